@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -30,6 +32,7 @@ func init() {
 	rootCmd.AddCommand(simulateCmd)
 	rootCmd.AddCommand(decisionsCmd)
 	rootCmd.AddCommand(actionsCmd)
+	rootCmd.AddCommand(webhooksCmd)
 }
 
 var healthCmd = &cobra.Command{
@@ -158,6 +161,85 @@ var actionsCmd = &cobra.Command{
 	},
 }
 
+var approveCmd = &cobra.Command{
+	Use:   "approve <action-id>",
+	Short: "Approve an action awaiting approval",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return postReview(cmd, args[0], "approve")
+	},
+}
+
+var rejectCmd = &cobra.Command{
+	Use:   "reject <action-id>",
+	Short: "Reject an action awaiting approval",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return postReview(cmd, args[0], "reject")
+	},
+}
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Inspect webhook deliveries and the dead-letter queue",
+}
+
+var deliveriesCmd = &cobra.Command{
+	Use:   "deliveries <webhook-id>",
+	Short: "List the delivery attempts of a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return getJSON("/webhooks/deliveries?webhook_id=" + url.QueryEscape(args[0]))
+	},
+}
+
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Manage webhooks that failed every retry",
+}
+
+var dlqListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dead-lettered webhooks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pending, _ := cmd.Flags().GetBool("pending")
+		limit, _ := cmd.Flags().GetInt("limit")
+		webhookID, _ := cmd.Flags().GetString("webhook")
+
+		q := url.Values{}
+		q.Set("limit", strconv.Itoa(limit))
+		if pending {
+			q.Set("pending", "true")
+		}
+		if webhookID != "" {
+			q.Set("webhook_id", webhookID)
+		}
+		return getJSON("/webhooks/dlq?" + q.Encode())
+	},
+}
+
+var dlqRedeliverCmd = &cobra.Command{
+	Use:   "redeliver <dead-letter-id>",
+	Short: "Send a dead-lettered webhook again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return postJSON("/webhooks/dlq/"+args[0]+"/redeliver", map[string]interface{}{})
+	},
+}
+
+func postReview(cmd *cobra.Command, actionID, verb string) error {
+	reviewer, _ := cmd.Flags().GetString("reviewer")
+	comment, _ := cmd.Flags().GetString("comment")
+	if reviewer == "" {
+		return fmt.Errorf("--reviewer is required")
+	}
+
+	return postJSON("/actions/"+actionID+"/"+verb, map[string]interface{}{
+		"reviewer": reviewer,
+		"comment":  comment,
+	})
+}
+
 func postJSON(endpoint string, data interface{}) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -222,6 +304,18 @@ func main() {
 	actionsCmd.Flags().StringP("type", "t", "scale_up", "Action type")
 	actionsCmd.Flags().BoolP("dry-run", "d", true, "Dry run mode")
 
+	for _, cmd := range []*cobra.Command{approveCmd, rejectCmd} {
+		cmd.Flags().StringP("reviewer", "u", os.Getenv("USER"), "Who is reviewing the action")
+		cmd.Flags().StringP("comment", "m", "", "Reason for the review")
+		actionsCmd.AddCommand(cmd)
+	}
+
+	dlqListCmd.Flags().BoolP("pending", "p", false, "Only dead letters not yet redelivered")
+	dlqListCmd.Flags().IntP("limit", "n", 100, "Maximum number of dead letters")
+	dlqListCmd.Flags().StringP("webhook", "w", "", "Only dead letters of this webhook ID")
+	dlqCmd.AddCommand(dlqListCmd, dlqRedeliverCmd)
+	webhooksCmd.AddCommand(deliveriesCmd, dlqCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/cache"
+	"github.com/aegis-decision-engine/ade/internal/circuitbreaker"
+	"github.com/aegis-decision-engine/ade/internal/config"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/storage/kafka"
+	"github.com/aegis-decision-engine/ade/internal/webhook"
+)
+
+// webhookConfig builds the webhook client configuration
+func webhookConfig(cfg *config.Config) webhook.Config {
+	return webhook.Config{
+		Timeout:       cfg.Action.DefaultWebhookTimeout,
+		MaxRetries:    cfg.Action.MaxRetries,
+		BaseBackoff:   time.Second,
+		MaxBackoff:    30 * time.Second,
+		MaxRetryAfter: cfg.Action.Retry.MaxRetryAfter,
+		Retry: webhook.RetryPolicy{
+			StatusCodes:   cfg.Action.Retry.StatusCodes,
+			NetworkErrors: cfg.Action.Retry.NetworkErrors,
+			Methods:       cfg.Action.Retry.Methods,
+		},
+		EnableCircuitBreaker: cfg.Action.EnableCircuitBreaker,
+		CircuitBreaker: circuitbreaker.Config{
+			MaxFailures:      cfg.Action.CircuitBreaker.MaxFailures,
+			ResetTimeout:     cfg.Action.CircuitBreaker.ResetTimeout,
+			HalfOpenMaxCalls: circuitbreaker.DefaultConfig().HalfOpenMaxCalls,
+		},
+		BreakerScope: webhook.BreakerScope(cfg.Action.CircuitBreaker.Scope),
+	}
+}
+
+// configureWebhookSigning installs the webhook signing secrets
+func configureWebhookSigning(cfg *config.Config, svc *action.Service) error {
+	if err := svc.SetWebhookSecrets("", cfg.Action.WebhookSecrets...); err != nil {
+		return err
+	}
+	for host, secrets := range cfg.Action.EndpointSecrets {
+		if err := svc.SetWebhookSecrets(host, strings.Split(secrets, "|")...); err != nil {
+			return fmt.Errorf("webhook host %s: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// configureGuards sets up target locks, batch concurrency limits and conflict
+// detection. With distributed locks on it connects to Redis and returns the
+// client and a func closing the connection.
+func configureGuards(cfg *config.Config, svc *action.Service, logger *slog.Logger) (*cache.Client, func(), error) {
+	guards := cfg.Action.Guards
+
+	perType := make(map[models.ActionType]int, len(guards.TypeConcurrency))
+	for actionType, limit := range guards.TypeConcurrency {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return nil, nil, fmt.Errorf("invalid concurrency limit %q for %s", limit, actionType)
+		}
+		perType[models.ActionType(actionType)] = n
+	}
+	svc.SetConcurrencyLimits(guards.BatchConcurrency, perType)
+	svc.SetConflictWindow(guards.ConflictWindow)
+
+	if !guards.DistributedLocks {
+		svc.SetTargetLocker(action.NewLocalLocker(), guards.LockWait)
+		return nil, func() {}, nil
+	}
+
+	redisClient, err := cache.NewClient(cfg.Redis.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("distributed locks: %w", err)
+	}
+	svc.SetTargetLocker(action.NewRedisLocker(redisClient, guards.LockTTL, logger), guards.LockWait)
+	return redisClient, func() { redisClient.Close() }, nil
+}
+
+// registerExecutors installs the executors configured in ACTION_EXECUTORS.
+// Action types without one keep the webhook executor.
+func registerExecutors(cfg *config.Config, svc *action.Service, kafkaClient *kafka.Client) (func(), error) {
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	built := make(map[string]action.Executor)
+	build := func(name string) (action.Executor, error) {
+		if executor, ok := built[name]; ok {
+			return executor, nil
+		}
+
+		var executor action.Executor
+		switch name {
+		case "kubernetes":
+			token := ""
+			if cfg.Action.Kubernetes.TokenFile != "" {
+				data, err := os.ReadFile(cfg.Action.Kubernetes.TokenFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read kubernetes token: %w", err)
+				}
+				token = strings.TrimSpace(string(data))
+			}
+			executor = action.NewScaleExecutor(cfg.Action.Kubernetes.APIURL, cfg.Action.Kubernetes.Namespace, token, nil)
+		case "exec":
+			commands := make(map[string]action.ExecCommand, len(cfg.Action.Exec.Commands))
+			for cmdName, line := range cfg.Action.Exec.Commands {
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					return nil, fmt.Errorf("exec command %s has no command line", cmdName)
+				}
+				commands[cmdName] = action.ExecCommand{
+					Path:    fields[0],
+					Args:    fields[1:],
+					Inverse: cfg.Action.Exec.Inverses[cmdName],
+				}
+			}
+			executor = action.NewExecExecutor(commands, cfg.Action.Exec.Timeout)
+		case "kafka":
+			// Synchronous, so a failed publish fails the action, and hashed by
+			// key, so a service's actions stay on one partition
+			writer := kafkaClient.NewSyncWriter(cfg.Kafka.ActionsTopic)
+			closers = append(closers, func() { writer.Close() })
+			executor = action.NewKafkaExecutor(writer, cfg.Kafka.ActionsTopic)
+		case "webhook":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unknown executor: %s", name)
+		}
+
+		built[name] = executor
+		return executor, nil
+	}
+
+	for actionType, name := range cfg.Action.Executors {
+		executor, err := build(name)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("action type %s: %w", actionType, err)
+		}
+		if executor != nil {
+			svc.RegisterExecutor(models.ActionType(actionType), executor)
+		}
+	}
+
+	return closeAll, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/cache"
+	"github.com/aegis-decision-engine/ade/internal/config"
+	"github.com/aegis-decision-engine/ade/internal/cron"
+	"github.com/aegis-decision-engine/ade/internal/decision"
+	"github.com/aegis-decision-engine/ade/internal/feedback"
+	"github.com/aegis-decision-engine/ade/internal/notification"
+	"github.com/aegis-decision-engine/ade/internal/scheduler"
+	"github.com/aegis-decision-engine/ade/internal/state"
+)
+
+// jobClaimTTL is how long a claimed run of a recurring job is remembered
+const jobClaimTTL = 24 * time.Hour
+
+// claimOnce wraps the handler of a recurring job so that, with Redis shared
+// by the replicas, only the replica that claims a run first carries it out.
+// Runs are keyed by the time they were scheduled for, which every replica
+// agrees on. Without Redis every replica runs every job.
+func claimOnce(redisClient *cache.Client, jobID string, logger *slog.Logger, handler func(context.Context, interface{}) error) func(context.Context, interface{}) error {
+	if redisClient == nil {
+		return handler
+	}
+	return func(ctx context.Context, payload interface{}) error {
+		scheduledFor := scheduler.ScheduledFor(ctx)
+		key := fmt.Sprintf("ade:job-run:%s:%d", jobID, scheduledFor.Unix())
+		token, err := redisClient.TryLock(ctx, key, jobClaimTTL)
+		if err != nil {
+			return fmt.Errorf("failed to claim job run: %w", err)
+		}
+		if token == "" {
+			logger.Debug("job run claimed by another replica", "id", jobID, "scheduled_for", scheduledFor)
+			return nil
+		}
+		return handler(ctx, payload)
+	}
+}
+
+// scheduleRecurringJobs registers the configured recurring jobs
+func scheduleRecurringJobs(
+	cfg *config.Config,
+	sched *scheduler.Scheduler,
+	stateService *state.Service,
+	feedbackService *feedback.Service,
+	decisionService *decision.Service,
+	slack *notification.SlackNotifier,
+	redisClient *cache.Client,
+	logger *slog.Logger,
+) {
+	// Keep feature snapshots fresh for services that see few decisions
+	if cfg.Scheduler.FeatureRefreshInterval > 0 {
+		for _, serviceID := range cfg.Scheduler.FeatureRefreshServices {
+			serviceID := serviceID
+			id := "features-" + serviceID
+			sched.Schedule(&scheduler.Job{
+				ID:         id,
+				Recurrence: scheduler.Every(cfg.Scheduler.FeatureRefreshInterval),
+				Jitter:     cfg.Scheduler.FeatureRefreshJitter,
+				Misfire:    scheduler.MisfireSkip,
+				Handler: claimOnce(redisClient, id, logger, func(ctx context.Context, _ interface{}) error {
+					_, err := stateService.CalculateFeatures(ctx, &state.CalculateFeaturesRequest{
+						ServiceID: serviceID,
+						Window:    cfg.Features.WindowSize,
+					})
+					return err
+				}),
+			})
+		}
+	}
+
+	if cfg.Scheduler.PolicyReportCron != "" {
+		schedule, err := cron.Parse(cfg.Scheduler.PolicyReportCron)
+		if err != nil {
+			logger.Warn("invalid policy report schedule", "error", err)
+		} else {
+			sched.Schedule(&scheduler.Job{
+				ID:         "policy-impact-report",
+				Recurrence: schedule,
+				Misfire:    scheduler.MisfireRunOnce,
+				Handler: claimOnce(redisClient, "policy-impact-report", logger, func(ctx context.Context, _ interface{}) error {
+					return reportPolicyImpact(ctx, feedbackService, slack, logger)
+				}),
+			})
+		}
+	}
+
+	if cfg.Scheduler.WindowsPath != "" {
+		windows, err := decision.LoadScheduledActions(cfg.Scheduler.WindowsPath)
+		if err != nil {
+			logger.Warn("failed to load scheduled actions", "error", err)
+			return
+		}
+		if len(windows) > 0 && !cfg.Action.AutoDispatch {
+			logger.Warn("scheduled actions need ACTION_AUTO_DISPATCH, not scheduling them", "count", len(windows))
+			return
+		}
+		for _, w := range windows {
+			w := w
+			misfire := scheduler.MisfireSkip // a missed window should not fire hours late
+			if w.Misfire != "" {
+				if misfire, err = scheduler.ParseMisfirePolicy(w.Misfire); err != nil {
+					logger.Warn("invalid misfire policy", "window", w.Name, "error", err)
+					continue
+				}
+			}
+			sched.Schedule(&scheduler.Job{
+				ID:         "window-" + w.Name,
+				Recurrence: cron.MustParse(w.Cron),
+				Misfire:    misfire,
+				Handler: func(ctx context.Context, _ interface{}) error {
+					_, err := decisionService.DecideScheduled(ctx, w, scheduler.ScheduledFor(ctx))
+					return err
+				},
+			})
+		}
+	}
+}
+
+// reportPolicyImpact logs the last day's policy impact and posts it to Slack
+func reportPolicyImpact(ctx context.Context, feedbackService *feedback.Service, slack *notification.SlackNotifier, logger *slog.Logger) error {
+	report, err := feedbackService.GetPolicyImpactReport(ctx, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("policy impact report", "from", report.From, "to", report.To, "policies", report.Policies)
+	if slack == nil {
+		return nil
+	}
+	return slack.NotifyAlert(ctx, notification.AlertNotification{
+		Title:     "Daily policy impact report",
+		Message:   report.Summary(),
+		ServiceID: "all",
+		Severity:  "info",
+	})
+}
@@ -12,15 +12,22 @@ import (
 	"github.com/aegis-decision-engine/ade/internal/action"
 	"github.com/aegis-decision-engine/ade/internal/config"
 	"github.com/aegis-decision-engine/ade/internal/decision"
+	"github.com/aegis-decision-engine/ade/internal/experiment"
 	"github.com/aegis-decision-engine/ade/internal/feedback"
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
 	"github.com/aegis-decision-engine/ade/internal/ingest"
 	"github.com/aegis-decision-engine/ade/internal/middleware"
+	"github.com/aegis-decision-engine/ade/internal/notification"
+	"github.com/aegis-decision-engine/ade/internal/plugin"
 	"github.com/aegis-decision-engine/ade/internal/policy"
 	"github.com/aegis-decision-engine/ade/internal/ratelimit"
+	"github.com/aegis-decision-engine/ade/internal/scheduler"
 	"github.com/aegis-decision-engine/ade/internal/simulation"
 	"github.com/aegis-decision-engine/ade/internal/state"
 	"github.com/aegis-decision-engine/ade/internal/storage/kafka"
 	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
+	"github.com/aegis-decision-engine/ade/internal/webhook"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -56,10 +63,6 @@ func main() {
 		slog.Warn("kafka not available", "error", err)
 	}
 
-	// Create Kafka writer for events topic
-	eventsWriter := kafkaClient.NewWriter("ade.events")
-	defer eventsWriter.Close()
-
 	// Initialize stores
 	var eventStore *postgres.EventStore
 	var featureStore *postgres.FeatureStore
@@ -69,8 +72,41 @@ func main() {
 	}
 
 	// Initialize services
-	ingestService := ingest.NewService(eventStore, eventsWriter, logger)
+	// In sync mode /ingest writes events to the database; in log-first mode
+	// it only publishes them and the consumer group writes them, committing
+	// offsets after each batch is stored
+	var ingestService *ingest.Service
+	var ingestConsumer *ingest.Consumer
+	if cfg.Ingest.Mode == config.IngestModeLogFirst {
+		eventsWriter := kafkaClient.NewSyncWriter(cfg.Kafka.EventsTopic)
+		defer eventsWriter.Close()
+		ingestService = ingest.NewService(nil, eventsWriter, logger)
+		ingestService.SetMode(ingest.ModeLogFirst)
+
+		if eventStore != nil {
+			ingestConsumer = ingest.NewConsumer(
+				kafkaClient.NewReader(cfg.Kafka.EventsTopic, cfg.Kafka.ConsumerGroup),
+				eventStore,
+				ingest.ConsumerConfig{
+					BatchSize:    cfg.Ingest.BatchSize,
+					BatchTimeout: cfg.Ingest.BatchTimeout,
+				},
+				logger,
+			)
+			prometheus.MustRegister(ingestConsumer.MetricsCollector())
+			go func() {
+				if err := ingestConsumer.Start(context.Background()); err != nil {
+					slog.Error("ingest consumer failed", "error", err)
+				}
+			}()
+		} else {
+			slog.Warn("log-first ingest without a database, published events are not consumed")
+		}
+	} else {
+		ingestService = ingest.NewService(eventStore, nil, logger)
+	}
 	ingestHandler := ingest.NewHandler(ingestService)
+	slog.Info("ingest mode", "mode", cfg.Ingest.Mode, "events_topic", cfg.Kafka.EventsTopic)
 
 	stateService := state.NewService(eventStore, featureStore, logger)
 	stateHandler := state.NewHandler(stateService)
@@ -78,29 +114,204 @@ func main() {
 	// Initialize decision service
 	policyEngine := policy.NewEngine(logger)
 	var decisionStore *postgres.DecisionStore
+	var feedbackStore *postgres.FeedbackStore
 	if pgClient != nil {
 		decisionStore = postgres.NewDecisionStore(pgClient)
+		feedbackStore = postgres.NewFeedbackStore(pgClient)
 	}
-	decisionService := decision.NewService(policyEngine, decisionStore, logger)
+	decisionService := decision.NewService(policyEngine, decisionStore, feedbackStore, logger)
 	decisionHandler := decision.NewHandler(decisionService)
 	
-	// Load default policy
-	if err := decisionHandler.LoadDefaultPolicy(); err != nil {
-		slog.Warn("failed to load default policy", "error", err)
+	// Load the policies and the bindings that select them for each service
+	if err := decisionHandler.LoadPolicies(cfg.Policy.Dir); err != nil {
+		slog.Warn("failed to load policies", "error", err)
+	}
+	if cfg.Policy.BindingsPath != "" {
+		if err := decisionHandler.LoadBindings(cfg.Policy.BindingsPath); err != nil {
+			slog.Error("invalid policy bindings", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Evaluate candidate policy versions in shadow next to the live ones
+	if cfg.Policy.CandidatesDir != "" {
+		if err := decisionHandler.LoadCandidates(cfg.Policy.CandidatesDir); err != nil {
+			slog.Error("invalid candidate policies", "error", err)
+			os.Exit(1)
+		}
+	}
+	prometheus.MustRegister(decisionService.MetricsCollector())
+
+	// Split the decisions of policies under experiment between their arms
+	var experimentStore *postgres.ExperimentStore
+	if pgClient != nil {
+		experimentStore = postgres.NewExperimentStore(pgClient)
 	}
+	experimentService := experiment.NewService(experimentStore, logger)
+	experimentService.SetPolicies(decisionHandler.Policies())
+	if err := experimentService.Load(context.Background()); err != nil {
+		slog.Warn("failed to load experiments", "error", err)
+	}
+	decisionHandler.SetExperiments(experimentService)
 
 	// Initialize simulation service
-	simulationService := simulation.NewService(logger)
+	dependencyGraph, _ := simulation.NewDependencyGraph(nil)
+	if cfg.Simulation.DependencyGraphPath != "" {
+		graph, err := simulation.LoadDependencyGraph(cfg.Simulation.DependencyGraphPath)
+		if err != nil {
+			slog.Warn("failed to load dependency graph", "error", err)
+		} else {
+			dependencyGraph = graph
+		}
+	}
+	simulationService := simulation.NewService(eventStore, dependencyGraph, logger)
 	simulationHandler := simulation.NewHandler(simulationService)
 
 	// Initialize action service
-	actionService := action.NewService("", false, logger)
+	var actionStore *postgres.ActionStore
+	if pgClient != nil {
+		actionStore = postgres.NewActionStore(pgClient)
+	}
+	actionService := action.NewService(actionStore, "", false, logger)
+	actionService.SetWebhookConfig(webhookConfig(cfg))
+	prometheus.MustRegister(actionService.WebhookClient().MetricsCollector())
 	actionHandler := action.NewHandler(actionService)
 
+	// Log webhook deliveries and dead-letter the ones that fail every retry
+	var webhookStore *postgres.WebhookStore
+	if pgClient != nil {
+		webhookStore = postgres.NewWebhookStore(pgClient)
+		actionService.WebhookClient().SetDeliveryLog(webhookStore)
+	}
+	webhookHandler := webhook.NewHandler(actionService.WebhookClient(), webhookStore)
+
+	closeExecutors, err := registerExecutors(cfg, actionService, kafkaClient)
+	if err != nil {
+		slog.Error("invalid action executors", "error", err)
+		os.Exit(1)
+	}
+	defer closeExecutors()
+	if err := configureWebhookSigning(cfg, actionService); err != nil {
+		slog.Error("invalid webhook secrets", "error", err)
+		os.Exit(1)
+	}
+	redisClient, closeGuards, err := configureGuards(cfg, actionService, logger)
+	if err != nil {
+		slog.Error("invalid action guards", "error", err)
+		os.Exit(1)
+	}
+	defer closeGuards()
+	if err := actionService.SetApprovalDefaults(cfg.Action.Approval.Timeout,
+		action.ApprovalOutcome(cfg.Action.Approval.DefaultOutcome)); err != nil {
+		slog.Warn("invalid approval settings, using defaults", "error", err)
+	}
+
+	// Ask for approvals in Slack when configured
+	var slack *notification.SlackNotifier
+	if cfg.Notification.SlackWebhookURL != "" {
+		slack = notification.NewSlackNotifier(cfg.Notification.SlackWebhookURL)
+		actionService.SetApprovalNotifier(slack)
+		if cfg.Notification.SlackSigningSecret != "" {
+			actionHandler.EnableSlackApprovals(cfg.Notification.SlackSigningSecret, slack)
+		}
+	}
+
+	actionService.SetRolloutWindow(cfg.Action.Rollout.Window)
+
+	// Bound what actions may do; without a guardrails file only the kill switch applies
+	guardrailConfig := &guardrail.Config{}
+	if cfg.Action.GuardrailsPath != "" {
+		loaded, err := guardrail.LoadConfig(cfg.Action.GuardrailsPath)
+		if err != nil {
+			slog.Error("invalid guardrails", "error", err)
+			os.Exit(1)
+		}
+		guardrailConfig = loaded
+	}
+	guardrails := guardrail.New(*guardrailConfig)
+	guardrails.SetKillSwitch(cfg.Action.KillSwitch)
+	// Reserve usage in the database, so the limits hold across replicas and
+	// restarts
+	if pgClient != nil {
+		guardrails.SetUsageStore(postgres.NewGuardrailStore(pgClient))
+	}
+
+	// Named calendars for time conditions; freeze calendars block every action
+	if cfg.Policy.CalendarsPath != "" {
+		calendars, err := policy.LoadCalendars(cfg.Policy.CalendarsPath)
+		if err != nil {
+			slog.Error("invalid calendars", "error", err)
+			os.Exit(1)
+		}
+		policyEngine.SetCalendars(calendars)
+		guardrails.SetFreezeCalendar(calendars)
+	}
+	actionService.SetGuardrails(guardrails)
+	decisionService.SetGuardrails(guardrails)
+
+	// Load plugins and call them around every decision and action
+	plugins := plugin.NewManager(logger)
+	plugins.SetProcessOptions(plugin.ProcessOptions{
+		CallTimeout:    cfg.Plugins.CallTimeout,
+		HealthInterval: cfg.Plugins.HealthInterval,
+		MaxBackoff:     cfg.Plugins.MaxBackoff,
+	})
+	if err := loadPlugins(cfg, plugins); err != nil {
+		slog.Error("invalid plugins", "error", err)
+		os.Exit(1)
+	}
+	defer plugins.Shutdown()
+	plugins.RegisterInverters(actionService)
+	actionService.SetHooks(plugins)
+	decisionService.SetHooks(plugins)
+
+	// Provide the built-in and plugin facts to policies
+	if err := registerFactProviders(cfg, policyEngine, plugins); err != nil {
+		slog.Error("invalid fact providers", "error", err)
+		os.Exit(1)
+	}
+
+	// Hand decision actions to the action service when enabled
+	if cfg.Action.AutoDispatch {
+		decisionService.SetActionDispatcher(actionService)
+	}
+
 	// Initialize feedback service
-	feedbackService := feedback.NewService(logger)
+	feedbackService := feedback.NewService(feedbackStore, actionStore, actionService, logger)
 	feedbackHandler := feedback.NewHandler(feedbackService)
 
+	// Initialize scheduler
+	jobScheduler := scheduler.NewScheduler(logger)
+	jobScheduler.Start(context.Background())
+	defer jobScheduler.Stop()
+
+	// Collect feedback automatically once actions complete
+	if cfg.Feedback.AutoCollect && eventStore != nil {
+		collector := feedback.NewCollector(stateService, jobScheduler, feedbackService,
+			cfg.Feedback.ObservationWindow, cfg.Features.WindowSize, logger)
+		actionService.SetFeedbackCollector(collector)
+	}
+
+	// Judge rollout stages by their measured impact
+	if eventStore != nil {
+		actionService.SetRolloutObserver(feedback.NewRolloutObserver(stateService, feedbackService, cfg.Features.WindowSize))
+	}
+
+	// Recurring jobs: feature refresh, policy impact report, scheduled actions
+	scheduleRecurringJobs(cfg, jobScheduler, stateService, feedbackService, decisionService, slack, redisClient, logger)
+
+	// Run scheduled actions, resolve approvals nobody reviewed in time and
+	// advance staged rollouts. The workers start once the action service is
+	// fully wired, so due actions claimed at boot see the kill switch,
+	// guardrails and hooks.
+	actionCtx, stopActions := context.WithCancel(context.Background())
+	defer stopActions()
+	if actionStore != nil {
+		go actionService.RunScheduled(actionCtx, cfg.Action.SchedulerInterval, cfg.Action.SchedulerWorkers)
+		go actionService.RunApprovalExpiry(actionCtx, cfg.Action.Approval.CheckInterval)
+		go actionService.RunRollouts(actionCtx, cfg.Action.Rollout.CheckInterval)
+	}
+
 	// Setup routes
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", healthHandler)
@@ -115,6 +326,10 @@ func main() {
 	simulationHandler.RegisterRoutes(mux)
 	actionHandler.RegisterRoutes(mux)
 	feedbackHandler.RegisterRoutes(mux)
+	scheduler.NewHandler(jobScheduler).RegisterRoutes(mux)
+	webhookHandler.RegisterRoutes(mux)
+	guardrail.NewHandler(guardrails).RegisterRoutes(mux)
+	experiment.NewHandler(experimentService).RegisterRoutes(mux)
 
 	// Setup middleware chain
 	// Order: Recovery -> Rate Limit -> Logging -> Handler
@@ -158,6 +373,14 @@ func main() {
 		slog.Error("server forced to shutdown", "error", err)
 	}
 
+	// Stop the consumer after the server, so the last published events are
+	// stored and committed
+	if ingestConsumer != nil {
+		if err := ingestConsumer.Stop(ctx); err != nil {
+			slog.Error("failed to stop ingest consumer", "error", err)
+		}
+	}
+
 	slog.Info("server stopped")
 }
 
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/aegis-decision-engine/ade/internal/config"
+	"github.com/aegis-decision-engine/ade/internal/plugin"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+)
+
+// loadPlugins loads the plugins configured in PLUGINS_FILE and the process
+// plugins in PLUGINS_DIR
+func loadPlugins(cfg *config.Config, plugins *plugin.Manager) error {
+	var configs []plugin.Config
+	if cfg.Plugins.Path != "" {
+		loaded, err := plugin.LoadConfig(cfg.Plugins.Path)
+		if err != nil {
+			return err
+		}
+		configs = loaded
+	}
+	if cfg.Plugins.Dir != "" {
+		discovered, err := plugin.Discover(cfg.Plugins.Dir, configs)
+		if err != nil {
+			return err
+		}
+		configs = discovered
+	}
+	return plugins.Load(configs)
+}
+
+// registerFactProviders registers the time and calendar facts and the facts
+// of the loaded plugins with the policy engine
+func registerFactProviders(cfg *config.Config, engine *policy.Engine, plugins *plugin.Manager) error {
+	options := policy.FactOptions{Timeout: cfg.Policy.FactTimeout, TTL: cfg.Policy.FactCacheTTL}
+
+	calendar, err := policy.NewCalendarProvider(cfg.Policy.BusinessTimezone, cfg.Policy.BusinessHoursStart, cfg.Policy.BusinessHoursEnd)
+	if err != nil {
+		return err
+	}
+	// Time facts change every minute, so they are never cached
+	if err := engine.RegisterFactProvider(policy.NewTimeProvider(), policy.FactOptions{Timeout: options.Timeout}); err != nil {
+		return err
+	}
+	if err := engine.RegisterFactProvider(calendar, policy.FactOptions{Timeout: options.Timeout}); err != nil {
+		return err
+	}
+	return plugins.RegisterFactProviders(engine, options)
+}
@@ -0,0 +1,225 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// ApprovalOutcome is what happens to an action once its approval is decided
+type ApprovalOutcome string
+
+const (
+	ApprovalOutcomeApprove ApprovalOutcome = "approve"
+	ApprovalOutcomeReject  ApprovalOutcome = "reject"
+)
+
+// ReviewerTimeout is recorded as the reviewer of actions whose approval expired
+const ReviewerTimeout = "system:timeout"
+
+// expiryBatchSize caps how many expired approvals are resolved per pass
+const expiryBatchSize = 100
+
+// ApprovalNotifier is told when an action starts waiting for approval
+type ApprovalNotifier interface {
+	ApprovalRequested(ctx context.Context, record *models.ActionRecord) error
+}
+
+// SetApprovalNotifier sets the notifier told about actions awaiting approval
+func (s *Service) SetApprovalNotifier(notifier ApprovalNotifier) {
+	s.approvalNotifier = notifier
+}
+
+// SetApprovalDefaults sets how long approvals wait when the policy does not say,
+// and what happens to an action when nobody reviews it in time
+func (s *Service) SetApprovalDefaults(timeout time.Duration, outcome ApprovalOutcome) error {
+	if timeout <= 0 {
+		return fmt.Errorf("approval timeout must be positive")
+	}
+	switch outcome {
+	case ApprovalOutcomeApprove, ApprovalOutcomeReject:
+	default:
+		return fmt.Errorf("unknown approval outcome: %s", outcome)
+	}
+	s.approvalTimeout = timeout
+	s.approvalDefault = outcome
+	return nil
+}
+
+// Submit records an action as awaiting approval without executing it. A zero
+// timeout uses the service default.
+func (s *Service) Submit(ctx context.Context, req *ActionRequest, timeout time.Duration) (*ActionResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if s.actionStore == nil {
+		return nil, fmt.Errorf("action store not available")
+	}
+	if timeout <= 0 {
+		timeout = s.approvalTimeout
+	}
+
+	expiresAt := time.Now().Add(timeout)
+	record := &models.ActionRecord{
+		ActionID:          req.ActionID,
+		DecisionID:        req.DecisionID,
+		ActionType:        req.ActionType,
+		ActionPayload:     mustMarshal(req.Payload),
+		TargetService:     req.TargetService,
+		Status:            models.ActionStatusAwaitingApproval,
+		DryRun:            req.DryRun || s.dryRun,
+		WebhookURL:        s.resolveWebhookURL(req),
+		RollbackOf:        req.RollbackOf,
+		ApprovalExpiresAt: &expiresAt,
+		Cost:              req.Cost,
+		Rollout:           newRollout(req.Rollout),
+	}
+	err := s.admitAndStore(ctx, req, record.DryRun, func() error {
+		if err := s.actionStore.Store(ctx, record); err != nil {
+			return fmt.Errorf("failed to persist action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("action awaiting approval",
+		"action_id", req.ActionID,
+		"type", req.ActionType,
+		"target", req.TargetService,
+		"expires_at", expiresAt,
+	)
+
+	if s.approvalNotifier != nil {
+		if err := s.approvalNotifier.ApprovalRequested(ctx, record); err != nil {
+			s.logger.Warn("failed to send approval request", "action_id", req.ActionID, "error", err)
+		}
+	}
+
+	return &ActionResult{
+		ActionID:   req.ActionID,
+		Status:     string(models.ActionStatusAwaitingApproval),
+		DryRun:     record.DryRun,
+		ExecutedAt: time.Now(),
+		WebhookURL: req.WebhookURL,
+		Metadata: map[string]interface{}{
+			"approval_expires_at": expiresAt,
+		},
+	}, nil
+}
+
+// Approve approves an action awaiting approval and executes it
+func (s *Service) Approve(ctx context.Context, actionID, reviewer, comment string) (*ActionResult, error) {
+	record, err := s.reviewable(ctx, actionID, reviewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.approve(ctx, record, reviewer, comment)
+}
+
+// Reject rejects an action awaiting approval so it never executes
+func (s *Service) Reject(ctx context.Context, actionID, reviewer, comment string) error {
+	if _, err := s.reviewable(ctx, actionID, reviewer); err != nil {
+		return err
+	}
+	return s.reject(ctx, actionID, reviewer, comment)
+}
+
+// ExpireApprovals resolves actions whose approval window has passed with the
+// default outcome and returns how many were resolved
+func (s *Service) ExpireApprovals(ctx context.Context) (int, error) {
+	if s.actionStore == nil {
+		return 0, fmt.Errorf("action store not available")
+	}
+
+	records, err := s.actionStore.GetExpiredApprovals(ctx, expiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired approvals: %w", err)
+	}
+
+	resolved := 0
+	for _, record := range records {
+		comment := fmt.Sprintf("approval timed out, default outcome %s", s.approvalDefault)
+		if s.approvalDefault == ApprovalOutcomeApprove {
+			_, err = s.approve(ctx, record, ReviewerTimeout, comment)
+		} else {
+			err = s.reject(ctx, record.ActionID, ReviewerTimeout, comment)
+		}
+		if err != nil {
+			s.logger.Warn("failed to expire approval", "action_id", record.ActionID, "error", err)
+			continue
+		}
+		resolved++
+	}
+
+	return resolved, nil
+}
+
+// RunApprovalExpiry resolves expired approvals every interval until ctx is done
+func (s *Service) RunApprovalExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ExpireApprovals(ctx)
+			if err != nil {
+				s.logger.Warn("approval expiry failed", "error", err)
+				continue
+			}
+			if n > 0 {
+				s.logger.Info("expired approvals resolved", "count", n, "outcome", s.approvalDefault)
+			}
+		}
+	}
+}
+
+// reviewable loads an action and checks that it can still be reviewed
+func (s *Service) reviewable(ctx context.Context, actionID, reviewer string) (*models.ActionRecord, error) {
+	if reviewer == "" {
+		return nil, fmt.Errorf("reviewer is required")
+	}
+	if s.actionStore == nil {
+		return nil, fmt.Errorf("action store not available")
+	}
+
+	record, err := s.actionStore.GetByID(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != models.ActionStatusAwaitingApproval {
+		return nil, fmt.Errorf("%w: %s is %s", models.ErrInvalidTransition, actionID, record.Status)
+	}
+	if record.ApprovalExpiresAt != nil && time.Now().After(*record.ApprovalExpiresAt) {
+		return nil, fmt.Errorf("%w: %s expired at %s", models.ErrApprovalExpired, actionID, record.ApprovalExpiresAt.Format(time.RFC3339))
+	}
+	return record, nil
+}
+
+func (s *Service) approve(ctx context.Context, record *models.ActionRecord, reviewer, comment string) (*ActionResult, error) {
+	req, err := RequestFromRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.actionStore.Review(ctx, record.ActionID, models.ActionStatusPending, reviewer, comment); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("action approved", "action_id", record.ActionID, "reviewer", reviewer)
+	return s.run(ctx, req, record.WebhookURL, record.DryRun)
+}
+
+func (s *Service) reject(ctx context.Context, actionID, reviewer, comment string) error {
+	if err := s.actionStore.Review(ctx, actionID, models.ActionStatusRejected, reviewer, comment); err != nil {
+		return err
+	}
+
+	s.logger.Info("action rejected", "action_id", actionID, "reviewer", reviewer)
+	return nil
+}
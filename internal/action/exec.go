@@ -0,0 +1,112 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ErrCommandNotAllowed is returned when an action names a command that is not
+// on the exec executor's allow-list
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// maxCommandOutput bounds the command output kept on the action record
+const maxCommandOutput = 4096
+
+// ExecCommand is a command the exec executor may run
+type ExecCommand struct {
+	Path    string
+	Args    []string
+	Inverse string // name of the command that undoes this one, if any
+}
+
+// ExecExecutor runs allow-listed local commands. The payload's "command"
+// names the command; arguments come only from the allow-list. The action is
+// passed to the command in ADE_* environment variables.
+type ExecExecutor struct {
+	commands map[string]ExecCommand
+	timeout  time.Duration
+}
+
+// NewExecExecutor creates an exec executor for the given commands
+func NewExecExecutor(commands map[string]ExecCommand, timeout time.Duration) *ExecExecutor {
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	return &ExecExecutor{
+		commands: commands,
+		timeout:  timeout,
+	}
+}
+
+// Execute runs the command named by the action
+func (e *ExecExecutor) Execute(ctx context.Context, req *ActionRequest) (*Execution, error) {
+	name, _ := req.Payload["command"].(string)
+	command, ok := e.commands[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrCommandNotAllowed, name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command.Path, command.Args...)
+	cmd.Env = append(os.Environ(),
+		"ADE_ACTION_ID="+req.ActionID,
+		"ADE_DECISION_ID="+req.DecisionID,
+		"ADE_ACTION_TYPE="+string(req.ActionType),
+		"ADE_TARGET_SERVICE="+req.TargetService,
+		"ADE_PAYLOAD="+string(mustMarshal(req.Payload)),
+	)
+	if req.RollbackOf != "" {
+		cmd.Env = append(cmd.Env, "ADE_ROLLBACK_OF="+req.RollbackOf)
+	}
+
+	started := time.Now()
+	output, err := cmd.CombinedOutput()
+	if len(output) > maxCommandOutput {
+		output = output[:maxCommandOutput]
+	}
+
+	exitCode := cmd.ProcessState.ExitCode()
+	if err != nil {
+		return nil, fmt.Errorf("command %s failed: %w: %s", name, err, output)
+	}
+
+	return &Execution{
+		StatusCode: exitCode,
+		Body:       string(output),
+		Response: mustMarshal(map[string]interface{}{
+			"command":     name,
+			"exit_code":   exitCode,
+			"output":      string(output),
+			"duration_ms": time.Since(started).Milliseconds(),
+		}),
+	}, nil
+}
+
+// Invert runs the command declared as the inverse of the original command
+func (e *ExecExecutor) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	name, _ := original.Payload["command"].(string)
+	inverse := e.commands[name].Inverse
+	if inverse == "" {
+		return nil, fmt.Errorf("%w: command %q has no inverse", ErrNotInvertible, name)
+	}
+
+	payload := make(map[string]interface{}, len(original.Payload))
+	for k, v := range original.Payload {
+		payload[k] = v
+	}
+	payload["command"] = inverse
+
+	return &ActionRequest{
+		DecisionID:    original.DecisionID,
+		ActionType:    original.ActionType,
+		TargetService: original.TargetService,
+		Payload:       payload,
+		RollbackOf:    original.ActionID,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/webhook"
+)
+
+// Executor carries out actions of the types it is registered for and knows
+// how to undo them
+type Executor interface {
+	Inverter
+	Execute(ctx context.Context, req *ActionRequest) (*Execution, error)
+}
+
+// Execution is what an executor reports about a performed action
+type Execution struct {
+	StatusCode int
+	Body       string
+	Response   json.RawMessage // stored on the action record and handed back to Invert
+}
+
+// RegisterExecutor sets the executor for an action type. Types without an
+// executor are delivered by webhook.
+func (s *Service) RegisterExecutor(actionType models.ActionType, executor Executor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executors[actionType] = executor
+}
+
+func (s *Service) executorFor(actionType models.ActionType) Executor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if executor, ok := s.executors[actionType]; ok {
+		return executor
+	}
+	return s.defaultExecutor
+}
+
+// WebhookExecutor POSTs actions as JSON to the request's webhook URL
+type WebhookExecutor struct {
+	client  *webhook.Client
+	onRetry func(ctx context.Context, actionID string)
+}
+
+// NewWebhookExecutor creates a webhook executor. onRetry, if set, is called
+// before every retried delivery.
+func NewWebhookExecutor(client *webhook.Client, onRetry func(ctx context.Context, actionID string)) *WebhookExecutor {
+	return &WebhookExecutor{
+		client:  client,
+		onRetry: onRetry,
+	}
+}
+
+// Execute sends the action to req.WebhookURL. Actions without a webhook URL
+// complete without being sent anywhere.
+func (e *WebhookExecutor) Execute(ctx context.Context, req *ActionRequest) (*Execution, error) {
+	if req.WebhookURL == "" {
+		return &Execution{}, nil
+	}
+
+	webhookReq := &webhook.Request{
+		ID:      req.ActionID,
+		URL:     req.WebhookURL,
+		Method:  "POST",
+		Payload: actionMessage(req),
+		Headers: map[string]string{
+			"X-Action-Type": string(req.ActionType),
+			"X-Service-ID":  req.TargetService,
+		},
+		OnRetry: func(attempt int, err error) {
+			if e.onRetry != nil {
+				e.onRetry(ctx, req.ActionID)
+			}
+		},
+	}
+
+	resp, err := e.client.Send(ctx, webhookReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Execution{
+		StatusCode: resp.StatusCode,
+		Body:       string(resp.Body),
+		Response: mustMarshal(map[string]interface{}{
+			"status_code": resp.StatusCode,
+			"body":        string(resp.Body),
+			"attempts":    resp.Attempts,
+			"duration_ms": resp.Duration.Milliseconds(),
+		}),
+	}, nil
+}
+
+// Invert returns the built-in inverse of the action, leaving the receiver to
+// interpret it
+func (e *WebhookExecutor) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	return invertByType(original)
+}
+
+// actionMessage is the body sent to webhook and Kafka consumers
+func actionMessage(req *ActionRequest) map[string]interface{} {
+	msg := map[string]interface{}{
+		"action_id":      req.ActionID,
+		"decision_id":    req.DecisionID,
+		"action_type":    req.ActionType,
+		"target_service": req.TargetService,
+		"payload":        req.Payload,
+		"timestamp":      time.Now(),
+	}
+	if req.RollbackOf != "" {
+		msg["rollback_of"] = req.RollbackOf
+	}
+	return msg
+}
+
+// intParam reads an integer from an action payload, which holds float64
+// values once it has been through JSON
+func intParam(payload map[string]interface{}, key string) (int, bool, error) {
+	v, ok := payload[key]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return n, true, nil
+	case int64:
+		return int(n), true, nil
+	case float64:
+		if n != float64(int(n)) {
+			return 0, false, fmt.Errorf("%s must be a whole number", key)
+		}
+		return int(n), true, nil
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, false, fmt.Errorf("%s must be a whole number", key)
+		}
+		return int(i), true, nil
+	}
+	return 0, false, fmt.Errorf("%s must be a number", key)
+}
@@ -0,0 +1,186 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScaleAPI serves the scale subresource of one deployment
+func fakeScaleAPI(t *testing.T, replicas *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/apps/v1/namespaces/prod/deployments/checkout/scale", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPatch:
+			assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+			var patch scale
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &patch))
+			*replicas = patch.Spec.Replicas
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var s scale
+		s.Spec.Replicas = *replicas
+		json.NewEncoder(w).Encode(s)
+	}))
+}
+
+func TestScaleExecutor(t *testing.T) {
+	replicas := 3
+	server := fakeScaleAPI(t, &replicas)
+	defer server.Close()
+
+	executor := NewScaleExecutor(server.URL, "prod", "secret", server.Client())
+	svc := NewService(nil, "", false, nil)
+	svc.RegisterExecutor(models.ActionTypeScaleUp, executor)
+	svc.RegisterExecutor(models.ActionTypeScaleDown, executor)
+
+	req := &ActionRequest{
+		ActionID:      "act-1",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleUp,
+		TargetService: "checkout",
+		Payload:       map[string]interface{}{"instances": float64(4), "max_instances": float64(5)},
+	}
+	result, err := svc.Execute(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+	assert.Equal(t, 5, replicas, "scale up is capped at max_instances")
+
+	// The inverse restores the replica count from before the action
+	execution, err := executor.Execute(context.Background(), &ActionRequest{
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "checkout",
+		Payload:       map[string]interface{}{"instances": 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, replicas)
+
+	original := &ActionRequest{
+		ActionID:      "act-2",
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "checkout",
+		Response:      execution.Response,
+	}
+	inverse, err := svc.Inverse(context.Background(), original)
+	require.NoError(t, err)
+	assert.Equal(t, models.ActionTypeScaleUp, inverse.ActionType)
+	assert.Equal(t, 5, inverse.Payload["replicas"])
+	assert.Equal(t, "act-2", inverse.RollbackOf)
+
+	_, err = executor.Execute(context.Background(), inverse)
+	require.NoError(t, err)
+	assert.Equal(t, 5, replicas)
+}
+
+func TestScaleExecutorInvertWithoutResponse(t *testing.T) {
+	executor := NewScaleExecutor("http://unused", "", "", nil)
+	inverse, err := executor.Invert(context.Background(), &ActionRequest{
+		ActionID:      "act-1",
+		ActionType:    models.ActionTypeScaleUp,
+		TargetService: "checkout",
+		Payload:       map[string]interface{}{"instances": 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ActionTypeScaleDown, inverse.ActionType)
+	assert.Equal(t, 2, inverse.Payload["instances"])
+}
+
+func TestScaleExecutorAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "deployments.apps \"checkout\" not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	executor := NewScaleExecutor(server.URL, "prod", "", server.Client())
+	_, err := executor.Execute(context.Background(), &ActionRequest{
+		ActionType:    models.ActionTypeScaleUp,
+		TargetService: "checkout",
+	})
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestExecExecutor(t *testing.T) {
+	echo, err := exec.LookPath("echo")
+	if err != nil {
+		t.Skip("echo not available")
+	}
+
+	executor := NewExecExecutor(map[string]ExecCommand{
+		"drain":   {Path: echo, Args: []string{"draining"}, Inverse: "undrain"},
+		"undrain": {Path: echo, Args: []string{"undraining"}},
+	}, 0)
+
+	req := &ActionRequest{
+		ActionID:      "act-1",
+		ActionType:    models.ActionTypeWebhook,
+		TargetService: "checkout",
+		Payload:       map[string]interface{}{"command": "drain"},
+	}
+	execution, err := executor.Execute(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, execution.StatusCode)
+	assert.Equal(t, "draining\n", execution.Body)
+
+	inverse, err := executor.Invert(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "undrain", inverse.Payload["command"])
+
+	_, err = executor.Invert(context.Background(), inverse)
+	assert.ErrorIs(t, err, ErrNotInvertible)
+
+	req.Payload["command"] = "rm"
+	_, err = executor.Execute(context.Background(), req)
+	assert.ErrorIs(t, err, ErrCommandNotAllowed)
+}
+
+type fakeWriter struct {
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func TestKafkaExecutor(t *testing.T) {
+	writer := &fakeWriter{}
+	svc := NewService(nil, "", false, nil)
+	svc.RegisterExecutor(models.ActionTypeThrottle, NewKafkaExecutor(writer, "ade.actions"))
+
+	_, err := svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-1",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeThrottle,
+		TargetService: "checkout",
+		Payload:       map[string]interface{}{"rate": 100},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, writer.messages, 1)
+	assert.Equal(t, "checkout", string(writer.messages[0].Key))
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(writer.messages[0].Value, &msg))
+	assert.Equal(t, "act-1", msg["action_id"])
+	assert.Equal(t, "throttle", msg["action_type"])
+
+	inverse, err := svc.Inverse(context.Background(), &ActionRequest{ActionID: "act-1", ActionType: models.ActionTypeThrottle})
+	require.NoError(t, err)
+	assert.Equal(t, models.ActionTypeUnthrottle, inverse.ActionType)
+}
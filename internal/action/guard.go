@@ -0,0 +1,344 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/cache"
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+var (
+	// ErrTargetBusy is returned when another action on the same target stays
+	// in flight for longer than the lock wait
+	ErrTargetBusy = errors.New("another action on the target is in flight")
+	// ErrConflictingAction is returned when an action contradicts one queued
+	// for the same target within the conflict window
+	ErrConflictingAction = errors.New("conflicting action")
+)
+
+// lockPollInterval is how often a busy target is retried
+const lockPollInterval = 100 * time.Millisecond
+
+// TargetLocker grants exclusive leases on action targets
+type TargetLocker interface {
+	// TryAcquire takes the lease on target without waiting. It returns a nil
+	// release func when someone else holds the lease.
+	TryAcquire(ctx context.Context, target string) (release func(), err error)
+}
+
+// LocalLocker holds target leases in memory, for a single replica
+type LocalLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// NewLocalLocker creates an in-memory target locker
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{held: make(map[string]bool)}
+}
+
+// TryAcquire takes the lease on target if it is free
+func (l *LocalLocker) TryAcquire(ctx context.Context, target string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[target] {
+		return nil, nil
+	}
+	l.held[target] = true
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(l.held, target)
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// RedisLocker holds target leases in Redis so they are shared by every
+// replica. Leases expire after ttl unless renewed, which the holder does every
+// ttl/3, so a crashed replica does not keep a target locked.
+type RedisLocker struct {
+	client *cache.Client
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// NewRedisLocker creates a Redis-backed target locker
+func NewRedisLocker(client *cache.Client, ttl time.Duration, logger *slog.Logger) *RedisLocker {
+	if ttl <= 0 {
+		ttl = 2 * time.Minute
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &RedisLocker{client: client, ttl: ttl, logger: logger}
+}
+
+// TryAcquire takes the lease on target if no replica holds it
+func (l *RedisLocker) TryAcquire(ctx context.Context, target string) (func(), error) {
+	key := "ade:action-lock:" + target
+	token, err := l.client.TryLock(ctx, key, l.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", target, err)
+	}
+	if token == "" {
+		return nil, nil
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ok, err := l.client.ExtendLock(context.Background(), key, token, l.ttl)
+				if err != nil || !ok {
+					l.logger.Warn("lost action lock", "target", target, "error", err)
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			if err := l.client.Unlock(context.Background(), key, token); err != nil {
+				l.logger.Warn("failed to release action lock", "target", target, "error", err)
+			}
+		})
+	}, nil
+}
+
+// SetTargetLocker sets how targets are locked and how long an action waits
+// for a busy target before failing
+func (s *Service) SetTargetLocker(locker TargetLocker, wait time.Duration) {
+	s.locker = locker
+	s.lockWait = wait
+}
+
+// SetConflictWindow sets how far back contradictory actions are looked for.
+// Zero disables conflict detection.
+func (s *Service) SetConflictWindow(window time.Duration) {
+	s.conflictWindow = window
+}
+
+// SetConcurrencyLimits caps how many actions of a batch run at once, overall
+// and per action type. Zero means no limit.
+func (s *Service) SetConcurrencyLimits(global int, perType map[models.ActionType]int) {
+	s.batchSlots = nil
+	if global > 0 {
+		s.batchSlots = make(chan struct{}, global)
+	}
+	s.typeSlots = make(map[models.ActionType]chan struct{}, len(perType))
+	for actionType, limit := range perType {
+		if limit > 0 {
+			s.typeSlots[actionType] = make(chan struct{}, limit)
+		}
+	}
+}
+
+// lockTarget waits up to the lock wait for the target's lease
+func (s *Service) lockTarget(ctx context.Context, target string) (func(), error) {
+	deadline := time.Now().Add(s.lockWait)
+	for {
+		release, err := s.locker.TryAcquire(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		if release != nil {
+			return release, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrTargetBusy, target)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// acquireSlots waits for a batch slot for the action type and overall. The
+// type slot is taken first so that held global slots can always make progress.
+func (s *Service) acquireSlots(ctx context.Context, actionType models.ActionType) (func(), error) {
+	var held []chan struct{}
+	release := func() {
+		for _, slot := range held {
+			<-slot
+		}
+	}
+
+	for _, slot := range []chan struct{}{s.typeSlots[actionType], s.batchSlots} {
+		if slot == nil {
+			continue
+		}
+		select {
+		case slot <- struct{}{}:
+			held = append(held, slot)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// recentAction is an action accepted by this replica, for conflict detection
+// without an action store
+type recentAction struct {
+	actionID   string
+	actionType models.ActionType
+	target     string
+	at         time.Time
+}
+
+// contradicts reports whether two action types undo each other
+func contradicts(a, b models.ActionType) bool {
+	return defaultInverses[a] == b || defaultInverses[b] == a
+}
+
+// admitAndStore admits req and persists it with store while holding the
+// admission lease on its target, so that no replica admits a contradicting
+// action in between. The lease is apart from the one held while an action
+// runs, so admitting an action does not wait for one in flight.
+func (s *Service) admitAndStore(ctx context.Context, req *ActionRequest, dryRun bool, store func() error) error {
+	if s.conflictWindow <= 0 || dryRun || req.RollbackOf != "" {
+		return store()
+	}
+	release, err := s.lockTarget(ctx, "admit:"+req.TargetService)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := s.admit(ctx, req, dryRun); err != nil {
+		return err
+	}
+	return store()
+}
+
+// admit rejects req if it contradicts an action queued for the same target
+// within the conflict window, and otherwise records it. Rollbacks and dry runs
+// are always admitted. Callers hold the target's admission lease until req is
+// persisted.
+func (s *Service) admit(ctx context.Context, req *ActionRequest, dryRun bool) error {
+	if s.conflictWindow <= 0 || dryRun || req.RollbackOf != "" {
+		return nil
+	}
+	since := time.Now().Add(-s.conflictWindow)
+
+	if s.actionStore != nil {
+		records, err := s.actionStore.ListActions(ctx, models.ActionFilters{
+			ServiceID: req.TargetService,
+			Since:     since,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check for conflicting actions: %w", err)
+		}
+		for _, r := range records {
+			if r.DryRun || r.RollbackOf != "" || !contradicts(r.ActionType, req.ActionType) {
+				continue
+			}
+			switch r.Status {
+			case models.ActionStatusFailed, models.ActionStatusCancelled, models.ActionStatusRejected:
+				continue
+			}
+			return fmt.Errorf("%w: %s contradicts %s %s on %s", ErrConflictingAction,
+				req.ActionType, r.ActionType, r.ActionID, req.TargetService)
+		}
+		return nil
+	}
+
+	s.recentMu.Lock()
+	defer s.recentMu.Unlock()
+	kept := s.recent[:0]
+	for _, r := range s.recent {
+		if r.at.After(since) {
+			kept = append(kept, r)
+		}
+	}
+	s.recent = kept
+
+	for _, r := range s.recent {
+		if r.target == req.TargetService && contradicts(r.actionType, req.ActionType) {
+			return fmt.Errorf("%w: %s contradicts %s %s on %s", ErrConflictingAction,
+				req.ActionType, r.actionType, r.actionID, req.TargetService)
+		}
+	}
+	s.recent = append(s.recent, recentAction{
+		actionID:   req.ActionID,
+		actionType: req.ActionType,
+		target:     req.TargetService,
+		at:         time.Now(),
+	})
+	return nil
+}
+
+// SetGuardrails bounds what actions may do. Actions blocked by a guardrail fail
+// with a *guardrail.Violation naming it.
+func (s *Service) SetGuardrails(g *guardrail.Guardrails) {
+	s.guardrails = g
+}
+
+// killSwitch reports whether the guardrail kill switch forces dry runs
+func (s *Service) killSwitch() bool {
+	return s.guardrails != nil && s.guardrails.KillSwitch()
+}
+
+// reserveGuardrails counts req against the guardrails and returns a func that
+// gives the reservation back. Rollbacks restore an earlier state and are not
+// limited.
+func (s *Service) reserveGuardrails(req *ActionRequest) (func(), error) {
+	if s.guardrails == nil {
+		return func() {}, nil
+	}
+	r := guardrail.NewRequest(req.TargetService, req.ActionType, req.Payload, req.Cost)
+	r.ActionID = req.ActionID
+	r.Rollback = req.RollbackOf != ""
+	return s.guardrails.Reserve(r)
+}
+
+// boundPayload returns the payload of a scale action with its instance bounds
+// narrowed to the service's replica guardrails
+func (s *Service) boundPayload(req *ActionRequest) map[string]interface{} {
+	if s.guardrails == nil {
+		return req.Payload
+	}
+	switch req.ActionType {
+	case models.ActionTypeScaleUp, models.ActionTypeScaleDown:
+	default:
+		return req.Payload
+	}
+	min, max := s.guardrails.Bounds(req.TargetService)
+	if min == 0 && max == 0 {
+		return req.Payload
+	}
+
+	payload := make(map[string]interface{}, len(req.Payload)+2)
+	for k, v := range req.Payload {
+		payload[k] = v
+	}
+	if n, ok, _ := intParam(payload, "min_instances"); min > 0 && (!ok || n < min) {
+		payload["min_instances"] = min
+	}
+	if n, ok, _ := intParam(payload, "max_instances"); max > 0 && (!ok || n > max) {
+		payload["max_instances"] = max
+	}
+	return payload
+}
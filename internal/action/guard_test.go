@@ -0,0 +1,311 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingExecutor records how many actions run at once and holds each one
+// until release is closed
+type blockingExecutor struct {
+	release chan struct{}
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (e *blockingExecutor) Execute(ctx context.Context, req *ActionRequest) (*Execution, error) {
+	n := e.running.Add(1)
+	defer e.running.Add(-1)
+	for {
+		peak := e.peak.Load()
+		if n <= peak || e.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	<-e.release
+	return &Execution{}, nil
+}
+
+func (e *blockingExecutor) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	return invertByType(original)
+}
+
+// executorFunc adapts a function to an Executor
+type executorFunc func(ctx context.Context, req *ActionRequest) (*Execution, error)
+
+func (f executorFunc) Execute(ctx context.Context, req *ActionRequest) (*Execution, error) {
+	return f(ctx, req)
+}
+
+func (f executorFunc) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	return invertByType(original)
+}
+
+func TestExecuteRejectsConflictingAction(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+
+	_, err := svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-1",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleUp,
+		TargetService: "checkout",
+	})
+	require.NoError(t, err)
+
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-2",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "checkout",
+	})
+	assert.ErrorIs(t, err, ErrConflictingAction)
+
+	// Other targets, rollbacks and dry runs are not conflicts
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-3",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "payments",
+	})
+	assert.NoError(t, err)
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-4",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "checkout",
+		RollbackOf:    "act-1",
+	})
+	assert.NoError(t, err)
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-5",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "checkout",
+		DryRun:        true,
+	})
+	assert.NoError(t, err)
+
+	svc.SetConflictWindow(0)
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-6",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleDown,
+		TargetService: "checkout",
+	})
+	assert.NoError(t, err)
+}
+
+func TestAdmissionHoldsTargetLease(t *testing.T) {
+	locker := NewLocalLocker()
+	svc := NewService(nil, "", false, nil)
+	svc.SetTargetLocker(locker, 50*time.Millisecond)
+
+	// Another replica is admitting an action on the target
+	release, err := locker.TryAcquire(context.Background(), "admit:checkout")
+	require.NoError(t, err)
+	req := &ActionRequest{
+		ActionID:      "act-1",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleUp,
+		TargetService: "checkout",
+	}
+	_, err = svc.Execute(context.Background(), req)
+	assert.ErrorIs(t, err, ErrTargetBusy)
+
+	// Other targets are admitted meanwhile
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-2",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeScaleUp,
+		TargetService: "payments",
+	})
+	assert.NoError(t, err)
+
+	release()
+	_, err = svc.Execute(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+func TestExecuteFailsWhenTargetStaysBusy(t *testing.T) {
+	executor := &blockingExecutor{release: make(chan struct{})}
+	svc := NewService(nil, "", false, nil)
+	svc.RegisterExecutor(models.ActionTypeThrottle, executor)
+	svc.SetTargetLocker(NewLocalLocker(), 50*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.Execute(context.Background(), &ActionRequest{
+			ActionID:      "act-1",
+			DecisionID:    "dec-1",
+			ActionType:    models.ActionTypeThrottle,
+			TargetService: "checkout",
+		})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return executor.running.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	result, err := svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-2",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeThrottle,
+		TargetService: "checkout",
+	})
+	assert.ErrorIs(t, err, ErrTargetBusy)
+	assert.Equal(t, "failed", result.Status)
+
+	close(executor.release)
+	require.NoError(t, <-done)
+
+	// The target is free again once the first action finishes
+	_, err = svc.Execute(context.Background(), &ActionRequest{
+		ActionID:      "act-3",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeThrottle,
+		TargetService: "checkout",
+	})
+	assert.NoError(t, err)
+}
+
+func TestExecuteBatchRespectsConcurrencyLimits(t *testing.T) {
+	executor := &blockingExecutor{release: make(chan struct{})}
+	svc := NewService(nil, "", false, nil)
+	svc.RegisterExecutor(models.ActionTypeThrottle, executor)
+	svc.SetConcurrencyLimits(3, map[models.ActionType]int{models.ActionTypeThrottle: 2})
+
+	var requests []*ActionRequest
+	for _, target := range []string{"a", "b", "c", "d", "e"} {
+		requests = append(requests, &ActionRequest{
+			ActionID:      "act-" + target,
+			DecisionID:    "dec-1",
+			ActionType:    models.ActionTypeThrottle,
+			TargetService: target,
+		})
+	}
+
+	var wg sync.WaitGroup
+	var results []*ActionResult
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results, _ = svc.ExecuteBatch(context.Background(), requests)
+	}()
+
+	require.Eventually(t, func() bool { return executor.running.Load() == 2 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(executor.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), executor.peak.Load())
+	require.Len(t, results, len(requests))
+	for i, result := range results {
+		assert.Equal(t, requests[i].ActionID, result.ActionID)
+		assert.Equal(t, "completed", result.Status)
+	}
+}
+
+func TestAcquireSlotsHonorsContext(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+	svc.SetConcurrencyLimits(1, nil)
+
+	release, err := svc.acquireSlots(context.Background(), models.ActionTypeThrottle)
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = svc.acquireSlots(ctx, models.ActionTypeScaleUp)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestExecuteEnforcesGuardrails(t *testing.T) {
+	var seen []map[string]interface{}
+	svc := NewService(nil, "", false, nil)
+	svc.RegisterExecutor(models.ActionTypeScaleUp, executorFunc(func(ctx context.Context, req *ActionRequest) (*Execution, error) {
+		seen = append(seen, req.Payload)
+		return &Execution{}, nil
+	}))
+	g := guardrail.New(guardrail.Config{
+		Services: map[string]guardrail.Limits{"checkout": {MaxInstancesPerHour: 3, MaxReplicas: 8}},
+	})
+	svc.SetGuardrails(g)
+
+	scaleUp := func(id string, instances int) (*ActionResult, error) {
+		return svc.Execute(context.Background(), &ActionRequest{
+			ActionID:      id,
+			DecisionID:    "dec-1",
+			ActionType:    models.ActionTypeScaleUp,
+			TargetService: "checkout",
+			Payload:       map[string]interface{}{"instances": float64(instances)},
+		})
+	}
+
+	_, err := scaleUp("act-1", 2)
+	require.NoError(t, err)
+	require.Len(t, seen, 1)
+	assert.Equal(t, 8, seen[0]["max_instances"])
+
+	result, err := scaleUp("act-2", 2)
+	assert.ErrorIs(t, err, guardrail.ErrBlocked)
+	assert.Contains(t, result.ErrorMessage, guardrail.MaxInstancesPerHour)
+	assert.Len(t, seen, 1)
+
+	// The kill switch turns actions into dry runs
+	g.SetKillSwitch(true)
+	result, err = scaleUp("act-3", 1)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Len(t, seen, 1)
+}
+
+// vetoHooks vetoes actions of one type
+type vetoHooks struct {
+	actionType models.ActionType
+}
+
+func (h vetoHooks) ExecuteBeforeAction(ctx context.Context, req *ActionRequest) error {
+	if req.ActionType == h.actionType {
+		return errors.New("frozen")
+	}
+	return nil
+}
+
+func (h vetoHooks) ExecuteAfterAction(ctx context.Context, req *ActionRequest, result *ActionResult) error {
+	return nil
+}
+
+func TestHooksVetoEveryPath(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+	svc.SetHooks(vetoHooks{actionType: models.ActionTypeScaleDown})
+	calls := 0
+	count := executorFunc(func(ctx context.Context, req *ActionRequest) (*Execution, error) {
+		calls++
+		return &Execution{}, nil
+	})
+	svc.RegisterExecutor(models.ActionTypeScaleDown, count)
+	svc.RegisterExecutor(models.ActionTypeThrottle, count)
+
+	// Scheduled and approved actions run through perform
+	result, err := svc.perform(context.Background(), &ActionRequest{
+		ActionID: "act-1", DecisionID: "dec-1", ActionType: models.ActionTypeScaleDown, TargetService: "checkout",
+	}, "", false)
+	assert.ErrorIs(t, err, ErrActionVetoed)
+	assert.Equal(t, "failed", result.Status)
+
+	// Later rollout stages go through the hooks on their own
+	svc.SetHooks(vetoHooks{actionType: models.ActionTypeThrottle})
+	staged := stagedRequest(models.ActionTypeThrottle, nil, 0.5, 1)
+	staged.Rollout = newRollout(staged.Rollout)
+	staged.Rollout.Current = 0
+	assert.ErrorIs(t, svc.applyStage(context.Background(), staged, ""), ErrActionVetoed)
+
+	assert.Zero(t, calls)
+}
@@ -1,15 +1,27 @@
 package action
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
 	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/notification"
 )
 
 // Handler handles HTTP requests for actions
 type Handler struct {
 	service *Service
+
+	slack              *notification.SlackNotifier
+	slackSigningSecret string
 }
 
 // NewHandler creates a new action handler
@@ -24,6 +36,21 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/actions/schedule", h.handleScheduleAction)
 	mux.HandleFunc("/actions/batch", h.handleBatchExecute)
 	mux.HandleFunc("/actions/{id}", h.handleGetAction)
+	mux.HandleFunc("/actions/{id}/approve", h.handleApproveAction)
+	mux.HandleFunc("/actions/{id}/reject", h.handleRejectAction)
+	mux.HandleFunc("/actions/{id}/cancel", h.handleCancelAction)
+	mux.HandleFunc("/actions/{id}/promote", h.handlePromoteAction)
+	mux.HandleFunc("/actions/{id}/abort", h.handleAbortAction)
+	if h.slackSigningSecret != "" {
+		mux.HandleFunc("/actions/approvals/slack", h.handleSlackInteraction)
+	}
+}
+
+// EnableSlackApprovals accepts approve and reject button clicks from Slack.
+// It must be called before RegisterRoutes.
+func (h *Handler) EnableSlackApprovals(signingSecret string, slack *notification.SlackNotifier) {
+	h.slackSigningSecret = signingSecret
+	h.slack = slack
 }
 
 func (h *Handler) handleExecuteAction(w http.ResponseWriter, r *http.Request) {
@@ -40,12 +67,29 @@ func (h *Handler) handleExecuteAction(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.service.Execute(r.Context(), &req)
 	if err != nil {
+		if errors.Is(err, ErrConflictingAction) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, guardrail.ErrBlocked) || errors.Is(err, ErrActionVetoed) {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, ErrRolloutNotSupported) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, ErrRolloutUnavailable) {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "execution failed: "+err.Error())
 		return
 	}
 
+	// Dry runs and staged rollouts still under way are accepted rather than done
 	w.Header().Set("Content-Type", "application/json")
-	if result.DryRun {
+	if result.DryRun || result.Status == "executing" {
 		w.WriteHeader(http.StatusAccepted)
 	} else {
 		w.WriteHeader(http.StatusOK)
@@ -67,6 +111,10 @@ func (h *Handler) handleScheduleAction(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.service.Schedule(r.Context(), &req)
 	if err != nil {
+		if errors.Is(err, ErrConflictingAction) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "scheduling failed: "+err.Error())
 		return
 	}
@@ -108,11 +156,32 @@ func (h *Handler) handleListActions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: implement with query params
+	q := r.URL.Query()
+	filters := models.ActionFilters{
+		DecisionID: q.Get("decision_id"),
+		ServiceID:  q.Get("service_id"),
+		Status:     q.Get("status"),
+		Limit:      100,
+	}
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			filters.Limit = n
+		}
+	}
+
+	actions, err := h.service.ListActions(r.Context(), filters)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list actions: "+err.Error())
+		return
+	}
+	if actions == nil {
+		actions = []*models.ActionRecord{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"actions": []interface{}{},
-		"count":   0,
+		"actions": actions,
+		"count":   len(actions),
 	})
 }
 
@@ -122,8 +191,229 @@ func (h *Handler) handleGetAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: implement
-	writeError(w, http.StatusNotImplemented, "not implemented")
+	actionID := r.PathValue("id")
+	record, err := h.service.GetAction(r.Context(), actionID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "action not found: "+actionID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get action: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
+
+// ReviewRequest is the body of an approve or reject request
+type ReviewRequest struct {
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+func (h *Handler) decodeReview(w http.ResponseWriter, r *http.Request) (*ReviewRequest, bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return nil, false
+	}
+
+	var req ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return nil, false
+	}
+	if req.Reviewer == "" {
+		writeError(w, http.StatusBadRequest, "reviewer is required")
+		return nil, false
+	}
+	return &req, true
+}
+
+func (h *Handler) handleApproveAction(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeReview(w, r)
+	if !ok {
+		return
+	}
+
+	actionID := r.PathValue("id")
+	result, err := h.service.Approve(r.Context(), actionID, req.Reviewer, req.Comment)
+	if err != nil && result == nil {
+		writeStatusError(w, actionID, "review", err)
+		return
+	}
+
+	// A failed execution after approval still reports the result
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handler) handleRejectAction(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeReview(w, r)
+	if !ok {
+		return
+	}
+
+	actionID := r.PathValue("id")
+	if err := h.service.Reject(r.Context(), actionID, req.Reviewer, req.Comment); err != nil {
+		writeStatusError(w, actionID, "review", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"action_id": actionID,
+		"status":    string(models.ActionStatusRejected),
+	})
+}
+
+func (h *Handler) handleSlackInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	if err := notification.VerifySlackSignature(h.slackSigningSecret,
+		r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now()); err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	interaction, err := notification.ParseInteraction(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Slack expects an acknowledgement within three seconds, and executing an
+	// approved action can take longer, so the outcome is posted back afterwards
+	w.WriteHeader(http.StatusOK)
+	if len(interaction.Actions) == 0 {
+		return
+	}
+
+	go h.reviewFromSlack(context.WithoutCancel(r.Context()), interaction)
+}
+
+func (h *Handler) reviewFromSlack(ctx context.Context, interaction *notification.Interaction) {
+	clicked := interaction.Actions[0]
+	reviewer := interaction.Reviewer()
+
+	var text string
+	switch clicked.ActionID {
+	case notification.ApprovalActionApprove:
+		result, err := h.service.Approve(ctx, clicked.Value, reviewer, "approved in Slack")
+		if err != nil && result == nil {
+			text = fmt.Sprintf("Could not approve %s: %v", clicked.Value, err)
+		} else {
+			text = fmt.Sprintf("%s approved by %s: %s", clicked.Value, reviewer, result.Status)
+		}
+	case notification.ApprovalActionReject:
+		if err := h.service.Reject(ctx, clicked.Value, reviewer, "rejected in Slack"); err != nil {
+			text = fmt.Sprintf("Could not reject %s: %v", clicked.Value, err)
+		} else {
+			text = fmt.Sprintf("%s rejected by %s", clicked.Value, reviewer)
+		}
+	default:
+		return
+	}
+
+	if h.slack != nil && interaction.ResponseURL != "" {
+		if err := h.slack.RespondToInteraction(ctx, interaction.ResponseURL, text); err != nil {
+			h.service.logger.Warn("failed to respond to slack interaction", "error", err)
+		}
+	}
+}
+
+func (h *Handler) handleCancelAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	actionID := r.PathValue("id")
+	if err := h.service.Cancel(r.Context(), actionID); err != nil {
+		writeStatusError(w, actionID, "cancel", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"action_id": actionID,
+		"status":    string(models.ActionStatusCancelled),
+	})
+}
+
+func (h *Handler) handlePromoteAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	actionID := r.PathValue("id")
+	rollout, err := h.service.Promote(r.Context(), actionID)
+	if err != nil && rollout == nil {
+		writeStatusError(w, actionID, "promote", err)
+		return
+	}
+
+	// A promotion whose next stage failed has rolled back, which the rollout shows
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"action_id": actionID,
+		"rollout":   rollout,
+	})
+}
+
+// AbortRequest is the body of an abort request
+type AbortRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *Handler) handleAbortAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req AbortRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+	}
+
+	actionID := r.PathValue("id")
+	rollout, err := h.service.Abort(r.Context(), actionID, req.Reason)
+	if err != nil && rollout == nil {
+		writeStatusError(w, actionID, "abort", err)
+		return
+	}
+
+	// A failed rollback is reported in the rollout's reason
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"action_id": actionID,
+		"rollout":   rollout,
+	})
+}
+
+// writeStatusError maps the errors of a status change to an HTTP response
+func writeStatusError(w http.ResponseWriter, actionID, op string, err error) {
+	switch {
+	case models.IsNotFound(err):
+		writeError(w, http.StatusNotFound, "action not found: "+actionID)
+	case errors.Is(err, models.ErrInvalidTransition), errors.Is(err, models.ErrApprovalExpired):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, op+" failed: "+err.Error())
+	}
 }
 
 func writeError(w http.ResponseWriter, status int, message string) {
@@ -134,30 +424,37 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
-// CreateActionRequest helper to create an action request
-func CreateActionRequest(decisionID, actionType, serviceID string, dryRun bool) *ActionRequest {
-	payload := map[string]interface{}{
-		"timestamp": "now",
+// RequestFromDecision builds the request for the index-th action of a
+// decision, carrying over the rule's params as the payload
+func RequestFromDecision(decisionID string, index int, a models.Action, dryRun bool) (*ActionRequest, error) {
+	var payload map[string]interface{}
+	if len(a.Payload) > 0 {
+		if err := json.Unmarshal(a.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("invalid payload for %s action: %w", a.Type, err)
+		}
+	}
+	if payload == nil {
+		payload = map[string]interface{}{}
 	}
 
-	// Add specific params based on action type
-	switch models.ActionType(actionType) {
-	case models.ActionTypeScaleUp:
-		payload["instances"] = 2
-		payload["urgency"] = "high"
-	case models.ActionTypeScaleDown:
-		payload["instances"] = 1
-		payload["min_instances"] = 2
-	case models.ActionTypeOpenCircuit:
-		payload["duration"] = "60s"
+	// A rule asks for a staged rollout with a rollout param
+	var rollout *models.Rollout
+	if plan, ok := payload["rollout"]; ok {
+		rollout = &models.Rollout{}
+		if err := json.Unmarshal(mustMarshal(plan), rollout); err != nil {
+			return nil, fmt.Errorf("invalid rollout for %s action: %w", a.Type, err)
+		}
+		delete(payload, "rollout")
 	}
 
 	return &ActionRequest{
-		ActionID:      "act-" + decisionID[:8],
+		ActionID:      fmt.Sprintf("act-%s-%d", strings.TrimPrefix(decisionID, "dec-"), index),
 		DecisionID:    decisionID,
-		ActionType:    models.ActionType(actionType),
-		TargetService: serviceID,
+		ActionType:    a.Type,
+		TargetService: a.Target,
 		Payload:       payload,
 		DryRun:        dryRun,
-	}
+		Cost:          a.Cost,
+		Rollout:       rollout,
+	}, nil
 }
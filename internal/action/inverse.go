@@ -0,0 +1,127 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// ErrNotInvertible is returned when no inverse can be derived for an action
+var ErrNotInvertible = errors.New("action cannot be inverted")
+
+// Inverter derives the action that undoes a completed action
+type Inverter interface {
+	Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error)
+}
+
+// InverterFunc adapts a function to the Inverter interface
+type InverterFunc func(ctx context.Context, original *ActionRequest) (*ActionRequest, error)
+
+// Invert calls f(ctx, original)
+func (f InverterFunc) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	return f(ctx, original)
+}
+
+// defaultInverses maps action types to the type that undoes them. The payload
+// is carried over unchanged, so a scale_up of N instances becomes a
+// scale_down of N instances.
+var defaultInverses = map[models.ActionType]models.ActionType{
+	models.ActionTypeScaleUp:     models.ActionTypeScaleDown,
+	models.ActionTypeScaleDown:   models.ActionTypeScaleUp,
+	models.ActionTypeThrottle:    models.ActionTypeUnthrottle,
+	models.ActionTypeOpenCircuit: models.ActionTypeCloseCircuit,
+}
+
+// RegisterInverter sets a custom inverter for an action type, overriding the default
+func (s *Service) RegisterInverter(actionType models.ActionType, inverter Inverter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inverters[actionType] = inverter
+}
+
+// Inverse derives the action that undoes original. Custom inverters take
+// precedence over the inverse declared by the action type's executor.
+func (s *Service) Inverse(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	s.mu.RLock()
+	inverter, ok := s.inverters[original.ActionType]
+	s.mu.RUnlock()
+	if !ok {
+		inverter = s.executorFor(original.ActionType)
+	}
+
+	inverse, err := inverter.Invert(ctx, original)
+	if err != nil {
+		return nil, err
+	}
+	inverse.RollbackOf = original.ActionID
+	// Undoing a dry run must not touch the target for real
+	inverse.DryRun = inverse.DryRun || original.DryRun
+	return inverse, nil
+}
+
+// invertByType returns the built-in inverse of original, carrying its payload over
+func invertByType(original *ActionRequest) (*ActionRequest, error) {
+	inverseType, ok := defaultInverses[original.ActionType]
+	if !ok {
+		return nil, fmt.Errorf("%w: no inverse for %s", ErrNotInvertible, original.ActionType)
+	}
+
+	payload := make(map[string]interface{}, len(original.Payload))
+	for k, v := range original.Payload {
+		payload[k] = v
+	}
+
+	return &ActionRequest{
+		DecisionID:    original.DecisionID,
+		ActionType:    inverseType,
+		TargetService: original.TargetService,
+		Payload:       payload,
+		WebhookURL:    original.WebhookURL,
+		RollbackOf:    original.ActionID,
+		DryRun:        original.DryRun,
+	}, nil
+}
+
+// ForcedRollback builds a webhook action asking the receiver to undo original
+// by hand. It is used when a rollback is forced on an action with no inverse.
+func ForcedRollback(original *ActionRequest) *ActionRequest {
+	return &ActionRequest{
+		DecisionID:    original.DecisionID,
+		ActionType:    models.ActionTypeWebhook,
+		TargetService: original.TargetService,
+		Payload: map[string]interface{}{
+			"rollback_of":      original.ActionID,
+			"original_type":    original.ActionType,
+			"original_payload": original.Payload,
+		},
+		WebhookURL: original.WebhookURL,
+		RollbackOf: original.ActionID,
+		DryRun:     original.DryRun,
+	}
+}
+
+// RequestFromRecord rebuilds the request of a stored action
+func RequestFromRecord(record *models.ActionRecord) (*ActionRequest, error) {
+	var payload map[string]interface{}
+	if len(record.ActionPayload) > 0 {
+		if err := json.Unmarshal(record.ActionPayload, &payload); err != nil {
+			return nil, fmt.Errorf("invalid payload for action %s: %w", record.ActionID, err)
+		}
+	}
+	return &ActionRequest{
+		ActionID:      record.ActionID,
+		DecisionID:    record.DecisionID,
+		ActionType:    record.ActionType,
+		TargetService: record.TargetService,
+		Payload:       payload,
+		DryRun:        record.DryRun,
+		WebhookURL:    record.WebhookURL,
+		RollbackOf:    record.RollbackOf,
+		Cost:          record.Cost,
+		Rollout:       record.Rollout,
+		Response:      record.WebhookResponse,
+	}, nil
+}
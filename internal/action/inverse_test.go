@@ -0,0 +1,92 @@
+package action
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInverseDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		original models.ActionType
+		want     models.ActionType
+		wantErr  bool
+	}{
+		{name: "scale up", original: models.ActionTypeScaleUp, want: models.ActionTypeScaleDown},
+		{name: "scale down", original: models.ActionTypeScaleDown, want: models.ActionTypeScaleUp},
+		{name: "throttle", original: models.ActionTypeThrottle, want: models.ActionTypeUnthrottle},
+		{name: "open circuit", original: models.ActionTypeOpenCircuit, want: models.ActionTypeCloseCircuit},
+		{name: "webhook", original: models.ActionTypeWebhook, wantErr: true},
+		{name: "close circuit", original: models.ActionTypeCloseCircuit, wantErr: true},
+	}
+
+	svc := NewService(nil, "", false, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := &ActionRequest{
+				ActionID:      "act-1",
+				DecisionID:    "dec-1",
+				ActionType:    tt.original,
+				TargetService: "checkout",
+				Payload:       map[string]interface{}{"instances": 3},
+			}
+
+			inverse, err := svc.Inverse(context.Background(), original)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrNotInvertible)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, inverse.ActionType)
+			assert.Equal(t, "checkout", inverse.TargetService)
+			assert.Equal(t, "act-1", inverse.RollbackOf)
+			assert.Equal(t, 3, inverse.Payload["instances"])
+		})
+	}
+}
+
+func TestInverseCustomInverter(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+	svc.RegisterInverter(models.ActionTypeWebhook, InverterFunc(func(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+		return &ActionRequest{
+			DecisionID:    original.DecisionID,
+			ActionType:    models.ActionTypeWebhook,
+			TargetService: original.TargetService,
+			Payload:       map[string]interface{}{"undo": true},
+		}, nil
+	}))
+
+	original := &ActionRequest{ActionID: "act-1", ActionType: models.ActionTypeWebhook, TargetService: "checkout"}
+	inverse, err := svc.Inverse(context.Background(), original)
+	require.NoError(t, err)
+	assert.Equal(t, true, inverse.Payload["undo"])
+	assert.Equal(t, "act-1", inverse.RollbackOf)
+}
+
+func TestInverseOfDryRun(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+	original := &ActionRequest{ActionID: "act-1", ActionType: models.ActionTypeScaleUp, TargetService: "checkout", DryRun: true}
+
+	inverse, err := svc.Inverse(context.Background(), original)
+	require.NoError(t, err)
+	assert.True(t, inverse.DryRun)
+	assert.True(t, ForcedRollback(original).DryRun)
+}
+
+func TestForcedRollback(t *testing.T) {
+	original := &ActionRequest{
+		ActionID:      "act-1",
+		DecisionID:    "dec-1",
+		ActionType:    models.ActionTypeCloseCircuit,
+		TargetService: "checkout",
+	}
+
+	inverse := ForcedRollback(original)
+	assert.Equal(t, models.ActionTypeWebhook, inverse.ActionType)
+	assert.Equal(t, "act-1", inverse.Payload["rollback_of"])
+	assert.Equal(t, "act-1", inverse.RollbackOf)
+}
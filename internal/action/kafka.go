@@ -0,0 +1,59 @@
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageWriter publishes Kafka messages. *kafka.Writer satisfies it.
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// KafkaExecutor publishes actions to a topic for downstream consumers to
+// carry out, keyed by target service so a service's actions stay in order
+type KafkaExecutor struct {
+	writer MessageWriter
+	topic  string
+}
+
+// NewKafkaExecutor creates a Kafka executor. The writer must already be bound
+// to topic, which is only recorded on the action. It must be synchronous, so
+// that an action only completes once published, and balance by key for the
+// actions on a service to stay in order.
+func NewKafkaExecutor(writer MessageWriter, topic string) *KafkaExecutor {
+	return &KafkaExecutor{
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+// Execute publishes the action
+func (e *KafkaExecutor) Execute(ctx context.Context, req *ActionRequest) (*Execution, error) {
+	msg := kafka.Message{
+		Key:   []byte(req.TargetService),
+		Value: mustMarshal(actionMessage(req)),
+		Headers: []kafka.Header{
+			{Key: "action_id", Value: []byte(req.ActionID)},
+			{Key: "action_type", Value: []byte(req.ActionType)},
+		},
+	}
+	if err := e.writer.WriteMessages(ctx, msg); err != nil {
+		return nil, fmt.Errorf("failed to publish action to %s: %w", e.topic, err)
+	}
+
+	return &Execution{
+		Response: mustMarshal(map[string]interface{}{
+			"topic": e.topic,
+			"key":   req.TargetService,
+		}),
+	}, nil
+}
+
+// Invert returns the built-in inverse of the action, leaving the consumer to
+// interpret it
+func (e *KafkaExecutor) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	return invertByType(original)
+}
@@ -0,0 +1,510 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// ErrRolloutNotSupported is returned for staged actions of a type that cannot
+// be applied in part
+var ErrRolloutNotSupported = errors.New("staged rollout not supported")
+
+// ErrRolloutUnavailable is returned for staged actions when the service runs
+// without an action store to keep the rollout state in
+var ErrRolloutUnavailable = errors.New("staged rollouts need an action store")
+
+// stagedTypes are the action types that may be rolled out in stages
+var stagedTypes = map[models.ActionType]bool{
+	models.ActionTypeThrottle:  true,
+	models.ActionTypeScaleDown: true,
+}
+
+// RolloutObserver measures a service around each stage of a rollout and
+// decides whether the rollout may go on. feedback.RolloutObserver implements it.
+type RolloutObserver interface {
+	// Metrics returns the current metrics of a service
+	Metrics(ctx context.Context, serviceID string) (map[string]float64, error)
+	// Assess compares the metrics before and after a stage and returns one of
+	// the RolloutVerdict constants along with the impact score
+	Assess(ctx context.Context, req *ActionRequest, stage int, before, after map[string]float64) (verdict string, impact float64, err error)
+}
+
+// SetRolloutObserver sets how rollout stages are judged. Without an observer
+// every stage proceeds once its observation window has passed.
+func (s *Service) SetRolloutObserver(observer RolloutObserver) {
+	s.rolloutObserver = observer
+}
+
+// SetRolloutWindow sets the observation window of rollouts that set none
+func (s *Service) SetRolloutWindow(window time.Duration) {
+	s.rolloutWindow = window
+}
+
+// validateRollout checks the rollout plan of a staged request
+func validateRollout(req *ActionRequest) error {
+	if req.Rollout == nil {
+		return nil
+	}
+	if !stagedTypes[req.ActionType] {
+		return fmt.Errorf("%w for %s", ErrRolloutNotSupported, req.ActionType)
+	}
+	if _, ok := req.Payload["replicas"]; ok {
+		return fmt.Errorf("%w: set instances rather than replicas", ErrRolloutNotSupported)
+	}
+	return req.Rollout.Validate()
+}
+
+// newRollout returns the state a rollout plan starts in: evaluating, with no
+// stage applied yet
+func newRollout(plan *models.Rollout) *models.Rollout {
+	if plan == nil {
+		return nil
+	}
+	stages := make([]models.RolloutStage, len(plan.Stages))
+	for i, stage := range plan.Stages {
+		stages[i] = models.RolloutStage{Fraction: stage.Fraction, Zone: stage.Zone}
+	}
+	return &models.Rollout{
+		Stages:  stages,
+		Window:  plan.Window,
+		Current: -1,
+		Status:  models.RolloutStatusEvaluating,
+	}
+}
+
+// stageInstances returns how many instances a stage applies: the cumulative
+// share of the action's instances up to the stage, less what earlier stages applied
+func stageInstances(req *ActionRequest, stage int) (int, bool, error) {
+	instances, ok, err := intParam(req.Payload, "instances")
+	if err != nil {
+		return 0, false, err
+	}
+	if !ok {
+		if req.ActionType != models.ActionTypeScaleDown {
+			return 0, false, nil
+		}
+		instances = 1
+	}
+	applied := func(i int) int {
+		if i < 0 {
+			return 0
+		}
+		return int(math.Ceil(float64(instances) * req.Rollout.Stages[i].Fraction))
+	}
+	return applied(stage) - applied(stage-1), true, nil
+}
+
+// appliedRequest returns req as applied up to the current stage, for inverting
+func appliedRequest(req *ActionRequest) *ActionRequest {
+	applied := *req
+	applied.Payload = make(map[string]interface{}, len(req.Payload))
+	for k, v := range req.Payload {
+		applied.Payload[k] = v
+	}
+
+	if instances, ok, _ := intParam(req.Payload, "instances"); ok || req.ActionType == models.ActionTypeScaleDown {
+		if !ok {
+			instances = 1
+		}
+		fraction := req.Rollout.Stages[req.Rollout.Current].Fraction
+		applied.Payload["instances"] = int(math.Ceil(float64(instances) * fraction))
+	}
+	for _, stage := range req.Rollout.Stages {
+		if len(stage.Response) > 0 {
+			applied.Response = stage.Response
+			break
+		}
+	}
+	return &applied
+}
+
+// applyStage carries out the next stage of a rollout and starts its
+// observation window. The caller holds the target lock.
+func (s *Service) applyStage(ctx context.Context, req *ActionRequest, webhookURL string) error {
+	rollout := req.Rollout
+	stage := rollout.Current + 1
+	from := rollout.Status
+
+	before := map[string]float64{}
+	if s.rolloutObserver != nil {
+		metrics, err := s.rolloutObserver.Metrics(ctx, req.TargetService)
+		if err != nil {
+			s.logger.Warn("failed to measure service before rollout stage",
+				"action_id", req.ActionID,
+				"stage", stage,
+				"error", err,
+			)
+		} else {
+			before = metrics
+		}
+	}
+
+	execReq := *req
+	if stage > 0 {
+		// The first stage went through the hooks with the action itself;
+		// each later stage may be vetoed or enriched on its own
+		execReq.Payload = make(map[string]interface{}, len(req.Payload))
+		for k, v := range req.Payload {
+			execReq.Payload[k] = v
+		}
+		if err := s.beforeAction(ctx, &execReq); err != nil {
+			return fmt.Errorf("rollout stage %d: %w", stage, err)
+		}
+	}
+	execReq.WebhookURL = webhookURL
+	execReq.Payload = s.boundPayload(&execReq)
+	payload := make(map[string]interface{}, len(execReq.Payload)+3)
+	for k, v := range execReq.Payload {
+		payload[k] = v
+	}
+	payload["rollout_stage"] = stage
+	payload["rollout_fraction"] = rollout.Stages[stage].Fraction
+	if zone := rollout.Stages[stage].Zone; zone != "" {
+		payload["zone"] = zone
+	}
+	execReq.Payload = payload
+
+	instances, counted, err := stageInstances(req, stage)
+	if err != nil {
+		return err
+	}
+	if counted {
+		payload["instances"] = instances
+	}
+
+	var response json.RawMessage
+	if !counted || instances > 0 {
+		execution, err := s.executorFor(req.ActionType).Execute(ctx, &execReq)
+		if err != nil {
+			return fmt.Errorf("rollout stage %d: %w", stage, err)
+		}
+		response = execution.Response
+	}
+
+	now := time.Now()
+	next := now.Add(rollout.WindowDuration(s.rolloutWindow))
+	rollout.Current = stage
+	rollout.Stages[stage].AppliedAt = &now
+	rollout.Stages[stage].Response = response
+	rollout.Stages[stage].MetricsBefore = before
+	rollout.Status = models.RolloutStatusObserving
+	rollout.NextCheckAt = &next
+	rollout.Reason = ""
+	if err := s.saveRollout(ctx, req.ActionID, from, rollout); err != nil {
+		return err
+	}
+
+	s.logger.Info("rollout stage applied",
+		"action_id", req.ActionID,
+		"type", req.ActionType,
+		"target", req.TargetService,
+		"stage", stage,
+		"fraction", rollout.Stages[stage].Fraction,
+		"next_check_at", next,
+	)
+	return nil
+}
+
+// saveRollout persists a rollout still in status from
+func (s *Service) saveRollout(ctx context.Context, actionID string, from models.RolloutStatus, rollout *models.Rollout) error {
+	if s.actionStore == nil {
+		return nil
+	}
+	return s.actionStore.UpdateRollout(ctx, actionID, from, rollout)
+}
+
+// startRollout applies the first stage of a staged action. Later stages are
+// applied by RunRollouts once each observation window has passed.
+func (s *Service) startRollout(ctx context.Context, req *ActionRequest, webhookURL string, result *ActionResult) (*ActionResult, error) {
+	if s.actionStore == nil {
+		return s.fail(ctx, req, result, ErrRolloutUnavailable)
+	}
+
+	// Requests rebuilt from a record already carry the rollout state
+	if req.Rollout.Status == "" {
+		staged := *req
+		staged.Rollout = newRollout(req.Rollout)
+		req = &staged
+	}
+	if err := s.applyStage(ctx, req, webhookURL); err != nil {
+		return s.fail(ctx, req, result, err)
+	}
+
+	result.Metadata = map[string]interface{}{"rollout": req.Rollout}
+	return result, nil
+}
+
+// RunRollouts evaluates rollout stages whose observation window has passed
+// until ctx is done
+func (s *Service) RunRollouts(ctx context.Context, interval time.Duration) {
+	if s.actionStore == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		records, err := s.actionStore.ClaimDueRollouts(ctx, 10)
+		if err != nil {
+			s.logger.Warn("failed to claim due rollouts", "error", err)
+			continue
+		}
+
+		for _, record := range records {
+			wg.Add(1)
+			go func(record *models.ActionRecord) {
+				defer wg.Done()
+				// Claimed rollouts are evaluating, so finish them even during shutdown
+				if err := s.evaluateRollout(context.WithoutCancel(ctx), record); err != nil {
+					s.logger.Error("rollout evaluation failed", "action_id", record.ActionID, "error", err)
+				}
+			}(record)
+		}
+	}
+}
+
+// evaluateRollout judges the stage a claimed rollout last applied and goes on,
+// pauses or rolls back accordingly
+func (s *Service) evaluateRollout(ctx context.Context, record *models.ActionRecord) error {
+	req, err := RequestFromRecord(record)
+	if err != nil {
+		return err
+	}
+	rollout := req.Rollout
+	stage := &rollout.Stages[rollout.Current]
+
+	if s.killSwitch() {
+		return s.pauseRollout(ctx, req, "kill switch on")
+	}
+
+	verdict := models.RolloutVerdictProceed
+	if s.rolloutObserver != nil {
+		after, err := s.rolloutObserver.Metrics(ctx, req.TargetService)
+		if err != nil {
+			return s.pauseRollout(ctx, req, "failed to measure service: "+err.Error())
+		}
+		var impact float64
+		verdict, impact, err = s.rolloutObserver.Assess(ctx, req, rollout.Current, stage.MetricsBefore, after)
+		if err != nil {
+			return s.pauseRollout(ctx, req, "failed to assess stage: "+err.Error())
+		}
+		stage.ImpactScore = &impact
+	}
+	stage.Verdict = verdict
+
+	switch verdict {
+	case models.RolloutVerdictRollback:
+		return s.rollBackRollout(ctx, req, fmt.Sprintf("stage %d degraded the service", rollout.Current))
+	case models.RolloutVerdictPause:
+		return s.pauseRollout(ctx, req, fmt.Sprintf("stage %d needs review", rollout.Current))
+	}
+	return s.advanceRollout(ctx, req, record.WebhookURL)
+}
+
+// advanceRollout applies the next stage of an evaluating rollout, or completes
+// the action after the last one
+func (s *Service) advanceRollout(ctx context.Context, req *ActionRequest, webhookURL string) error {
+	rollout := req.Rollout
+	if rollout.Current == len(rollout.Stages)-1 {
+		return s.completeRollout(ctx, req)
+	}
+
+	// Each stage goes out for real, so it is held back like a new action
+	// while the kill switch is on, a freeze window is open or a guardrail
+	// would block it
+	if s.killSwitch() {
+		return s.pauseRollout(ctx, req, "kill switch on")
+	}
+	if v := s.checkStage(req); v != nil {
+		return s.pauseRollout(ctx, req, v.Error())
+	}
+
+	release, err := s.lockTarget(ctx, req.TargetService)
+	if err != nil {
+		return s.pauseRollout(ctx, req, err.Error())
+	}
+	err = s.applyStage(ctx, req, webhookURL)
+	release()
+
+	// The rollback takes the target lock itself
+	if err != nil {
+		return s.rollBackRollout(ctx, req, err.Error())
+	}
+	return nil
+}
+
+// checkStage checks the next stage of a rollout against the guardrails. The
+// action's cost was counted when it started, so only the other limits apply.
+func (s *Service) checkStage(req *ActionRequest) *guardrail.Violation {
+	if s.guardrails == nil {
+		return nil
+	}
+	r := guardrail.NewRequest(req.TargetService, req.ActionType, req.Payload, 0)
+	r.ActionID = req.ActionID
+	return s.guardrails.Check(r)
+}
+
+// completeRollout marks a rollout and its action completed
+func (s *Service) completeRollout(ctx context.Context, req *ActionRequest) error {
+	rollout := req.Rollout
+	rollout.Status = models.RolloutStatusCompleted
+	rollout.NextCheckAt = nil
+	if err := s.saveRollout(ctx, req.ActionID, models.RolloutStatusEvaluating, rollout); err != nil {
+		return err
+	}
+
+	s.markExecuted(ctx, req.ActionID, appliedRequest(req).Response)
+	s.logger.Info("rollout completed",
+		"action_id", req.ActionID,
+		"type", req.ActionType,
+		"target", req.TargetService,
+		"stages", len(rollout.Stages),
+	)
+
+	now := time.Now()
+	result := &ActionResult{
+		ActionID:    req.ActionID,
+		Status:      "completed",
+		ExecutedAt:  now,
+		CompletedAt: &now,
+		Metadata:    map[string]interface{}{"rollout": rollout},
+	}
+	s.afterAction(ctx, req, result)
+	if s.feedbackCollector != nil {
+		s.feedbackCollector.ActionCompleted(ctx, req, result)
+	}
+	return nil
+}
+
+// pauseRollout holds an evaluating rollout until it is promoted or aborted
+func (s *Service) pauseRollout(ctx context.Context, req *ActionRequest, reason string) error {
+	rollout := req.Rollout
+	rollout.Status = models.RolloutStatusPaused
+	rollout.NextCheckAt = nil
+	rollout.Reason = reason
+	if err := s.saveRollout(ctx, req.ActionID, models.RolloutStatusEvaluating, rollout); err != nil {
+		return err
+	}
+
+	s.logger.Warn("rollout paused",
+		"action_id", req.ActionID,
+		"target", req.TargetService,
+		"stage", rollout.Current,
+		"reason", reason,
+	)
+	return nil
+}
+
+// rollBackRollout undoes the stages applied so far and fails the action
+func (s *Service) rollBackRollout(ctx context.Context, req *ActionRequest, reason string) error {
+	rollout := req.Rollout
+	var rollbackErr error
+	if rollout.Current >= 0 {
+		applied := appliedRequest(req)
+		inverse, err := s.Inverse(ctx, applied)
+		if err == nil {
+			inverse.ActionID = fmt.Sprintf("rbk-%d", time.Now().UnixNano())
+			_, err = s.Execute(ctx, inverse)
+		}
+		rollbackErr = err
+	}
+
+	rollout.Status = models.RolloutStatusRolledBack
+	rollout.NextCheckAt = nil
+	rollout.Reason = reason
+	if rollbackErr != nil {
+		rollout.Reason = fmt.Sprintf("%s; rollback failed: %v", reason, rollbackErr)
+	}
+	if err := s.saveRollout(ctx, req.ActionID, models.RolloutStatusEvaluating, rollout); err != nil {
+		return err
+	}
+	s.transition(ctx, req.ActionID, models.ActionStatusExecuting, models.ActionStatusFailed, "rollout rolled back: "+rollout.Reason)
+
+	s.logger.Warn("rollout rolled back",
+		"action_id", req.ActionID,
+		"target", req.TargetService,
+		"stage", rollout.Current,
+		"reason", rollout.Reason,
+	)
+	return rollbackErr
+}
+
+// claimRollout moves a running or paused rollout to evaluating for a manual
+// promote or abort
+func (s *Service) claimRollout(ctx context.Context, actionID string) (*ActionRequest, string, error) {
+	if s.actionStore == nil {
+		return nil, "", fmt.Errorf("action store not available")
+	}
+
+	record, err := s.actionStore.GetByID(ctx, actionID)
+	if err != nil {
+		return nil, "", err
+	}
+	if record.Rollout == nil {
+		return nil, "", fmt.Errorf("%w: %s is not a staged action", models.ErrInvalidTransition, actionID)
+	}
+	from := record.Rollout.Status
+	if from != models.RolloutStatusObserving && from != models.RolloutStatusPaused {
+		return nil, "", fmt.Errorf("%w: rollout of %s is %s", models.ErrInvalidTransition, actionID, from)
+	}
+
+	req, err := RequestFromRecord(record)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Rollout.Status = models.RolloutStatusEvaluating
+	if err := s.actionStore.UpdateRollout(ctx, actionID, from, req.Rollout); err != nil {
+		return nil, "", err
+	}
+	return req, record.WebhookURL, nil
+}
+
+// Promote moves a staged action on to its next stage without waiting for its
+// observation window, or completes it after the last stage
+func (s *Service) Promote(ctx context.Context, actionID string) (*models.Rollout, error) {
+	req, webhookURL, err := s.claimRollout(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+	req.Rollout.Stages[req.Rollout.Current].Verdict = models.RolloutVerdictPromoted
+
+	s.logger.Info("rollout promoted", "action_id", actionID, "stage", req.Rollout.Current)
+	if err := s.advanceRollout(ctx, req, webhookURL); err != nil {
+		return req.Rollout, err
+	}
+	return req.Rollout, nil
+}
+
+// Abort rolls back the stages a staged action has applied
+func (s *Service) Abort(ctx context.Context, actionID, reason string) (*models.Rollout, error) {
+	req, _, err := s.claimRollout(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "aborted"
+	}
+
+	if err := s.rollBackRollout(ctx, req, reason); err != nil {
+		return req.Rollout, err
+	}
+	return req.Rollout, nil
+}
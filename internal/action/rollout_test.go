@@ -0,0 +1,139 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stagedRequest(actionType models.ActionType, payload map[string]interface{}, fractions ...float64) *ActionRequest {
+	rollout := &models.Rollout{}
+	for _, f := range fractions {
+		rollout.Stages = append(rollout.Stages, models.RolloutStage{Fraction: f})
+	}
+	return &ActionRequest{
+		ActionID:      "act-1",
+		DecisionID:    "dec-1",
+		ActionType:    actionType,
+		TargetService: "checkout",
+		Payload:       payload,
+		Rollout:       rollout,
+	}
+}
+
+func TestRolloutValidation(t *testing.T) {
+	assert.NoError(t, stagedRequest(models.ActionTypeThrottle, nil, 0.1, 0.5, 1).Validate())
+	assert.ErrorIs(t, stagedRequest(models.ActionTypeScaleUp, nil, 0.5, 1).Validate(), ErrRolloutNotSupported)
+	assert.ErrorIs(t, stagedRequest(models.ActionTypeScaleDown,
+		map[string]interface{}{"replicas": float64(2)}, 0.5, 1).Validate(), ErrRolloutNotSupported)
+
+	assert.Error(t, stagedRequest(models.ActionTypeThrottle, nil, 0.5, 0.5, 1).Validate())
+	assert.Error(t, stagedRequest(models.ActionTypeThrottle, nil, 0.1, 0.5).Validate())
+	assert.Error(t, stagedRequest(models.ActionTypeThrottle, nil).Validate())
+
+	req := stagedRequest(models.ActionTypeThrottle, nil, 1)
+	req.Rollout.Window = "soon"
+	assert.Error(t, req.Validate())
+}
+
+func TestStageInstances(t *testing.T) {
+	req := stagedRequest(models.ActionTypeScaleDown, map[string]interface{}{"instances": float64(10)}, 0.1, 0.5, 1)
+
+	var total int
+	for stage, want := range []int{1, 4, 5} {
+		n, counted, err := stageInstances(req, stage)
+		require.NoError(t, err)
+		assert.True(t, counted)
+		assert.Equal(t, want, n, "stage %d", stage)
+		total += n
+	}
+	assert.Equal(t, 10, total)
+
+	// A single instance goes out in the first stage that covers it
+	req = stagedRequest(models.ActionTypeScaleDown, nil, 0.1, 1)
+	n, _, _ := stageInstances(req, 0)
+	assert.Equal(t, 1, n)
+	n, _, _ = stageInstances(req, 1)
+	assert.Equal(t, 0, n)
+
+	// Throttles without instances are applied per zone or fraction as given
+	_, counted, err := stageInstances(stagedRequest(models.ActionTypeThrottle, nil, 0.5, 1), 0)
+	require.NoError(t, err)
+	assert.False(t, counted)
+}
+
+func TestAppliedRequestInvertsAppliedStages(t *testing.T) {
+	req := stagedRequest(models.ActionTypeScaleDown, map[string]interface{}{"instances": float64(10)}, 0.1, 0.5, 1)
+	req.Rollout.Current = 1
+	req.Rollout.Stages[0].Response = json.RawMessage(`{"previous_replicas":20}`)
+	req.Rollout.Stages[1].Response = json.RawMessage(`{"previous_replicas":19}`)
+
+	applied := appliedRequest(req)
+	assert.Equal(t, 5, applied.Payload["instances"])
+	assert.JSONEq(t, `{"previous_replicas":20}`, string(applied.Response))
+	assert.Equal(t, float64(10), req.Payload["instances"])
+
+	inverse, err := invertByType(applied)
+	require.NoError(t, err)
+	assert.Equal(t, models.ActionTypeScaleUp, inverse.ActionType)
+	assert.Equal(t, 5, inverse.Payload["instances"])
+}
+
+func TestRolloutNeedsActionStore(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+	calls := 0
+	svc.RegisterExecutor(models.ActionTypeThrottle, executorFunc(func(ctx context.Context, req *ActionRequest) (*Execution, error) {
+		calls++
+		return &Execution{}, nil
+	}))
+
+	result, err := svc.Execute(context.Background(), stagedRequest(models.ActionTypeThrottle, nil, 0.5, 1))
+	assert.ErrorIs(t, err, ErrRolloutUnavailable)
+	assert.Nil(t, result)
+	assert.Zero(t, calls)
+}
+
+// frozen is a freeze calendar that is always open
+type frozen struct{}
+
+func (frozen) Frozen(at time.Time) (string, bool) { return "release-freeze", true }
+
+func TestAdvanceRolloutHeldBack(t *testing.T) {
+	svc := NewService(nil, "", false, nil)
+	calls := 0
+	svc.RegisterExecutor(models.ActionTypeThrottle, executorFunc(func(ctx context.Context, req *ActionRequest) (*Execution, error) {
+		calls++
+		return &Execution{}, nil
+	}))
+	guardrails := guardrail.New(guardrail.Config{})
+	svc.SetGuardrails(guardrails)
+
+	staged := func() *ActionRequest {
+		req := stagedRequest(models.ActionTypeThrottle, nil, 0.5, 1)
+		req.Rollout = newRollout(req.Rollout)
+		req.Rollout.Current = 0
+		req.Rollout.Status = models.RolloutStatusEvaluating
+		return req
+	}
+
+	guardrails.SetKillSwitch(true)
+	req := staged()
+	require.NoError(t, svc.advanceRollout(context.Background(), req, ""))
+	assert.Equal(t, models.RolloutStatusPaused, req.Rollout.Status)
+	assert.Equal(t, "kill switch on", req.Rollout.Reason)
+
+	guardrails.SetKillSwitch(false)
+	guardrails.SetFreezeCalendar(frozen{})
+	req = staged()
+	require.NoError(t, svc.advanceRollout(context.Background(), req, ""))
+	assert.Equal(t, models.RolloutStatusPaused, req.Rollout.Status)
+	assert.Contains(t, req.Rollout.Reason, guardrail.FreezeWindow)
+
+	assert.Zero(t, calls)
+}
@@ -0,0 +1,212 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// ScaleExecutor runs scale_up and scale_down actions against the scale
+// subresource of a Kubernetes-style API. The target service names the
+// deployment.
+//
+// The payload either sets "replicas" outright or moves the current count by
+// "instances" (default 1), bounded by "min_instances" and "max_instances".
+// "namespace" overrides the executor's namespace.
+type ScaleExecutor struct {
+	apiURL     string
+	namespace  string
+	token      string
+	httpClient *http.Client
+}
+
+// NewScaleExecutor creates a scale executor for the API at apiURL. token, if
+// set, is sent as a bearer token.
+func NewScaleExecutor(apiURL, namespace, token string, httpClient *http.Client) *ScaleExecutor {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &ScaleExecutor{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		namespace:  namespace,
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// scale is the part of the scale subresource the executor reads and writes
+type scale struct {
+	Spec struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+}
+
+// scaleResult is stored as the action's response
+type scaleResult struct {
+	Deployment       string `json:"deployment"`
+	Namespace        string `json:"namespace"`
+	PreviousReplicas int    `json:"previous_replicas"`
+	Replicas         int    `json:"replicas"`
+}
+
+// Execute sets the deployment's replica count
+func (e *ScaleExecutor) Execute(ctx context.Context, req *ActionRequest) (*Execution, error) {
+	namespace := e.namespace
+	if ns, ok := req.Payload["namespace"].(string); ok && ns != "" {
+		namespace = ns
+	}
+	endpoint := fmt.Sprintf("%s/apis/apps/v1/namespaces/%s/deployments/%s/scale",
+		e.apiURL, url.PathEscape(namespace), url.PathEscape(req.TargetService))
+
+	var current scale
+	if _, err := e.do(ctx, http.MethodGet, endpoint, nil, &current); err != nil {
+		return nil, fmt.Errorf("failed to read scale of %s: %w", req.TargetService, err)
+	}
+
+	replicas, err := desiredReplicas(req, current.Spec.Replicas)
+	if err != nil {
+		return nil, err
+	}
+
+	result := scaleResult{
+		Deployment:       req.TargetService,
+		Namespace:        namespace,
+		PreviousReplicas: current.Spec.Replicas,
+		Replicas:         replicas,
+	}
+	execution := &Execution{StatusCode: http.StatusOK}
+
+	if replicas != current.Spec.Replicas {
+		patch := mustMarshal(map[string]interface{}{
+			"spec": map[string]int{"replicas": replicas},
+		})
+		status, err := e.do(ctx, http.MethodPatch, endpoint, patch, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scale %s to %d: %w", req.TargetService, replicas, err)
+		}
+		execution.StatusCode = status
+	}
+
+	execution.Response = mustMarshal(result)
+	execution.Body = string(execution.Response)
+	return execution, nil
+}
+
+// Invert restores the replica count seen before the original action ran.
+// Without a recorded response it falls back to the opposite scale action.
+func (e *ScaleExecutor) Invert(ctx context.Context, original *ActionRequest) (*ActionRequest, error) {
+	var result scaleResult
+	if len(original.Response) == 0 || json.Unmarshal(original.Response, &result) != nil || result.Deployment == "" {
+		return invertByType(original)
+	}
+
+	inverseType := models.ActionTypeScaleUp
+	if result.PreviousReplicas < result.Replicas {
+		inverseType = models.ActionTypeScaleDown
+	}
+
+	return &ActionRequest{
+		DecisionID:    original.DecisionID,
+		ActionType:    inverseType,
+		TargetService: original.TargetService,
+		Payload: map[string]interface{}{
+			"replicas":  result.PreviousReplicas,
+			"namespace": result.Namespace,
+		},
+		WebhookURL: original.WebhookURL,
+		RollbackOf: original.ActionID,
+	}, nil
+}
+
+// desiredReplicas computes the replica count an action asks for
+func desiredReplicas(req *ActionRequest, current int) (int, error) {
+	replicas, ok, err := intParam(req.Payload, "replicas")
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		instances, ok, err := intParam(req.Payload, "instances")
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			instances = 1
+		}
+		switch req.ActionType {
+		case models.ActionTypeScaleUp:
+			replicas = current + instances
+		case models.ActionTypeScaleDown:
+			replicas = current - instances
+		default:
+			return 0, fmt.Errorf("scale executor cannot run %s actions", req.ActionType)
+		}
+	}
+
+	if min, ok, err := intParam(req.Payload, "min_instances"); err != nil {
+		return 0, err
+	} else if ok && replicas < min {
+		replicas = min
+	}
+	if max, ok, err := intParam(req.Payload, "max_instances"); err != nil {
+		return 0, err
+	} else if ok && replicas > max {
+		replicas = max
+	}
+	if replicas < 0 {
+		replicas = 0
+	}
+
+	return replicas, nil
+}
+
+// do sends a request to the API and decodes a JSON response into out
+func (e *ScaleExecutor) do(ctx context.Context, method, endpoint string, body []byte, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("%s %s returned %d: %s", method, endpoint, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("invalid response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
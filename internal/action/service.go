@@ -2,24 +2,67 @@ package action
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
 	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
 	"github.com/aegis-decision-engine/ade/internal/webhook"
 )
 
+// FeedbackCollector is notified when an action completes so its impact can be measured
+type FeedbackCollector interface {
+	ActionCompleted(ctx context.Context, req *ActionRequest, result *ActionResult)
+}
+
+// Hooks are called around each action; plugin.Manager implements them.
+// ExecuteBeforeAction may adjust the request or veto it with an error.
+type Hooks interface {
+	ExecuteBeforeAction(ctx context.Context, req *ActionRequest) error
+	ExecuteAfterAction(ctx context.Context, req *ActionRequest, result *ActionResult) error
+}
+
+// ErrActionVetoed is returned for an action a hook refused
+var ErrActionVetoed = errors.New("action vetoed")
+
 // Service handles action execution
 type Service struct {
-	webhookClient *webhook.Client
-	webhookURL    string
-	logger        *slog.Logger
-	dryRun        bool
+	actionStore       *postgres.ActionStore
+	webhookClient     *webhook.Client
+	webhookURL        string
+	feedbackCollector FeedbackCollector
+	hooks             Hooks
+	approvalNotifier  ApprovalNotifier
+	approvalTimeout   time.Duration
+	approvalDefault   ApprovalOutcome
+	logger            *slog.Logger
+	dryRun            bool
+
+	mu              sync.RWMutex
+	inverters       map[models.ActionType]Inverter
+	executors       map[models.ActionType]Executor
+	defaultExecutor Executor
+
+	locker         TargetLocker
+	lockWait       time.Duration
+	conflictWindow time.Duration
+	recentMu       sync.Mutex
+	recent         []recentAction
+	batchSlots     chan struct{}
+	typeSlots      map[models.ActionType]chan struct{}
+	guardrails     *guardrail.Guardrails
+
+	rolloutObserver RolloutObserver
+	rolloutWindow   time.Duration
 }
 
 // NewService creates a new action service
-func NewService(webhookURL string, dryRun bool, logger *slog.Logger) *Service {
+func NewService(actionStore *postgres.ActionStore, webhookURL string, dryRun bool, logger *slog.Logger) *Service {
 	if logger == nil {
 		logger = slog.Default()
 	}
@@ -27,12 +70,54 @@ func NewService(webhookURL string, dryRun bool, logger *slog.Logger) *Service {
 	webhookConfig := webhook.DefaultConfig()
 	webhookConfig.EnableCircuitBreaker = true
 	
-	return &Service{
-		webhookClient: webhook.NewClient(webhookConfig, logger),
-		webhookURL:    webhookURL,
-		logger:        logger,
-		dryRun:        dryRun,
+	s := &Service{
+		actionStore:     actionStore,
+		webhookClient:   webhook.NewClient(webhookConfig, logger),
+		webhookURL:      webhookURL,
+		logger:          logger,
+		dryRun:          dryRun,
+		inverters:       make(map[models.ActionType]Inverter),
+		executors:       make(map[models.ActionType]Executor),
+		approvalTimeout: 30 * time.Minute,
+		approvalDefault: ApprovalOutcomeReject,
+		locker:          NewLocalLocker(),
+		lockWait:        30 * time.Second,
+		conflictWindow:  5 * time.Minute,
+		batchSlots:      make(chan struct{}, 10),
+		rolloutWindow:   5 * time.Minute,
 	}
+	s.defaultExecutor = NewWebhookExecutor(s.webhookClient, s.recordRetry)
+	return s
+}
+
+// SetFeedbackCollector sets the collector notified after each completed action
+func (s *Service) SetFeedbackCollector(collector FeedbackCollector) {
+	s.feedbackCollector = collector
+}
+
+// SetHooks sets the hooks called before each action, rollout stage included, is
+// carried out and after it runs
+func (s *Service) SetHooks(hooks Hooks) {
+	s.hooks = hooks
+}
+
+// SetWebhookSecrets sets the secrets webhooks to host are signed with, or the
+// default secrets when host is empty. Pass the new secret first and the old
+// one second while rotating.
+func (s *Service) SetWebhookSecrets(host string, secrets ...string) error {
+	return s.webhookClient.SetSigningSecrets(host, secrets...)
+}
+
+// SetWebhookConfig replaces the webhook client with one built from config.
+// Call it before setting webhook secrets or the delivery log.
+func (s *Service) SetWebhookConfig(config webhook.Config) {
+	s.webhookClient = webhook.NewClient(config, s.logger)
+	s.defaultExecutor = NewWebhookExecutor(s.webhookClient, s.recordRetry)
+}
+
+// WebhookClient returns the client webhook actions are delivered with
+func (s *Service) WebhookClient() *webhook.Client {
+	return s.webhookClient
 }
 
 // ActionRequest represents a request to execute an action
@@ -45,6 +130,10 @@ type ActionRequest struct {
 	DryRun        bool                   `json:"dry_run"`
 	ScheduledAt   *time.Time             `json:"scheduled_at,omitempty"`
 	WebhookURL    string                 `json:"webhook_url,omitempty"`
+	RollbackOf    string                 `json:"rollback_of,omitempty"` // ID of the action this one undoes
+	Cost          float64                `json:"cost,omitempty"`        // counted against the daily cost budget
+	Rollout       *models.Rollout        `json:"rollout,omitempty"`     // applies the action in stages
+	Response      json.RawMessage        `json:"-"`                     // executor response of a completed action, used to invert it
 }
 
 // Validate validates the action request
@@ -61,7 +150,7 @@ func (r *ActionRequest) Validate() error {
 	if r.TargetService == "" {
 		return fmt.Errorf("target_service is required")
 	}
-	return nil
+	return validateRollout(r)
 }
 
 // ActionResult represents the result of executing an action
@@ -83,23 +172,123 @@ func (s *Service) Execute(ctx context.Context, req *ActionRequest) (*ActionResul
 	if err := req.Validate(); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
+	if req.Rollout != nil && s.actionStore == nil {
+		return nil, ErrRolloutUnavailable
+	}
+
+	webhookURL := s.resolveWebhookURL(req)
+	dryRun := req.DryRun || s.dryRun || s.killSwitch()
+
+	// Persist before doing anything so every action leaves an audit record
+	err := s.admitAndStore(ctx, req, dryRun, func() error {
+		if s.actionStore == nil {
+			return nil
+		}
+		record := &models.ActionRecord{
+			ActionID:      req.ActionID,
+			DecisionID:    req.DecisionID,
+			ActionType:    req.ActionType,
+			ActionPayload: mustMarshal(req.Payload),
+			TargetService: req.TargetService,
+			Status:        models.ActionStatusPending,
+			DryRun:        dryRun,
+			WebhookURL:    webhookURL,
+			RollbackOf:    req.RollbackOf,
+			Cost:          req.Cost,
+			Rollout:       newRollout(req.Rollout),
+		}
+		if err := s.actionStore.Store(ctx, record); err != nil {
+			return fmt.Errorf("failed to persist action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.run(ctx, req, webhookURL, dryRun)
+}
+
+// run executes a persisted pending action
+func (s *Service) run(ctx context.Context, req *ActionRequest, webhookURL string, dryRun bool) (*ActionResult, error) {
+	s.transition(ctx, req.ActionID, models.ActionStatusPending, models.ActionStatusExecuting, "")
+	return s.perform(ctx, req, webhookURL, dryRun)
+}
+
+// beforeAction runs the before hooks, which may change req
+func (s *Service) beforeAction(ctx context.Context, req *ActionRequest) error {
+	if s.hooks == nil {
+		return nil
+	}
+	if err := s.hooks.ExecuteBeforeAction(ctx, req); err != nil {
+		s.logger.Warn("action vetoed",
+			"action_id", req.ActionID,
+			"type", req.ActionType,
+			"target", req.TargetService,
+			"error", err,
+		)
+		return fmt.Errorf("%w: %v", ErrActionVetoed, err)
+	}
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("validation failed after hooks: %w", err)
+	}
+	return nil
+}
 
+// afterAction hands the outcome of an action to the after hooks
+func (s *Service) afterAction(ctx context.Context, req *ActionRequest, result *ActionResult) {
+	if s.hooks == nil || result == nil {
+		return
+	}
+	if err := s.hooks.ExecuteAfterAction(ctx, req, result); err != nil {
+		s.logger.Warn("action hook failed", "action_id", req.ActionID, "error", err)
+	}
+}
+
+// perform carries out an action whose record is already executing and hands
+// the result to the hooks. Every way of running an action ends here, so the
+// before hooks see immediate, scheduled and approved actions alike, and a
+// vetoed action fails.
+func (s *Service) perform(ctx context.Context, req *ActionRequest, webhookURL string, dryRun bool) (*ActionResult, error) {
+	if err := s.beforeAction(ctx, req); err != nil {
+		return s.fail(ctx, req, &ActionResult{
+			ActionID:   req.ActionID,
+			DryRun:     dryRun,
+			ExecutedAt: time.Now(),
+			WebhookURL: req.WebhookURL,
+		}, err)
+	}
+
+	result, err := s.carryOut(ctx, req, webhookURL, dryRun)
+	s.afterAction(ctx, req, result)
+	return result, err
+}
+
+// carryOut runs an executing action, or records it as a dry run
+func (s *Service) carryOut(ctx context.Context, req *ActionRequest, webhookURL string, dryRun bool) (*ActionResult, error) {
 	result := &ActionResult{
 		ActionID:   req.ActionID,
 		Status:     "executing",
-		DryRun:     req.DryRun || s.dryRun,
+		DryRun:     dryRun,
 		ExecutedAt: time.Now(),
 		WebhookURL: req.WebhookURL,
 	}
 
+	message := "action would have been executed"
+	if !result.DryRun && s.killSwitch() {
+		result.DryRun = true
+		message = "kill switch on, action would have been executed"
+	}
+
 	if result.DryRun {
 		result.Status = "dry_run"
 		result.Metadata = map[string]interface{}{
 			"action_type":    req.ActionType,
 			"target_service": req.TargetService,
 			"payload":        req.Payload,
-			"message":        "action would have been executed",
+			"message":        message,
 		}
+		s.markExecuted(ctx, req.ActionID, mustMarshal(result.Metadata))
 		s.logger.Info("action dry run",
 			"action_id", req.ActionID,
 			"type", req.ActionType,
@@ -108,48 +297,40 @@ func (s *Service) Execute(ctx context.Context, req *ActionRequest) (*ActionResul
 		return result, nil
 	}
 
-	// Build webhook payload
-	webhookPayload := map[string]interface{}{
-		"action_id":      req.ActionID,
-		"decision_id":    req.DecisionID,
-		"action_type":    req.ActionType,
-		"target_service": req.TargetService,
-		"payload":        req.Payload,
-		"timestamp":      time.Now(),
-	}
-
-	// Send webhook
-	webhookURL := req.WebhookURL
-	if webhookURL == "" {
-		webhookURL = s.webhookURL
-	}
-
-	if webhookURL != "" {
-		webhookReq := &webhook.Request{
-			ID:      req.ActionID,
-			URL:     webhookURL,
-			Method:  "POST",
-			Payload: webhookPayload,
-			Headers: map[string]string{
-				"X-Action-Type": string(req.ActionType),
-				"X-Service-ID":  req.TargetService,
-			},
-		}
-		
-		webhookResp, err := s.webhookClient.Send(ctx, webhookReq)
+	// Only one action per target is in flight at a time
+	release, err := s.lockTarget(ctx, req.TargetService)
+	if err != nil {
+		return s.fail(ctx, req, result, err)
+	}
+	defer release()
+
+	giveBack, err := s.reserveGuardrails(req)
+	if err != nil {
+		return s.fail(ctx, req, result, err)
+	}
+
+	if req.Rollout != nil {
+		result, err := s.startRollout(ctx, req, webhookURL, result)
 		if err != nil {
-			result.Status = "failed"
-			result.ErrorMessage = err.Error()
-			s.logger.Error("webhook failed",
-				"action_id", req.ActionID,
-				"error", err,
-			)
-			return result, err
+			giveBack()
 		}
-		
-		result.ResponseCode = webhookResp.StatusCode
-		result.ResponseBody = string(webhookResp.Body)
+		return result, err
+	}
+
+	// Executors see the resolved webhook URL and the replica bounds
+	execReq := *req
+	execReq.WebhookURL = webhookURL
+	execReq.Payload = s.boundPayload(req)
+
+	execution, err := s.executorFor(req.ActionType).Execute(ctx, &execReq)
+	if err != nil {
+		giveBack()
+		return s.fail(ctx, req, result, err)
 	}
+	result.ResponseCode = execution.StatusCode
+	result.ResponseBody = execution.Body
+
+	s.markExecuted(ctx, req.ActionID, execution.Response)
 
 	now := time.Now()
 	result.Status = "completed"
@@ -162,19 +343,130 @@ func (s *Service) Execute(ctx context.Context, req *ActionRequest) (*ActionResul
 		"duration_ms", time.Since(result.ExecutedAt).Milliseconds(),
 	)
 
+	if s.feedbackCollector != nil {
+		s.feedbackCollector.ActionCompleted(ctx, req, result)
+	}
+
 	return result, nil
 }
 
-// Schedule schedules an action for future execution
+// fail marks an executing action as failed
+func (s *Service) fail(ctx context.Context, req *ActionRequest, result *ActionResult, err error) (*ActionResult, error) {
+	result.Status = "failed"
+	result.ErrorMessage = err.Error()
+	s.transition(ctx, req.ActionID, models.ActionStatusExecuting, models.ActionStatusFailed, err.Error())
+	s.logger.Error("action failed",
+		"action_id", req.ActionID,
+		"type", req.ActionType,
+		"error", err,
+	)
+	return result, err
+}
+
+// GetAction retrieves a persisted action by ID
+func (s *Service) GetAction(ctx context.Context, actionID string) (*models.ActionRecord, error) {
+	if s.actionStore == nil {
+		return nil, fmt.Errorf("action store not available")
+	}
+	return s.actionStore.GetByID(ctx, actionID)
+}
+
+// ListActions lists persisted actions with filters
+func (s *Service) ListActions(ctx context.Context, filters models.ActionFilters) ([]*models.ActionRecord, error) {
+	if s.actionStore == nil {
+		return nil, fmt.Errorf("action store not available")
+	}
+	return s.actionStore.ListActions(ctx, filters)
+}
+
+// resolveWebhookURL returns the request's webhook URL or the service default
+func (s *Service) resolveWebhookURL(req *ActionRequest) string {
+	if req.WebhookURL != "" {
+		return req.WebhookURL
+	}
+	return s.webhookURL
+}
+
+// transition records a status change, logging rather than failing the action
+func (s *Service) transition(ctx context.Context, actionID string, from, to models.ActionStatus, errorMsg string) {
+	if s.actionStore == nil {
+		return
+	}
+	if err := s.actionStore.UpdateStatus(ctx, actionID, from, to, errorMsg); err != nil {
+		s.logger.Warn("failed to update action status",
+			"action_id", actionID,
+			"from", from,
+			"to", to,
+			"error", err,
+		)
+	}
+}
+
+// recordRetry counts a retried delivery on the action record
+func (s *Service) recordRetry(ctx context.Context, actionID string) {
+	if s.actionStore == nil {
+		return
+	}
+	if err := s.actionStore.IncrementRetry(ctx, actionID); err != nil {
+		s.logger.Warn("failed to record retry", "action_id", actionID, "error", err)
+	}
+}
+
+func (s *Service) markExecuted(ctx context.Context, actionID string, response json.RawMessage) {
+	if s.actionStore == nil {
+		return
+	}
+	if err := s.actionStore.MarkExecuted(ctx, actionID, response); err != nil {
+		s.logger.Warn("failed to mark action executed", "action_id", actionID, "error", err)
+	}
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// Schedule persists an action to run at req.ScheduledAt. Scheduled actions are
+// picked up by RunScheduled on any replica sharing the action store.
 func (s *Service) Schedule(ctx context.Context, req *ActionRequest) (*ActionResult, error) {
 	if req.ScheduledAt == nil {
 		return nil, fmt.Errorf("scheduled_at is required for scheduling")
 	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if s.actionStore == nil {
+		return nil, fmt.Errorf("action store not available")
+	}
+
+	record := &models.ActionRecord{
+		ActionID:      req.ActionID,
+		DecisionID:    req.DecisionID,
+		ActionType:    req.ActionType,
+		ActionPayload: mustMarshal(req.Payload),
+		TargetService: req.TargetService,
+		Status:        models.ActionStatusScheduled,
+		DryRun:        req.DryRun || s.dryRun,
+		ScheduledAt:   req.ScheduledAt,
+		WebhookURL:    s.resolveWebhookURL(req),
+		RollbackOf:    req.RollbackOf,
+		Cost:          req.Cost,
+		Rollout:       newRollout(req.Rollout),
+	}
+	err := s.admitAndStore(ctx, req, record.DryRun, func() error {
+		if err := s.actionStore.Store(ctx, record); err != nil {
+			return fmt.Errorf("failed to persist action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	result := &ActionResult{
 		ActionID:   req.ActionID,
-		Status:     "scheduled",
-		DryRun:     req.DryRun,
+		Status:     string(models.ActionStatusScheduled),
+		DryRun:     record.DryRun,
 		ExecutedAt: time.Now(),
 		Metadata: map[string]interface{}{
 			"action_type":    req.ActionType,
@@ -193,23 +485,117 @@ func (s *Service) Schedule(ctx context.Context, req *ActionRequest) (*ActionResu
 	return result, nil
 }
 
-// ExecuteBatch executes multiple actions
-func (s *Service) ExecuteBatch(ctx context.Context, requests []*ActionRequest) ([]*ActionResult, error) {
-	results := make([]*ActionResult, 0, len(requests))
+// Cancel cancels an action that has not started executing yet
+func (s *Service) Cancel(ctx context.Context, actionID string) error {
+	if s.actionStore == nil {
+		return fmt.Errorf("action store not available")
+	}
+
+	record, err := s.actionStore.GetByID(ctx, actionID)
+	if err != nil {
+		return err
+	}
+	if err := s.actionStore.UpdateStatus(ctx, actionID, record.Status, models.ActionStatusCancelled, ""); err != nil {
+		return err
+	}
+
+	s.logger.Info("action cancelled", "action_id", actionID, "was", record.Status)
+	return nil
+}
+
+// RunScheduled executes due scheduled actions until ctx is done. Each interval
+// it claims as many actions as it has idle workers, so no more than workers
+// actions run at once on this replica.
+func (s *Service) RunScheduled(ctx context.Context, interval time.Duration, workers int) {
+	if s.actionStore == nil {
+		return
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		idle := workers - len(sem)
+		if idle == 0 {
+			continue
+		}
 
-	for _, req := range requests {
-		result, err := s.Execute(ctx, req)
+		records, err := s.actionStore.GetPendingActions(ctx, idle)
 		if err != nil {
-			s.logger.Error("batch action failed", "action_id", req.ActionID, "error", err)
-			result = &ActionResult{
-				ActionID:     req.ActionID,
-				Status:       "failed",
-				ErrorMessage: err.Error(),
-				ExecutedAt:   time.Now(),
-			}
+			s.logger.Warn("failed to claim scheduled actions", "error", err)
+			continue
+		}
+
+		for _, record := range records {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(record *models.ActionRecord) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				// Claimed actions are already executing, so finish them even during shutdown
+				s.runClaimed(context.WithoutCancel(ctx), record)
+			}(record)
 		}
-		results = append(results, result)
 	}
+}
+
+func (s *Service) runClaimed(ctx context.Context, record *models.ActionRecord) {
+	req, err := RequestFromRecord(record)
+	if err != nil {
+		s.transition(ctx, record.ActionID, models.ActionStatusExecuting, models.ActionStatusFailed, err.Error())
+		s.logger.Error("invalid scheduled action", "action_id", record.ActionID, "error", err)
+		return
+	}
+	req.ScheduledAt = record.ScheduledAt
+
+	if _, err := s.perform(ctx, req, record.WebhookURL, record.DryRun); err != nil {
+		s.logger.Error("scheduled action failed", "action_id", record.ActionID, "error", err)
+	}
+}
+
+// ExecuteBatch executes multiple actions concurrently, within the limits set
+// by SetConcurrencyLimits. Results are in the order of requests.
+func (s *Service) ExecuteBatch(ctx context.Context, requests []*ActionRequest) ([]*ActionResult, error) {
+	results := make([]*ActionResult, len(requests))
+
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func(i int, req *ActionRequest) {
+			defer wg.Done()
+
+			release, err := s.acquireSlots(ctx, req.ActionType)
+			var result *ActionResult
+			if err == nil {
+				result, err = s.Execute(ctx, req)
+				release()
+			}
+			if err != nil {
+				s.logger.Error("batch action failed", "action_id", req.ActionID, "error", err)
+				result = &ActionResult{
+					ActionID:     req.ActionID,
+					Status:       "failed",
+					ErrorMessage: err.Error(),
+					ExecutedAt:   time.Now(),
+				}
+			}
+			results[i] = result
+		}(i, req)
+	}
+	wg.Wait()
 
 	return results, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -78,3 +79,45 @@ func (c *Client) Exists(ctx context.Context, key string) bool {
 func (c *Client) Health(ctx context.Context) error {
 	return c.client.Ping(ctx).Err()
 }
+
+// unlockScript deletes a lock only while it still holds the caller's token
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// extendScript renews a lock only while it still holds the caller's token
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// TryLock takes key for ttl if nobody holds it. It returns the token needed
+// to extend or release the lock, or an empty token when the key is held.
+func (c *Client) TryLock(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	token := fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
+	ok, err := c.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", nil
+	}
+	return token, nil
+}
+
+// ExtendLock renews a lock held with token, reporting false if it was lost
+func (c *Client) ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := extendScript.Run(ctx, c.client, []string{key}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Unlock releases a lock held with token
+func (c *Client) Unlock(ctx context.Context, key, token string) error {
+	return unlockScript.Run(ctx, c.client, []string{key}, token).Err()
+}
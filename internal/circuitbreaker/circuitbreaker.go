@@ -180,3 +180,15 @@ func (cb *CircuitBreaker) Reset() {
 
 // ErrCircuitOpen is returned when circuit is open
 var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Name returns the name the breaker was created with
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// Failures returns the consecutive failures counted while closed
+func (cb *CircuitBreaker) Failures() int {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.failures
+}
@@ -183,3 +183,18 @@ func TestCircuitBreakerStats(t *testing.T) {
 	assert.Equal(t, "test-cb", stats["name"])
 	assert.Equal(t, "closed", stats["state"])
 }
+
+func TestRegistryKeepsBreakersApart(t *testing.T) {
+	registry := NewRegistry(Config{MaxFailures: 1, ResetTimeout: time.Minute, HalfOpenMaxCalls: 1})
+
+	a := registry.Get("a.example.com")
+	assert.Same(t, a, registry.Get("a.example.com"))
+
+	a.Execute(context.Background(), func() error { return errors.New("down") })
+	assert.Equal(t, StateOpen, a.State())
+	assert.Equal(t, StateClosed, registry.Get("b.example.com").State())
+
+	all := registry.All()
+	assert.Len(t, all, 2)
+	assert.Equal(t, "a.example.com", all[0].Name())
+}
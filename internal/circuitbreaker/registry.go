@@ -0,0 +1,56 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry holds one circuit breaker per name, created on first use
+type Registry struct {
+	config Config
+
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewRegistry creates a registry whose breakers share config
+func NewRegistry(config Config) *Registry {
+	return &Registry{
+		config:   config,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// Get returns the breaker for name, creating it if needed
+func (r *Registry) Get(name string) *CircuitBreaker {
+	r.mu.RLock()
+	cb, ok := r.breakers[name]
+	r.mu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb, ok := r.breakers[name]; ok {
+		return cb
+	}
+	cb = New(name, r.config)
+	r.breakers[name] = cb
+	return cb
+}
+
+// All returns every breaker created so far, sorted by name
+func (r *Registry) All() []*CircuitBreaker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	sort.Slice(breakers, func(i, j int) bool {
+		return breakers[i].name < breakers[j].name
+	})
+	return breakers
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,9 @@ type Config struct {
 	
 	// Kafka configuration
 	Kafka KafkaConfig
+
+	// Ingest configuration
+	Ingest IngestConfig
 	
 	// Feature configuration
 	Features FeatureConfig
@@ -29,6 +33,21 @@ type Config struct {
 	
 	// Action configuration
 	Action ActionConfig
+
+	// Feedback configuration
+	Feedback FeedbackConfig
+
+	// Notification configuration
+	Notification NotificationConfig
+
+	// Recurring job configuration
+	Scheduler SchedulerConfig
+
+	// Plugin configuration
+	Plugins PluginConfig
+
+	// Policy configuration
+	Policy PolicyConfig
 	
 	// Logging configuration
 	Logging LoggingConfig
@@ -76,6 +95,19 @@ type KafkaConfig struct {
 	ConsumerGroup string
 }
 
+// Ingest modes
+const (
+	IngestModeSync     = "sync"      // HTTP ingest writes events to the database only
+	IngestModeLogFirst = "log-first" // HTTP ingest publishes to Kafka, the consumer writes to the database
+)
+
+// IngestConfig holds event ingestion configuration
+type IngestConfig struct {
+	Mode         string
+	BatchSize    int           // events the consumer writes per database batch
+	BatchTimeout time.Duration // longest the consumer waits to fill a batch
+}
+
 // FeatureConfig holds feature calculation configuration
 type FeatureConfig struct {
 	WindowSize  time.Duration
@@ -84,10 +116,11 @@ type FeatureConfig struct {
 
 // SimulationConfig holds simulation configuration
 type SimulationConfig struct {
-	DefaultIterations int
-	MaxIterations     int
-	DefaultHorizon    time.Duration
-	MaxHorizon        time.Duration
+	DefaultIterations   int
+	MaxIterations       int
+	DefaultHorizon      time.Duration
+	MaxHorizon          time.Duration
+	DependencyGraphPath string // YAML file of service dependencies
 }
 
 // ActionConfig holds action execution configuration
@@ -96,12 +129,116 @@ type ActionConfig struct {
 	MaxRetries            int
 	EnableCircuitBreaker  bool
 	CircuitBreaker        CircuitBreakerConfig
+	Retry                 RetryConfig
+	AutoDispatch          bool // hand non-dry-run decision actions to the action service
+	Approval              ApprovalConfig
+	SchedulerInterval     time.Duration     // how often due scheduled actions are claimed
+	SchedulerWorkers      int               // scheduled actions run at once per replica
+	Executors             map[string]string // action type to executor: webhook, kubernetes, exec or kafka
+	WebhookSecrets        []string          // default signing secrets, new first while rotating
+	EndpointSecrets       map[string]string // webhook host to "new|old" signing secrets
+	Kubernetes            KubernetesConfig
+	Exec                  ExecConfig
+	Guards                GuardConfig
+	GuardrailsPath        string // YAML file of per-service and global blast-radius limits
+	KillSwitch            bool   // run every action as a dry run
+	Rollout               RolloutConfig
+}
+
+// RolloutConfig holds settings for staged actions
+type RolloutConfig struct {
+	Window        time.Duration // observation window after each stage when the rollout sets none
+	CheckInterval time.Duration // how often stages whose window has passed are evaluated
+}
+
+// GuardConfig holds action concurrency and conflict settings
+
+type GuardConfig struct {
+	LockWait         time.Duration     // how long an action waits for a busy target
+	LockTTL          time.Duration     // lease of a distributed target lock, renewed while held
+	DistributedLocks bool              // share target locks across replicas through Redis
+	ConflictWindow   time.Duration     // zero disables conflict detection
+	BatchConcurrency int               // actions of a batch run at once, zero for no limit
+	TypeConcurrency  map[string]string // action type to the actions of that type a batch runs at once
+}
+
+// KubernetesConfig holds settings for the scale executor
+type KubernetesConfig struct {
+	APIURL    string
+	Namespace string
+	TokenFile string // bearer token, e.g. a mounted service account token
+}
+
+// ExecConfig holds settings for the exec executor
+type ExecConfig struct {
+	Commands map[string]string // allow-listed command name to command line
+	Inverses map[string]string // command name to the command that undoes it
+	Timeout  time.Duration
+}
+
+// ApprovalConfig holds settings for actions awaiting approval
+type ApprovalConfig struct {
+	Timeout        time.Duration // used when the policy sets no approval timeout
+	DefaultOutcome string        // approve or reject once the timeout passes
+	CheckInterval  time.Duration
+}
+
+// SchedulerConfig holds recurring job configuration
+type SchedulerConfig struct {
+	FeatureRefreshInterval time.Duration // zero disables periodic feature recalculation
+	FeatureRefreshJitter   time.Duration
+	FeatureRefreshServices []string
+	PolicyReportCron       string // empty disables the policy impact report
+	WindowsPath            string // YAML file of scheduled actions such as scale-down windows
+}
+
+// PluginConfig holds plugin configuration
+type PluginConfig struct {
+	Path           string        // YAML file of the plugins to load and their config
+	Dir            string        // directory of process plugin executables
+	CallTimeout    time.Duration // per call to a process plugin
+	HealthInterval time.Duration // zero disables process plugin health checks
+	MaxBackoff     time.Duration // longest wait between restarts of a crashed process plugin
+}
+
+// PolicyConfig holds policy evaluation configuration
+type PolicyConfig struct {
+	Dir                string        // policies loaded at startup, every .yaml and .yml file
+	BindingsPath       string        // YAML file binding services to policies
+	CandidatesDir      string        // candidate policy versions evaluated in shadow next to the live ones
+	FactTimeout        time.Duration // per lookup of a provided fact
+	FactCacheTTL       time.Duration // how long a provided fact is reused, zero disables caching
+	BusinessTimezone   string        // time zone of the calendar facts
+	BusinessHoursStart int           // first business hour, Monday to Friday
+	BusinessHoursEnd   int           // hour business ends
+	CalendarsPath      string        // YAML file of named calendars, such as maintenance and freeze windows
+}
+
+// NotificationConfig holds notification configuration
+type NotificationConfig struct {
+	SlackWebhookURL    string
+	SlackSigningSecret string // verifies approval button clicks from Slack
+}
+
+// FeedbackConfig holds post-action feedback configuration
+type FeedbackConfig struct {
+	AutoCollect       bool
+	ObservationWindow time.Duration
 }
 
 // CircuitBreakerConfig holds circuit breaker configuration
 type CircuitBreakerConfig struct {
 	MaxFailures  int
 	ResetTimeout time.Duration
+	Scope        string // host or endpoint: which webhooks share a breaker
+}
+
+// RetryConfig holds webhook retry settings
+type RetryConfig struct {
+	StatusCodes   []int    // response codes that are retried
+	NetworkErrors bool     // retry when no response was received
+	Methods       []string // methods that may be retried
+	MaxRetryAfter time.Duration
 }
 
 // LoggingConfig holds logging configuration
@@ -157,6 +294,12 @@ func Load() (*Config, error) {
 			ActionsTopic:  getEnv("KAFKA_ACTIONS_TOPIC", "ade.actions"),
 			ConsumerGroup: getEnv("KAFKA_CONSUMER_GROUP", "ade-consumer"),
 		},
+
+		Ingest: IngestConfig{
+			Mode:         getEnv("INGEST_MODE", IngestModeSync),
+			BatchSize:    parseInt("INGEST_BATCH_SIZE", 100),
+			BatchTimeout: parseDuration("INGEST_BATCH_TIMEOUT", time.Second),
+		},
 		
 		Features: FeatureConfig{
 			WindowSize:  parseDuration("FEATURE_WINDOW_SIZE", 5*time.Minute),
@@ -164,10 +307,11 @@ func Load() (*Config, error) {
 		},
 		
 		Simulation: SimulationConfig{
-			DefaultIterations: parseInt("SIMULATION_DEFAULT_ITERATIONS", 1000),
-			MaxIterations:     parseInt("SIMULATION_MAX_ITERATIONS", 10000),
-			DefaultHorizon:    parseDuration("SIMULATION_DEFAULT_HORIZON", 10*time.Minute),
-			MaxHorizon:        parseDuration("SIMULATION_MAX_HORIZON", 15*time.Minute),
+			DefaultIterations:   parseInt("SIMULATION_DEFAULT_ITERATIONS", 1000),
+			MaxIterations:       parseInt("SIMULATION_MAX_ITERATIONS", 10000),
+			DefaultHorizon:      parseDuration("SIMULATION_DEFAULT_HORIZON", 10*time.Minute),
+			MaxHorizon:          parseDuration("SIMULATION_MAX_HORIZON", 15*time.Minute),
+			DependencyGraphPath: getEnv("SIMULATION_DEPENDENCY_GRAPH", ""),
 		},
 		
 		Action: ActionConfig{
@@ -177,7 +321,87 @@ func Load() (*Config, error) {
 			CircuitBreaker: CircuitBreakerConfig{
 				MaxFailures:  parseInt("CB_MAX_FAILURES", 5),
 				ResetTimeout: parseDuration("CB_RESET_TIMEOUT", 30*time.Second),
+				Scope:        getEnv("CB_SCOPE", "host"),
+			},
+			Retry: RetryConfig{
+				StatusCodes:   parseIntSlice("ACTION_RETRY_STATUS_CODES", []int{408, 429, 500, 502, 503, 504}),
+				NetworkErrors: parseBool("ACTION_RETRY_NETWORK_ERRORS", true),
+				Methods:       parseStringSlice("ACTION_RETRY_METHODS", []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE", "POST"}),
+				MaxRetryAfter: parseDuration("ACTION_MAX_RETRY_AFTER", 2*time.Minute),
+			},
+			AutoDispatch: parseBool("ACTION_AUTO_DISPATCH", false),
+			Approval: ApprovalConfig{
+				Timeout:        parseDuration("ACTION_APPROVAL_TIMEOUT", 30*time.Minute),
+				DefaultOutcome: getEnv("ACTION_APPROVAL_DEFAULT_OUTCOME", "reject"),
+				CheckInterval:  parseDuration("ACTION_APPROVAL_CHECK_INTERVAL", time.Minute),
+			},
+			SchedulerInterval: parseDuration("ACTION_SCHEDULER_INTERVAL", 5*time.Second),
+			SchedulerWorkers:  parseInt("ACTION_SCHEDULER_WORKERS", 4),
+			Executors:         parseStringMap("ACTION_EXECUTORS"),
+			WebhookSecrets:    parseStringSlice("ACTION_WEBHOOK_SECRETS", nil),
+			EndpointSecrets:   parseStringMap("ACTION_WEBHOOK_ENDPOINT_SECRETS"),
+			Kubernetes: KubernetesConfig{
+				APIURL:    getEnv("ACTION_K8S_API_URL", "https://kubernetes.default.svc"),
+				Namespace: getEnv("ACTION_K8S_NAMESPACE", "default"),
+				TokenFile: getEnv("ACTION_K8S_TOKEN_FILE", "/var/run/secrets/kubernetes.io/serviceaccount/token"),
+			},
+			Exec: ExecConfig{
+				Commands: parseStringMap("ACTION_EXEC_COMMANDS"),
+				Inverses: parseStringMap("ACTION_EXEC_INVERSES"),
+				Timeout:  parseDuration("ACTION_EXEC_TIMEOUT", time.Minute),
+			},
+			Guards: GuardConfig{
+				LockWait:         parseDuration("ACTION_LOCK_WAIT", 30*time.Second),
+				LockTTL:          parseDuration("ACTION_LOCK_TTL", 2*time.Minute),
+				DistributedLocks: parseBool("ACTION_DISTRIBUTED_LOCKS", false),
+				ConflictWindow:   parseDuration("ACTION_CONFLICT_WINDOW", 5*time.Minute),
+				BatchConcurrency: parseInt("ACTION_BATCH_CONCURRENCY", 10),
+				TypeConcurrency:  parseStringMap("ACTION_TYPE_CONCURRENCY"),
 			},
+			GuardrailsPath: getEnv("GUARDRAILS_FILE", ""),
+			KillSwitch:     parseBool("ACTION_KILL_SWITCH", false),
+			Rollout: RolloutConfig{
+				Window:        parseDuration("ACTION_ROLLOUT_WINDOW", 5*time.Minute),
+				CheckInterval: parseDuration("ACTION_ROLLOUT_CHECK_INTERVAL", 15*time.Second),
+			},
+		},
+
+		Feedback: FeedbackConfig{
+			AutoCollect:       parseBool("FEEDBACK_AUTO_COLLECT", true),
+			ObservationWindow: parseDuration("FEEDBACK_OBSERVATION_WINDOW", 5*time.Minute),
+		},
+
+		Notification: NotificationConfig{
+			SlackWebhookURL:    getEnv("SLACK_WEBHOOK_URL", ""),
+			SlackSigningSecret: getEnv("SLACK_SIGNING_SECRET", ""),
+		},
+
+		Scheduler: SchedulerConfig{
+			FeatureRefreshInterval: parseDuration("SCHEDULER_FEATURE_REFRESH_INTERVAL", time.Minute),
+			FeatureRefreshJitter:   parseDuration("SCHEDULER_FEATURE_REFRESH_JITTER", 10*time.Second),
+			FeatureRefreshServices: parseStringSlice("SCHEDULER_FEATURE_REFRESH_SERVICES", nil),
+			PolicyReportCron:       getEnv("SCHEDULER_POLICY_REPORT_CRON", "0 2 * * *"),
+			WindowsPath:            getEnv("SCHEDULER_WINDOWS_FILE", ""),
+		},
+
+		Plugins: PluginConfig{
+			Path:           getEnv("PLUGINS_FILE", ""),
+			Dir:            getEnv("PLUGINS_DIR", ""),
+			CallTimeout:    parseDuration("PLUGIN_CALL_TIMEOUT", 2*time.Second),
+			HealthInterval: parseDuration("PLUGIN_HEALTH_INTERVAL", 10*time.Second),
+			MaxBackoff:     parseDuration("PLUGIN_MAX_BACKOFF", time.Minute),
+		},
+
+		Policy: PolicyConfig{
+			Dir:                getEnv("POLICY_DIR", "policies"),
+			BindingsPath:       getEnv("POLICY_BINDINGS_FILE", ""),
+			CandidatesDir:      getEnv("POLICY_CANDIDATES_DIR", ""),
+			FactTimeout:        parseDuration("POLICY_FACT_TIMEOUT", 500*time.Millisecond),
+			FactCacheTTL:       parseDuration("POLICY_FACT_CACHE_TTL", 30*time.Second),
+			BusinessTimezone:   getEnv("POLICY_BUSINESS_TIMEZONE", "UTC"),
+			BusinessHoursStart: parseInt("POLICY_BUSINESS_HOURS_START", 9),
+			BusinessHoursEnd:   parseInt("POLICY_BUSINESS_HOURS_END", 17),
+			CalendarsPath:      getEnv("POLICY_CALENDARS_FILE", ""),
 		},
 		
 		Logging: LoggingConfig{
@@ -197,6 +421,10 @@ func Load() (*Config, error) {
 			Burst:   parseInt("RATE_LIMIT_BURST", 200),
 		},
 	}
+
+	if cfg.Ingest.Mode != IngestModeSync && cfg.Ingest.Mode != IngestModeLogFirst {
+		return nil, fmt.Errorf("invalid INGEST_MODE %q: must be %s or %s", cfg.Ingest.Mode, IngestModeSync, IngestModeLogFirst)
+	}
 	
 	return cfg, nil
 }
@@ -237,12 +465,42 @@ func parseDuration(key string, defaultValue time.Duration) time.Duration {
 
 func parseStringSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
-		// Simple parsing - in production use proper CSV parsing
-		return []string{value}
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	return defaultValue
+}
+
+func parseIntSlice(key string, defaultValue []int) []int {
+	if value := os.Getenv(key); value != "" {
+		var values []int
+		for _, v := range strings.Split(value, ",") {
+			if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				values = append(values, i)
+			}
+		}
+		return values
 	}
 	return defaultValue
 }
 
+// parseStringMap parses comma separated key=value pairs
+func parseStringMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range parseStringSlice(key, nil) {
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			values[k] = strings.TrimSpace(v)
+		}
+	}
+	return values
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	if c.Server.Port == "" {
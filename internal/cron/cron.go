@@ -0,0 +1,231 @@
+// Package cron parses standard five-field cron expressions
+// (minute hour day-of-month month day-of-week) and computes their next
+// activation time.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// A restricted day-of-month and day-of-week match if either matches,
+	// as in Vixie cron. When one of them is "*" only the other applies.
+	domStar bool
+	dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as Sunday and folded onto 0
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearch bounds Next for expressions that can never fire, such as 30 February
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Parse parses a five-field cron expression or one of the @hourly, @daily,
+// @weekly, @monthly and @yearly macros
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{expr: expr}
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return s, nil
+}
+
+// MustParse is like Parse but panics on an invalid expression
+func MustParse(expr string) *Schedule {
+	s, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first activation strictly after t, in t's location. It
+// returns the zero time if the schedule never fires.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// MatchesHour reports whether the schedule fires in the hour of t, in t's
+// location. The minute field is ignored, so "0 9-17 * * mon-fri" matches from
+// 09:00 to 17:59 on weekdays.
+func (s *Schedule) MatchesHour(t time.Time) bool {
+	return s.month&(1<<uint(t.Month())) != 0 && s.dayMatches(t) && s.hour&(1<<uint(t.Hour())) != 0
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField parses a comma separated list of values, ranges and steps
+func parseField(spec string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(spec, ",") {
+		b, err := parsePart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parsePart(part string, f field) (uint64, error) {
+	rangeSpec, stepSpec, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepSpec)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s step %q", f.name, stepSpec)
+		}
+		step = n
+	}
+
+	var lo, hi int
+	switch {
+	case rangeSpec == "*" || rangeSpec == "?":
+		lo, hi = f.min, f.max
+	case strings.Contains(rangeSpec, "-"):
+		a, b, _ := strings.Cut(rangeSpec, "-")
+		var err error
+		if lo, err = parseValue(a, f); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(b, f); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid %s range %q", f.name, rangeSpec)
+		}
+	default:
+		v, err := parseValue(rangeSpec, f)
+		if err != nil {
+			return 0, err
+		}
+		// "5/15" means every 15 starting at 5
+		lo, hi = v, v
+		if hasStep {
+			hi = f.max
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s value %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2026, 3, 11, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 11, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 11, 10, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 3, 12, 2, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * mon-fri", time.Date(2026, 3, 11, 10, 30, 0, 0, time.UTC)},
+		{"0 22 * * 6,0", time.Date(2026, 3, 14, 22, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 3, 11, 10, 25, 0, 0, time.UTC)},
+		{"0 12 1 * fri", time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 11, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+		})
+	}
+}
+
+func TestNextIsStrictlyAfter(t *testing.T) {
+	s := MustParse("0 * * * *")
+	on := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, on.Add(time.Hour), s.Next(on))
+}
+
+func TestNextNeverFires(t *testing.T) {
+	s := MustParse("0 0 30 2 *")
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestMatchesHour(t *testing.T) {
+	s := MustParse("0 9-17 * * MON-FRI")
+
+	assert.True(t, s.MatchesHour(time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, s.MatchesHour(time.Date(2026, 3, 11, 17, 59, 0, 0, time.UTC)))
+	assert.False(t, s.MatchesHour(time.Date(2026, 3, 11, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, s.MatchesHour(time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC))) // Saturday
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
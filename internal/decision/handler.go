@@ -1,45 +1,110 @@
 package decision
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/models"
 	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/google/uuid"
 )
 
+// defaultPolicyID is the autoscale policy used for services without a binding
+const defaultPolicyID = "autoscale_policy"
+
+// Experiments assigns services to the arms of policy experiments;
+// experiment.Service implements it. Assign returns nil when the policy has no
+// running experiment.
+type Experiments interface {
+	Assign(ctx context.Context, policyID, serviceID string) *models.ExperimentAssignment
+}
+
 // Handler handles HTTP requests for decisions
 type Handler struct {
-	service  *Service
-	policies map[string]*policy.Policy
+	service     *Service
+	policies    *policy.Registry
+	experiments Experiments
 }
 
-// NewHandler creates a new decision handler
+// NewHandler creates a new decision handler. Candidate policy versions
+// loaded into the handler are evaluated in shadow by the service.
 func NewHandler(service *Service) *Handler {
+	policies := policy.NewRegistry()
+	service.SetCandidates(policies)
 	return &Handler{
 		service:  service,
-		policies: make(map[string]*policy.Policy),
+		policies: policies,
 	}
 }
 
+// Policies returns the registry of the loaded policies
+func (h *Handler) Policies() *policy.Registry {
+	return h.policies
+}
+
+// SetExperiments makes decisions of policies under experiment use the policy
+// of the arm each service is in
+func (h *Handler) SetExperiments(experiments Experiments) {
+	h.experiments = experiments
+}
+
 // LoadDefaultPolicy loads the default autoscale policy
 func (h *Handler) LoadDefaultPolicy() error {
 	pol, err := policy.LoadPolicy("policies/autoscale_v1.yaml")
 	if err != nil {
 		return err
 	}
-	h.policies[pol.ID] = pol
+	return h.policies.Add(pol)
+}
+
+// LoadPolicies loads every policy in a directory
+func (h *Handler) LoadPolicies(dir string) error {
+	policies, err := policy.LoadPolicies(dir)
+	if err != nil {
+		return err
+	}
+	return h.policies.AddAll(policies)
+}
+
+// LoadCandidates loads every policy in a directory as the candidate version
+// of the loaded policy with its ID
+func (h *Handler) LoadCandidates(dir string) error {
+	candidates, err := policy.LoadPolicies(dir)
+	if err != nil {
+		return err
+	}
+	for _, p := range candidates {
+		if err := h.policies.SetCandidate(p); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// LoadBindings loads the bindings that select the policies of each service
+func (h *Handler) LoadBindings(path string) error {
+	bindings, err := policy.LoadBindings(path)
+	if err != nil {
+		return err
+	}
+	return h.policies.SetBindings(bindings)
+}
+
 // RegisterRoutes registers the decision routes
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/decisions", h.handleListDecisions)
 	mux.HandleFunc("/decisions/{id}", h.handleGetDecision)
 	mux.HandleFunc("/decisions/{id}/trace", h.handleGetDecisionTrace)
 	mux.HandleFunc("/evaluate", h.handleEvaluate)
+	mux.HandleFunc("/services/{id}/evaluate", h.handleEvaluateService)
 	mux.HandleFunc("/policies/load", h.handleLoadPolicy)
+	mux.HandleFunc("/policies/{id}/candidate", h.handleCandidate)
+	mux.HandleFunc("/policies/{id}/shadow", h.handleShadowReport)
 }
 
 func (h *Handler) handleEvaluate(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +116,9 @@ func (h *Handler) handleEvaluate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ServiceID      string                 `json:"service_id"`
 		PolicyID       string                 `json:"policy_id"`
+		DecisionType   string                 `json:"decision_type"`
+		Labels         map[string]string      `json:"labels"`
+		Params         map[string]interface{} `json:"params"`
 		Features       *models.ServiceFeatures `json:"features"`
 		DryRun         bool                   `json:"dry_run"`
 		IdempotencyKey string                 `json:"idempotency_key"`
@@ -66,31 +134,61 @@ func (h *Handler) handleEvaluate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.PolicyID == "" {
-		req.PolicyID = "autoscale_policy"
+	if req.Features == nil {
+		writeError(w, http.StatusBadRequest, "features is required")
+		return
+	}
+
+	if req.DecisionType == "" {
+		req.DecisionType = string(models.DecisionTypeAutoScale)
 	}
 
 	if req.IdempotencyKey == "" {
-		req.IdempotencyKey = "idemp-" + time.Now().Format("20060102150405")
+		req.IdempotencyKey = "idemp-" + uuid.NewString()
 	}
 
-	// Get policy
-	pol, ok := h.policies[req.PolicyID]
-	if !ok {
-		writeError(w, http.StatusNotFound, "policy not found: "+req.PolicyID)
+	// Get policy: the one asked for, else the one bound to the service
+	bound, err := h.selectPolicy(req.ServiceID, req.Labels, req.PolicyID, req.DecisionType)
+	if err != nil {
+		if errors.Is(err, models.ErrPolicyNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to select policy: "+err.Error())
+		return
+	}
+	bound, assignment, err := h.assignExperiment(r.Context(), req.ServiceID, bound)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to select experiment arm: "+err.Error())
 		return
 	}
+	pol := bound.Policy
+
+	// Request params override those of the binding
+	params := bound.Params
+	for name, v := range req.Params {
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		params[name] = v
+	}
 
 	decisionReq := &models.DecisionRequest{
 		ServiceID:      req.ServiceID,
-		DecisionType:   models.DecisionTypeAutoScale,
+		DecisionType:   models.DecisionType(pol.Type),
 		Features:       req.Features,
+		Params:         params,
+		Experiment:     assignment,
 		DryRun:         req.DryRun,
 		IdempotencyKey: req.IdempotencyKey,
 	}
 
 	resp, err := h.service.MakeDecision(r.Context(), decisionReq, pol)
 	if err != nil {
+		if errors.Is(err, policy.ErrInvalidParams) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "decision failed: "+err.Error())
 		return
 	}
@@ -99,6 +197,146 @@ func (h *Handler) handleEvaluate(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// selectPolicy returns the named policy or, without a name, the policy of the
+// decision type bound to the service with its binding's params. Unbound
+// services get the default autoscale policy.
+func (h *Handler) selectPolicy(serviceID string, labels map[string]string, policyID, decisionType string) (*policy.BoundPolicy, error) {
+	if policyID == "" {
+		bound, err := h.policies.BoundOfType(serviceID, labels, decisionType)
+		if err != nil || bound != nil {
+			return bound, err
+		}
+		if decisionType != string(models.DecisionTypeAutoScale) {
+			return nil, fmt.Errorf("%w: no %s policy bound to %s", models.ErrPolicyNotFound, decisionType, serviceID)
+		}
+		policyID = defaultPolicyID
+	}
+	pol, err := h.policies.Get(policyID)
+	if err != nil {
+		return nil, err
+	}
+	return &policy.BoundPolicy{Policy: pol}, nil
+}
+
+// assignExperiment returns the policy of the experiment arm a service is in
+// when the selected policy is under experiment, with the assignment, and
+// the selected policy otherwise. Binding params carry over to the arm's
+// policy where it declares them.
+func (h *Handler) assignExperiment(ctx context.Context, serviceID string, bound *policy.BoundPolicy) (*policy.BoundPolicy, *models.ExperimentAssignment, error) {
+	if h.experiments == nil {
+		return bound, nil, nil
+	}
+	assignment := h.experiments.Assign(ctx, bound.Policy.ID, serviceID)
+	if assignment == nil || assignment.PolicyID == bound.Policy.ID {
+		return bound, assignment, nil
+	}
+	pol, err := h.policies.Get(assignment.PolicyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &policy.BoundPolicy{Policy: pol, Params: pol.DeclaredParams(bound.Params)}, assignment, nil
+}
+
+// handleEvaluateService evaluates a service against every policy bound to
+// it, one decision per policy type
+func (h *Handler) handleEvaluateService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	serviceID := r.PathValue("id")
+	var req struct {
+		Labels         map[string]string      `json:"labels"`
+		Params         map[string]interface{} `json:"params"`
+		Features       *models.ServiceFeatures `json:"features"`
+		DryRun         bool                   `json:"dry_run"`
+		IdempotencyKey string                 `json:"idempotency_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Features == nil {
+		writeError(w, http.StatusBadRequest, "features is required")
+		return
+	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = "idemp-" + uuid.NewString()
+	}
+
+	policies, err := h.policies.Bound(serviceID, req.Labels)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to select policies: "+err.Error())
+		return
+	}
+	if len(policies) == 0 {
+		pol, err := h.policies.Get(defaultPolicyID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "no policies bound to "+serviceID)
+			return
+		}
+		policies = append(policies, policy.BoundPolicy{Policy: pol})
+	}
+
+	// Policies under experiment are replaced by the policy of the service's arm
+	assignments := make([]*models.ExperimentAssignment, len(policies))
+	for i := range policies {
+		bound, assignment, err := h.assignExperiment(r.Context(), serviceID, &policies[i])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to select experiment arm: "+err.Error())
+			return
+		}
+		policies[i], assignments[i] = *bound, assignment
+	}
+
+	// Each request param goes to the policies that declare it
+	for name := range req.Params {
+		declared := false
+		for _, bound := range policies {
+			_, ok := bound.Policy.Params[name]
+			declared = declared || ok
+		}
+		if !declared {
+			writeError(w, http.StatusBadRequest, "no bound policy declares param "+name)
+			return
+		}
+	}
+
+	decisions := make([]*models.DecisionResponse, 0, len(policies))
+	for i, bound := range policies {
+		pol := bound.Policy
+		params := pol.DeclaredParams(bound.Params)
+		for name, v := range pol.DeclaredParams(req.Params) {
+			params[name] = v
+		}
+		resp, err := h.service.MakeDecision(r.Context(), &models.DecisionRequest{
+			ServiceID:      serviceID,
+			DecisionType:   models.DecisionType(pol.Type),
+			Features:       req.Features,
+			Params:         params,
+			Experiment:     assignments[i],
+			DryRun:         req.DryRun,
+			IdempotencyKey: req.IdempotencyKey + ":" + pol.ID,
+		}, pol)
+		if err != nil {
+			if errors.Is(err, policy.ErrInvalidParams) {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "decision failed for policy "+pol.ID+": "+err.Error())
+			return
+		}
+		decisions = append(decisions, resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"service_id": serviceID,
+		"decisions":  decisions,
+	})
+}
+
 func (h *Handler) handleLoadPolicy(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -124,7 +362,10 @@ func (h *Handler) handleLoadPolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.policies[pol.ID] = pol
+	if err := h.policies.Add(pol); err != nil {
+		writeError(w, http.StatusBadRequest, "failed to load policy: "+err.Error())
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -135,17 +376,160 @@ func (h *Handler) handleLoadPolicy(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleCandidate shows (GET), sets (POST, from a policy file) or removes
+// (DELETE) the candidate version of a policy
+func (h *Handler) handleCandidate(w http.ResponseWriter, r *http.Request) {
+	policyID := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		candidate, err := h.policies.Candidate(policyID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get candidate: "+err.Error())
+			return
+		}
+		if candidate == nil {
+			writeError(w, http.StatusNotFound, "no candidate for policy "+policyID)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(candidate)
+
+	case http.MethodPost:
+		var req struct {
+			Path string `json:"path"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if req.Path == "" {
+			writeError(w, http.StatusBadRequest, "path is required")
+			return
+		}
+
+		candidate, err := policy.LoadPolicy(req.Path)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "failed to load candidate: "+err.Error())
+			return
+		}
+		if candidate.ID != policyID {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("candidate has ID %s, not %s", candidate.ID, policyID))
+			return
+		}
+		if err := h.policies.SetCandidate(candidate); err != nil {
+			if errors.Is(err, models.ErrPolicyNotFound) {
+				writeError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			writeError(w, http.StatusBadRequest, "failed to load candidate: "+err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "shadowing",
+			"policy":  candidate.ID,
+			"version": candidate.Version,
+		})
+
+	case http.MethodDelete:
+		if !h.policies.RemoveCandidate(policyID) {
+			writeError(w, http.StatusNotFound, "no candidate for policy "+policyID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleShadowReport compares the shadow evaluations of a policy's
+// candidates with the live decisions
+func (h *Handler) handleShadowReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := models.DecisionFilters{
+		PolicyID:      r.PathValue("id"),
+		PolicyVersion: q.Get("version"),
+		ServiceID:     q.Get("service_id"),
+		Limit:         1000,
+	}
+	if v := q.Get("from"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filters.From = t
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filters.To = t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			filters.Limit = n
+		}
+	}
+
+	report, err := h.service.ShadowReport(r.Context(), filters)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to build shadow report: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
 func (h *Handler) handleListDecisions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	// TODO: implement with query params
+	q := r.URL.Query()
+	filters := models.DecisionFilters{
+		ServiceID:    q.Get("service_id"),
+		PolicyID:     q.Get("policy_id"),
+		Result:       q.Get("result"),
+		Shadow:       q.Get("shadow") == "true",
+		ExperimentID: q.Get("experiment_id"),
+		Limit:        100,
+	}
+	if v := q.Get("from"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filters.From = t
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filters.To = t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			filters.Limit = n
+		}
+	}
+
+	decisions, err := h.service.ListDecisions(r.Context(), filters)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list decisions: "+err.Error())
+		return
+	}
+	if decisions == nil {
+		decisions = []*models.DecisionRecord{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"decisions": []interface{}{},
-		"count":     0,
+		"decisions": decisions,
+		"count":     len(decisions),
 	})
 }
 
@@ -155,8 +539,19 @@ func (h *Handler) handleGetDecision(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: implement
-	writeError(w, http.StatusNotImplemented, "not implemented")
+	decisionID := r.PathValue("id")
+	decision, err := h.service.GetDecision(r.Context(), decisionID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "decision not found: "+decisionID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get decision: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decision)
 }
 
 func (h *Handler) handleGetDecisionTrace(w http.ResponseWriter, r *http.Request) {
@@ -165,8 +560,19 @@ func (h *Handler) handleGetDecisionTrace(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: implement
-	writeError(w, http.StatusNotImplemented, "not implemented")
+	decisionID := r.PathValue("id")
+	view, err := h.service.GetDecisionTraceView(r.Context(), decisionID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "trace not found for decision: "+decisionID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get trace: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
 }
 
 func writeError(w http.ResponseWriter, status int, message string) {
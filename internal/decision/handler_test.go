@@ -0,0 +1,36 @@
+package decision
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateRequiresFeatures(t *testing.T) {
+	handler := NewHandler(NewService(policy.NewEngine(nil), nil, nil, nil))
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "evaluate", path: "/evaluate", body: `{"service_id": "checkout"}`},
+		{name: "evaluate service", path: "/services/checkout/evaluate", body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "features is required")
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package decision
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/cron"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// ScheduledAction is an action taken on a schedule rather than in response to
+// features, such as scaling a service down outside business hours
+type ScheduledAction struct {
+	Name    string                 `yaml:"name" json:"name"`
+	Cron    string                 `yaml:"cron" json:"cron"`
+	Service string                 `yaml:"service" json:"service"`
+	Action  models.ActionType      `yaml:"action" json:"action"`
+	Params  map[string]interface{} `yaml:"params,omitempty" json:"params,omitempty"`
+	Misfire string                 `yaml:"misfire,omitempty" json:"misfire,omitempty"` // skip, run_once or catch_up
+}
+
+// Validate validates the scheduled action
+func (a *ScheduledAction) Validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("scheduled action name is required")
+	}
+	if a.Service == "" {
+		return fmt.Errorf("scheduled action %s: service is required", a.Name)
+	}
+	if a.Action == "" {
+		return fmt.Errorf("scheduled action %s: action is required", a.Name)
+	}
+	if _, err := cron.Parse(a.Cron); err != nil {
+		return fmt.Errorf("scheduled action %s: %w", a.Name, err)
+	}
+	return nil
+}
+
+// LoadScheduledActions reads scheduled actions from a YAML file with a
+// top-level "windows" list
+func LoadScheduledActions(path string) ([]ScheduledAction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled actions: %w", err)
+	}
+
+	var file struct {
+		Windows []ScheduledAction `yaml:"windows"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduled actions: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range file.Windows {
+		if err := file.Windows[i].Validate(); err != nil {
+			return nil, err
+		}
+		if seen[file.Windows[i].Name] {
+			return nil, fmt.Errorf("duplicate scheduled action: %s", file.Windows[i].Name)
+		}
+		seen[file.Windows[i].Name] = true
+	}
+
+	return file.Windows, nil
+}
+
+// DecideScheduled records a decision for the occurrence of a scheduled action
+// due at the given time and executes its action, so that scheduled changes
+// leave the same audit trail as any other. Every replica runs the schedule,
+// so the decision's idempotency key is the action's name and due time: only
+// the first replica stores it and dispatches the action, and the others
+// return a nil response.
+func (s *Service) DecideScheduled(ctx context.Context, sa ScheduledAction, at time.Time) (*models.DecisionResponse, error) {
+	if s.dispatcher == nil {
+		return nil, fmt.Errorf("action dispatch not enabled")
+	}
+	if s.decisionStore == nil {
+		return nil, fmt.Errorf("decision store not available")
+	}
+
+	if at.IsZero() {
+		at = time.Now().Truncate(time.Minute)
+	}
+	idempotencyKey := fmt.Sprintf("schedule:%s:%s", sa.Name, at.UTC().Format(time.RFC3339))
+	decisionID := fmt.Sprintf("dec-%d", time.Now().UnixNano())
+	traceID := fmt.Sprintf("trace-%d", time.Now().UnixNano())
+	actions := []models.Action{{
+		Type:    sa.Action,
+		Payload: mustMarshal(sa.Params),
+		Target:  sa.Service,
+	}}
+
+	violations := s.checkGuardrails(actions)
+
+	// Scheduled actions are configured up front, so they run without approval
+	pol := &policy.Policy{
+		ID:        "schedule:" + sa.Name,
+		Version:   sa.Cron,
+		Type:      string(models.DecisionTypeCustom),
+		Execution: policy.Execution{Mode: policy.ExecutionModeAuto},
+	}
+
+	confidence := 1.0
+	record := &models.DecisionRecord{
+		DecisionID:      decisionID,
+		IdempotencyKey:  idempotencyKey,
+		ServiceID:       sa.Service,
+		PolicyID:        pol.ID,
+		PolicyVersion:   pol.Version,
+		SnapshotID:      sa.Service + "-scheduled",
+		DecisionType:    models.DecisionTypeCustom,
+		DecisionResult:  models.DecisionResultAllow,
+		Actions:         mustMarshal(actions),
+		ConfidenceScore: &confidence,
+		ExecutedAt:      time.Now(),
+	}
+	if err := s.decisionStore.Store(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store scheduled decision: %w", err)
+	}
+	if record.ID == "" {
+		s.logger.Info("scheduled action already decided", "schedule", sa.Name, "scheduled_for", at)
+		return nil, nil
+	}
+
+	trace := &models.DecisionTrace{
+		TraceID:        traceID,
+		DecisionID:     decisionID,
+		PolicyID:       pol.ID,
+		PolicyVersion:  pol.Version,
+		TraceData:      mustMarshal(sa),
+		RulesEvaluated: mustMarshal([]string{}),
+		RulesMatched:   mustMarshal([]string{}),
+		FeaturesUsed:   mustMarshal(map[string]interface{}{}),
+		Guardrails:     mustMarshal(violations),
+	}
+	if err := s.decisionStore.StoreTrace(ctx, trace); err != nil {
+		s.logger.Warn("failed to store trace", "error", err)
+	}
+
+	actionIDs := s.dispatch(ctx, decisionID, pol, actions)
+	s.logger.Info("scheduled decision made",
+		"decision_id", decisionID,
+		"schedule", sa.Name,
+		"service_id", sa.Service,
+		"action", sa.Action,
+		"action_ids", actionIDs,
+	)
+
+	return &models.DecisionResponse{
+		DecisionID:     decisionID,
+		DecisionResult: models.DecisionResultAllow,
+		Actions:        actions,
+		ActionIDs:      actionIDs,
+		Confidence:     confidence,
+		TraceID:        traceID,
+		Timestamp:      time.Now(),
+	}, nil
+}
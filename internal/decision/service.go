@@ -7,74 +7,132 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
 	"github.com/aegis-decision-engine/ade/internal/models"
 	"github.com/aegis-decision-engine/ade/internal/policy"
 	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
 )
 
+// ActionDispatcher receives the actions of non-dry-run decisions
+type ActionDispatcher interface {
+	Execute(ctx context.Context, req *action.ActionRequest) (*action.ActionResult, error)
+	Submit(ctx context.Context, req *action.ActionRequest, timeout time.Duration) (*action.ActionResult, error)
+}
+
+// Hooks are called around each decision; plugin.Manager implements them.
+// ExecuteBeforeDecision may adjust the features or veto the decision with an
+// error, and ExecuteAfterDecision may change the decision before it is stored
+// and its actions are dispatched.
+type Hooks interface {
+	ExecuteBeforeDecision(ctx context.Context, features *models.ServiceFeatures) error
+	ExecuteAfterDecision(ctx context.Context, decision *models.DecisionResponse) error
+}
+
 // Service handles decision making
 type Service struct {
 	policyEngine  *policy.Engine
 	decisionStore *postgres.DecisionStore
+	feedbackStore *postgres.FeedbackStore
+	dispatcher    ActionDispatcher
+	guardrails    *guardrail.Guardrails
+	hooks         Hooks
+	candidates    Candidates
+	shadowStats   *shadowStats
 	logger        *slog.Logger
 }
 
 // NewService creates a new decision service
-func NewService(policyEngine *policy.Engine, decisionStore *postgres.DecisionStore, logger *slog.Logger) *Service {
+func NewService(policyEngine *policy.Engine, decisionStore *postgres.DecisionStore, feedbackStore *postgres.FeedbackStore, logger *slog.Logger) *Service {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	return &Service{
 		policyEngine:  policyEngine,
 		decisionStore: decisionStore,
+		feedbackStore: feedbackStore,
+		shadowStats:   newShadowStats(),
 		logger:        logger,
 	}
 }
 
+// SetActionDispatcher enables automatic dispatch of decision actions
+func (s *Service) SetActionDispatcher(dispatcher ActionDispatcher) {
+	s.dispatcher = dispatcher
+}
+
+// SetGuardrails makes decisions check their actions against the guardrails,
+// naming any that would block them in the trace
+func (s *Service) SetGuardrails(g *guardrail.Guardrails) {
+	s.guardrails = g
+}
+
+// SetHooks sets the hooks called around each decision
+func (s *Service) SetHooks(hooks Hooks) {
+	s.hooks = hooks
+}
+
+// SetCandidates makes live decisions also evaluate the candidate version of
+// their policy in shadow
+func (s *Service) SetCandidates(candidates Candidates) {
+	s.candidates = candidates
+}
+
 // MakeDecision creates a decision based on features and policy
 func (s *Service) MakeDecision(ctx context.Context, req *models.DecisionRequest, pol *policy.Policy) (*models.DecisionResponse, error) {
 	start := time.Now()
+
+	// Fill the policy's params in from the request overrides and defaults
+	params, err := pol.ResolveParams(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	pol = pol.WithParams(params)
 	
 	decisionID := fmt.Sprintf("dec-%d", time.Now().UnixNano())
 	traceID := fmt.Sprintf("trace-%d", time.Now().UnixNano())
 
-	result, allResults := s.policyEngine.Evaluate(ctx, pol, req.Features)
+	// A vetoed decision denies without evaluating the policy
+	var result *policy.EvaluationResult
+	var allResults []policy.EvaluationResult
+	vetoedBy := s.beforeDecision(ctx, req)
+	if vetoedBy != "" {
+		result = &policy.EvaluationResult{Reason: "vetoed: " + vetoedBy, EvaluatedAt: time.Now()}
+	} else {
+		result, allResults = s.policyEngine.Evaluate(ctx, pol, req.Features)
+	}
 
-	// Build actions
-	actions := []models.Action{}
-	if result.Matched && result.Action != "" {
-		actionPayload, _ := json.Marshal(result.ActionPayload)
-		actions = append(actions, models.Action{
-			Type:    result.Action,
-			Payload: actionPayload,
-			Target:  req.ServiceID,
-			Cost:    getActionCost(pol, result.RuleID),
-			Risk:    getActionRisk(pol, result.RuleID),
-		})
+	actions := buildActions(pol, result, req.ServiceID)
+	decisionResult := resultOf(result)
+	if vetoedBy != "" {
+		decisionResult = models.DecisionResultDeny
 	}
 
-	// Determine result
-	decisionResult := models.DecisionResultAllow
-	if result.Matched {
-		switch result.Action {
-		case models.ActionTypeScaleUp, models.ActionTypeScaleDown:
-			decisionResult = models.DecisionResultAllow
-		case models.ActionTypeThrottle:
-			decisionResult = models.DecisionResultThrottle
-		case models.ActionTypeOpenCircuit:
-			decisionResult = models.DecisionResultDeny
-		}
+	resp := &models.DecisionResponse{
+		DecisionID:     decisionID,
+		DecisionResult: decisionResult,
+		Actions:        actions,
+		Confidence:     result.Confidence,
+		TraceID:        traceID,
+		DryRun:         req.DryRun,
+		VetoedBy:       vetoedBy,
+	}
+	if req.Experiment != nil {
+		resp.ExperimentID = req.Experiment.ExperimentID
+		resp.ExperimentArm = req.Experiment.Arm
+	}
+	s.afterDecision(ctx, resp)
+	if resp.Actions == nil {
+		resp.Actions = []models.Action{}
 	}
 
+	violations := s.checkGuardrails(resp.Actions)
+
 	executionTimeMs := int(time.Since(start).Milliseconds())
 
 	// Store decision record
 	if s.decisionStore != nil {
-		actionsJSON, _ := json.Marshal(actions)
-		confidence := 0.8
-		if result.Confidence > 0 {
-			confidence = result.Confidence
-		}
+		actionsJSON, _ := json.Marshal(resp.Actions)
 
 		decisionRecord := &models.DecisionRecord{
 			DecisionID:      decisionID,
@@ -84,15 +142,23 @@ func (s *Service) MakeDecision(ctx context.Context, req *models.DecisionRequest,
 			PolicyVersion:   pol.Version,
 			SnapshotID:      req.Features.ServiceID + "-snap",
 			DecisionType:    models.DecisionType(pol.Type),
-			DecisionResult:  decisionResult,
+			DecisionResult:  resp.DecisionResult,
 			Actions:         actionsJSON,
-			ConfidenceScore: &confidence,
+			ConfidenceScore: confidenceOf(result),
 			DryRun:          req.DryRun,
 			ExecutedAt:      time.Now(),
 		}
+		if req.Experiment != nil {
+			decisionRecord.ExperimentID = &req.Experiment.ExperimentID
+			decisionRecord.ExperimentArm = &req.Experiment.Arm
+		}
 
 		if err := s.decisionStore.Store(ctx, decisionRecord); err != nil {
 			s.logger.Warn("failed to store decision", "error", err)
+		} else if decisionRecord.ID == "" {
+			// A retry of a stored decision gets that decision back, and
+			// nothing is traced or dispatched again
+			return s.storedDecision(ctx, req.IdempotencyKey)
 		}
 
 		// Store trace
@@ -104,8 +170,9 @@ func (s *Service) MakeDecision(ctx context.Context, req *models.DecisionRequest,
 			TraceData:       mustMarshal(result),
 			RulesEvaluated:  mustMarshal(allResults),
 			RulesMatched:    mustMarshal([]string{result.RuleID}),
-			FeaturesUsed:    mustMarshal(req.Features),
+			FeaturesUsed:    mustMarshal(featuresUsed{ServiceFeatures: req.Features, Facts: result.Facts, Params: params, EvaluatedAt: result.EvaluatedAt}),
 			ExecutionTimeMs: executionTimeMs,
+			Guardrails:      mustMarshal(violations),
 		}
 
 		if err := s.decisionStore.StoreTrace(ctx, trace); err != nil {
@@ -113,24 +180,167 @@ func (s *Service) MakeDecision(ctx context.Context, req *models.DecisionRequest,
 		}
 	}
 
+	// Evaluate the candidate version of the policy, if any, without acting
+	if !req.DryRun && vetoedBy == "" && s.candidates != nil {
+		s.shadow(ctx, req, pol, result, resp)
+	}
+
+	if !req.DryRun && s.dispatcher != nil {
+		resp.ActionIDs = s.dispatch(ctx, decisionID, pol, resp.Actions)
+	}
+
 	s.logger.Info("decision made",
 		"decision_id", decisionID,
 		"service_id", req.ServiceID,
-		"result", decisionResult,
+		"result", resp.DecisionResult,
 		"matched", result.Matched,
 		"action", result.Action,
 		"duration_ms", executionTimeMs,
 	)
 
-	return &models.DecisionResponse{
-		DecisionID:     decisionID,
-		DecisionResult: decisionResult,
-		Actions:        actions,
-		Confidence:     result.Confidence,
-		TraceID:        traceID,
-		DryRun:         req.DryRun,
-		Timestamp:      time.Now(),
-	}, nil
+	resp.Timestamp = time.Now()
+	return resp, nil
+}
+
+// storedDecision returns the decision already stored under an idempotency
+// key. The actions it dispatched are listed by GET /actions?decision_id=.
+func (s *Service) storedDecision(ctx context.Context, idempotencyKey string) (*models.DecisionResponse, error) {
+	record, err := s.decisionStore.GetByIdempotencyKey(ctx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored decision: %w", err)
+	}
+
+	resp := &models.DecisionResponse{
+		DecisionID:     record.DecisionID,
+		DecisionResult: record.DecisionResult,
+		Actions:        []models.Action{},
+		DryRun:         record.DryRun,
+		Timestamp:      record.CreatedAt,
+	}
+	if len(record.Actions) > 0 {
+		if err := json.Unmarshal(record.Actions, &resp.Actions); err != nil {
+			return nil, fmt.Errorf("invalid actions in decision %s: %w", record.DecisionID, err)
+		}
+	}
+	if record.ConfidenceScore != nil {
+		resp.Confidence = *record.ConfidenceScore
+	}
+	if record.ExperimentID != nil && record.ExperimentArm != nil {
+		resp.ExperimentID, resp.ExperimentArm = *record.ExperimentID, *record.ExperimentArm
+	}
+	if trace, err := s.decisionStore.GetTraceByDecisionID(ctx, record.DecisionID); err == nil {
+		resp.TraceID = trace.TraceID
+	}
+
+	s.logger.Info("decision already made",
+		"decision_id", record.DecisionID,
+		"service_id", record.ServiceID,
+		"idempotency_key", idempotencyKey,
+	)
+	return resp, nil
+}
+
+// beforeDecision runs the before hooks, which may change the features, and
+// returns why the decision was vetoed, if it was
+func (s *Service) beforeDecision(ctx context.Context, req *models.DecisionRequest) string {
+	if s.hooks == nil {
+		return ""
+	}
+	if err := s.hooks.ExecuteBeforeDecision(ctx, req.Features); err != nil {
+		s.logger.Warn("decision vetoed", "service_id", req.ServiceID, "error", err)
+		return err.Error()
+	}
+	return ""
+}
+
+// afterDecision runs the after hooks on a decision before it is stored
+func (s *Service) afterDecision(ctx context.Context, resp *models.DecisionResponse) {
+	if s.hooks == nil {
+		return
+	}
+	if err := s.hooks.ExecuteAfterDecision(ctx, resp); err != nil {
+		s.logger.Warn("decision hook failed", "decision_id", resp.DecisionID, "error", err)
+	}
+}
+
+// dispatch hands a decision's actions to the dispatcher according to the
+// policy's execution mode and returns the IDs of the actions it created.
+// Actions over the policy's approval thresholds always wait for approval.
+func (s *Service) dispatch(ctx context.Context, decisionID string, pol *policy.Policy, actions []models.Action) []string {
+	mode := pol.ExecutionMode()
+	if mode == policy.ExecutionModeManual {
+		return nil
+	}
+
+	ids := []string{}
+	for i, a := range actions {
+		if a.BlockedBy != "" {
+			continue
+		}
+		req, err := action.RequestFromDecision(decisionID, i, a, false)
+		if err != nil {
+			s.logger.Warn("failed to build action", "decision_id", decisionID, "error", err)
+			continue
+		}
+
+		var result *action.ActionResult
+		if mode == policy.ExecutionModeApproval || pol.Approval.Requires(a.Risk, a.Cost) {
+			result, err = s.dispatcher.Submit(ctx, req, pol.Approval.TimeoutDuration())
+		} else {
+			result, err = s.dispatcher.Execute(ctx, req)
+		}
+		if err != nil {
+			s.logger.Warn("failed to dispatch action",
+				"decision_id", decisionID,
+				"action_id", req.ActionID,
+				"mode", mode,
+				"error", err,
+			)
+		}
+		// A result means the action was recorded, even if it then failed
+		if result != nil {
+			ids = append(ids, result.ActionID)
+		}
+	}
+
+	return ids
+}
+
+// checkGuardrails marks the actions a guardrail blocks and returns the
+// violations, including a note when the kill switch makes every action a dry run
+func (s *Service) checkGuardrails(actions []models.Action) []*guardrail.Violation {
+	violations := []*guardrail.Violation{}
+	if s.guardrails == nil {
+		return violations
+	}
+
+	for i := range actions {
+		a := &actions[i]
+		var payload map[string]interface{}
+		if len(a.Payload) > 0 {
+			json.Unmarshal(a.Payload, &payload)
+		}
+		if v := s.guardrails.Check(guardrail.NewRequest(a.Target, a.Type, payload, a.Cost)); v != nil {
+			a.BlockedBy = v.Guardrail
+			violations = append(violations, v)
+			s.logger.Warn("action blocked by guardrail",
+				"service_id", a.Target,
+				"action", a.Type,
+				"guardrail", v.Guardrail,
+				"reason", v.Reason,
+			)
+		} else if s.guardrails.KillSwitch() {
+			violations = append(violations, &guardrail.Violation{
+				Guardrail: guardrail.KillSwitch,
+				Scope:     "global",
+				Service:   a.Target,
+				Action:    a.Type,
+				Reason:    "kill switch on, action runs as a dry run",
+			})
+		}
+	}
+
+	return violations
 }
 
 // GetDecision retrieves a decision by ID
@@ -149,6 +359,32 @@ func (s *Service) GetDecisionTrace(ctx context.Context, decisionID string) (*mod
 	return s.decisionStore.GetTraceByDecisionID(ctx, decisionID)
 }
 
+// TraceView is a decision trace together with the feedback its actions received
+type TraceView struct {
+	Trace    *models.DecisionTrace    `json:"trace"`
+	Feedback []*models.FeedbackRecord `json:"feedback"`
+}
+
+// GetDecisionTraceView retrieves the trace for a decision along with its feedback
+func (s *Service) GetDecisionTraceView(ctx context.Context, decisionID string) (*TraceView, error) {
+	trace, err := s.GetDecisionTrace(ctx, decisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &TraceView{Trace: trace, Feedback: []*models.FeedbackRecord{}}
+	if s.feedbackStore != nil {
+		feedback, err := s.feedbackStore.ListByFilters(ctx, models.FeedbackFilters{DecisionID: decisionID})
+		if err != nil {
+			s.logger.Warn("failed to load decision feedback", "error", err, "decision_id", decisionID)
+		} else if feedback != nil {
+			view.Feedback = feedback
+		}
+	}
+
+	return view, nil
+}
+
 // ListDecisions lists decisions with filters
 func (s *Service) ListDecisions(ctx context.Context, filters models.DecisionFilters) ([]*models.DecisionRecord, error) {
 	if s.decisionStore == nil {
@@ -157,6 +393,38 @@ func (s *Service) ListDecisions(ctx context.Context, filters models.DecisionFilt
 	return s.decisionStore.ListByFilters(ctx, filters)
 }
 
+// buildActions returns the actions of an evaluation result against a target
+func buildActions(pol *policy.Policy, result *policy.EvaluationResult, target string) []models.Action {
+	actions := []models.Action{}
+	if result.Matched && result.Action != "" {
+		actionPayload, _ := json.Marshal(result.ActionPayload)
+		actions = append(actions, models.Action{
+			Type:    result.Action,
+			Payload: actionPayload,
+			Target:  target,
+			Cost:    getActionCost(pol, result.RuleID),
+			Risk:    getActionRisk(pol, result.RuleID),
+		})
+	}
+	return actions
+}
+
+// resultOf returns the decision result of an evaluation result
+func resultOf(result *policy.EvaluationResult) models.DecisionResult {
+	decisionResult := models.DecisionResultAllow
+	if result.Matched {
+		switch result.Action {
+		case models.ActionTypeScaleUp, models.ActionTypeScaleDown:
+			decisionResult = models.DecisionResultAllow
+		case models.ActionTypeThrottle:
+			decisionResult = models.DecisionResultThrottle
+		case models.ActionTypeOpenCircuit:
+			decisionResult = models.DecisionResultDeny
+		}
+	}
+	return decisionResult
+}
+
 func getActionCost(pol *policy.Policy, ruleID string) float64 {
 	for _, r := range pol.Rules {
 		if r.ID == ruleID {
@@ -175,6 +443,38 @@ func getActionRisk(pol *policy.Policy, ruleID string) float64 {
 	return 0
 }
 
+// featuresUsed is what a trace records as the inputs of a decision: the
+// features, and the time, provided facts and params the policy was evaluated
+// with
+type featuresUsed struct {
+	*models.ServiceFeatures
+	Facts       map[string]interface{} `json:"facts,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+	EvaluatedAt time.Time              `json:"evaluated_at"`
+}
+
+// TraceInputs are the inputs a trace recorded, for evaluating the decision
+// again with policy.Engine.EvaluateRecorded
+type TraceInputs struct {
+	Features    *models.ServiceFeatures
+	Facts       map[string]interface{}
+	Params      map[string]interface{} // resolved policy params
+	EvaluatedAt time.Time              // zero for traces recorded before evaluation times were
+}
+
+// RecordedInputs returns the features, evaluation time, provided facts and
+// params a trace recorded
+func RecordedInputs(trace *models.DecisionTrace) (*TraceInputs, error) {
+	var used featuresUsed
+	if err := json.Unmarshal(trace.FeaturesUsed, &used); err != nil {
+		return nil, fmt.Errorf("invalid features_used: %w", err)
+	}
+	if used.Facts == nil {
+		used.Facts = map[string]interface{}{}
+	}
+	return &TraceInputs{Features: used.ServiceFeatures, Facts: used.Facts, Params: used.Params, EvaluatedAt: used.EvaluatedAt}, nil
+}
+
 func mustMarshal(v interface{}) json.RawMessage {
 	b, _ := json.Marshal(v)
 	return b
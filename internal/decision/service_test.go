@@ -0,0 +1,232 @@
+package decision
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/guardrail"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDispatcher struct {
+	executed  []*action.ActionRequest
+	submitted []*action.ActionRequest
+}
+
+func (f *fakeDispatcher) Execute(ctx context.Context, req *action.ActionRequest) (*action.ActionResult, error) {
+	f.executed = append(f.executed, req)
+	return &action.ActionResult{ActionID: req.ActionID, Status: "completed"}, nil
+}
+
+func (f *fakeDispatcher) Submit(ctx context.Context, req *action.ActionRequest, timeout time.Duration) (*action.ActionResult, error) {
+	f.submitted = append(f.submitted, req)
+	return &action.ActionResult{ActionID: req.ActionID, Status: "pending"}, nil
+}
+
+func testPolicy(mode string) *policy.Policy {
+	return &policy.Policy{
+		ID:        "test_policy",
+		Version:   "1.0",
+		Type:      "autoscale",
+		Execution: policy.Execution{Mode: mode},
+		Rules: []policy.Rule{
+			{
+				ID:       "high_cpu",
+				Name:     "High CPU",
+				Priority: 100,
+				When:     policy.Condition{Fact: "CPUCurrent", Op: ">=", Value: 80.0},
+				Action: policy.Action{
+					Type:   "scale_up",
+					Params: map[string]interface{}{"instances": 3, "urgency": "emergency"},
+					Cost:   50,
+					Risk:   0.2,
+				},
+			},
+		},
+	}
+}
+
+func testRequest(dryRun bool) *models.DecisionRequest {
+	return &models.DecisionRequest{
+		ServiceID: "checkout",
+		Features:  &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 95},
+		DryRun:    dryRun,
+	}
+}
+
+func TestMakeDecisionDispatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		dryRun        bool
+		wantExecuted  int
+		wantSubmitted int
+		approval      policy.Approval
+	}{
+		{name: "auto", mode: policy.ExecutionModeAuto, wantExecuted: 1},
+		{name: "approval", mode: policy.ExecutionModeApproval, wantSubmitted: 1},
+		{name: "manual", mode: policy.ExecutionModeManual},
+		{name: "default is manual", mode: ""},
+		{name: "dry run never dispatches", mode: policy.ExecutionModeAuto, dryRun: true},
+		{name: "auto over risk threshold", mode: policy.ExecutionModeAuto, approval: policy.Approval{MaxRisk: 0.1}, wantSubmitted: 1},
+		{name: "auto over cost threshold", mode: policy.ExecutionModeAuto, approval: policy.Approval{MaxCost: 40}, wantSubmitted: 1},
+		{name: "auto under thresholds", mode: policy.ExecutionModeAuto, approval: policy.Approval{MaxRisk: 0.5, MaxCost: 100}, wantExecuted: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := &fakeDispatcher{}
+			svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+			svc.SetActionDispatcher(dispatcher)
+
+			pol := testPolicy(tt.mode)
+			pol.Approval = tt.approval
+
+			resp, err := svc.MakeDecision(context.Background(), testRequest(tt.dryRun), pol)
+			require.NoError(t, err)
+			require.Len(t, resp.Actions, 1)
+
+			assert.Len(t, dispatcher.executed, tt.wantExecuted)
+			assert.Len(t, dispatcher.submitted, tt.wantSubmitted)
+			assert.Len(t, resp.ActionIDs, tt.wantExecuted+tt.wantSubmitted)
+
+			for _, req := range append(dispatcher.executed, dispatcher.submitted...) {
+				assert.Equal(t, resp.DecisionID, req.DecisionID)
+				assert.Equal(t, "checkout", req.TargetService)
+				assert.Equal(t, models.ActionTypeScaleUp, req.ActionType)
+				assert.Equal(t, float64(3), req.Payload["instances"])
+				assert.Equal(t, "emergency", req.Payload["urgency"])
+				assert.Contains(t, resp.ActionIDs, req.ActionID)
+			}
+		})
+	}
+}
+
+func TestMakeDecisionGuardrails(t *testing.T) {
+	dispatcher := &fakeDispatcher{}
+	svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+	svc.SetActionDispatcher(dispatcher)
+	svc.SetGuardrails(guardrail.New(guardrail.Config{
+		Services: map[string]guardrail.Limits{"checkout": {MaxInstancesPerHour: 2}},
+	}))
+
+	resp, err := svc.MakeDecision(context.Background(), testRequest(false), testPolicy(policy.ExecutionModeAuto))
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 1)
+
+	assert.Equal(t, guardrail.MaxInstancesPerHour, resp.Actions[0].BlockedBy)
+	assert.Empty(t, dispatcher.executed)
+	assert.Empty(t, resp.ActionIDs)
+
+	violations := svc.checkGuardrails([]models.Action{{Type: models.ActionTypeScaleUp, Target: "checkout", Payload: []byte(`{"instances":3}`)}})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "checkout", violations[0].Scope)
+}
+
+type fakeHooks struct {
+	before func(features *models.ServiceFeatures) error
+	after  func(decision *models.DecisionResponse)
+}
+
+func (f *fakeHooks) ExecuteBeforeDecision(ctx context.Context, features *models.ServiceFeatures) error {
+	if f.before == nil {
+		return nil
+	}
+	return f.before(features)
+}
+
+func (f *fakeHooks) ExecuteAfterDecision(ctx context.Context, decision *models.DecisionResponse) error {
+	if f.after != nil {
+		f.after(decision)
+	}
+	return nil
+}
+
+func TestMakeDecisionHooks(t *testing.T) {
+	pol := testPolicy(policy.ExecutionModeAuto)
+
+	t.Run("veto denies", func(t *testing.T) {
+		dispatcher := &fakeDispatcher{}
+		svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+		svc.SetActionDispatcher(dispatcher)
+		svc.SetHooks(&fakeHooks{before: func(*models.ServiceFeatures) error {
+			return errors.New("plugin change-freeze: freeze in effect")
+		}})
+
+		resp, err := svc.MakeDecision(context.Background(), testRequest(false), pol)
+		require.NoError(t, err)
+		assert.Equal(t, models.DecisionResultDeny, resp.DecisionResult)
+		assert.Equal(t, "plugin change-freeze: freeze in effect", resp.VetoedBy)
+		assert.Empty(t, resp.Actions)
+		assert.Empty(t, dispatcher.executed)
+	})
+
+	t.Run("before adjusts features", func(t *testing.T) {
+		svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+		svc.SetHooks(&fakeHooks{before: func(f *models.ServiceFeatures) error {
+			f.CPUCurrent = 40
+			return nil
+		}})
+
+		resp, err := svc.MakeDecision(context.Background(), testRequest(true), pol)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Actions)
+	})
+
+	t.Run("after changes the decision before dispatch", func(t *testing.T) {
+		dispatcher := &fakeDispatcher{}
+		svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+		svc.SetActionDispatcher(dispatcher)
+		svc.SetHooks(&fakeHooks{after: func(d *models.DecisionResponse) {
+			require.Len(t, d.Actions, 1)
+			d.Actions[0].Payload = []byte(`{"instances":1}`)
+		}})
+
+		resp, err := svc.MakeDecision(context.Background(), testRequest(false), pol)
+		require.NoError(t, err)
+		require.Len(t, dispatcher.executed, 1)
+		assert.Equal(t, float64(1), dispatcher.executed[0].Payload["instances"])
+		assert.Len(t, resp.ActionIDs, 1)
+	})
+}
+
+func TestRecordedInputs(t *testing.T) {
+	features := &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 90}
+	at := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+	trace := &models.DecisionTrace{FeaturesUsed: mustMarshal(featuresUsed{
+		ServiceFeatures: features,
+		Facts:           map[string]interface{}{"deploy.in_progress": true},
+		EvaluatedAt:     at,
+	})}
+
+	inputs, err := RecordedInputs(trace)
+	require.NoError(t, err)
+	assert.Equal(t, "checkout", inputs.Features.ServiceID)
+	assert.Equal(t, 90.0, inputs.Features.CPUCurrent)
+	assert.Equal(t, map[string]interface{}{"deploy.in_progress": true}, inputs.Facts)
+	assert.True(t, at.Equal(inputs.EvaluatedAt))
+
+	// Traces recorded before facts existed replay without provided facts
+	trace.FeaturesUsed = mustMarshal(features)
+	inputs, err = RecordedInputs(trace)
+	require.NoError(t, err)
+	assert.Empty(t, inputs.Facts)
+	assert.True(t, inputs.EvaluatedAt.IsZero())
+}
+
+func TestMakeDecisionExperiment(t *testing.T) {
+	svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+	req := testRequest(true)
+	req.Experiment = &models.ExperimentAssignment{ExperimentID: "autoscale-v2", ServiceID: "checkout", Arm: "v2"}
+
+	resp, err := svc.MakeDecision(context.Background(), req, testPolicy(policy.ExecutionModeManual))
+	require.NoError(t, err)
+	assert.Equal(t, "autoscale-v2", resp.ExperimentID)
+	assert.Equal(t, "v2", resp.ExperimentArm)
+}
@@ -0,0 +1,294 @@
+package decision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Candidates supplies the candidate version of a policy; policy.Registry
+// implements it. Candidate returns nil when the policy has none.
+type Candidates interface {
+	Candidate(policyID string) (*policy.Policy, error)
+}
+
+// noRule labels evaluations where no rule matched
+const noRule = "none"
+
+// shadow evaluates the candidate version of a live decision's policy with the
+// same features and params, stores the result next to the decision as a
+// shadow record and counts whether the two agree. Nothing the candidate
+// decides is acted on.
+func (s *Service) shadow(ctx context.Context, req *models.DecisionRequest, live *policy.Policy, liveResult *policy.EvaluationResult, resp *models.DecisionResponse) {
+	candidate, err := s.candidates.Candidate(live.ID)
+	if err != nil {
+		s.logger.Warn("failed to get candidate policy", "policy_id", live.ID, "error", err)
+		return
+	}
+	if candidate == nil {
+		return
+	}
+
+	start := time.Now()
+	params, err := candidate.ResolveParams(candidate.DeclaredParams(req.Params))
+	if err != nil {
+		s.logger.Warn("candidate policy rejected params", "policy_id", candidate.ID, "version", candidate.Version, "error", err)
+		return
+	}
+	candidate = candidate.WithParams(params)
+	result, allResults := s.policyEngine.Evaluate(ctx, candidate, req.Features)
+	actions := buildActions(candidate, result, req.ServiceID)
+	decisionResult := resultOf(result)
+
+	agree := agrees(resp.DecisionResult, resp.Actions, decisionResult, actions)
+	s.shadowStats.record(live.ID, candidate.Version, ruleLabel(liveResult.RuleID), agree)
+
+	if s.decisionStore != nil {
+		shadowID := fmt.Sprintf("shadow-%d", time.Now().UnixNano())
+		actionsJSON, _ := json.Marshal(actions)
+		record := &models.DecisionRecord{
+			DecisionID:      shadowID,
+			IdempotencyKey:  req.IdempotencyKey + ":shadow",
+			ServiceID:       req.ServiceID,
+			PolicyID:        candidate.ID,
+			PolicyVersion:   candidate.Version,
+			SnapshotID:      req.Features.ServiceID + "-snap",
+			DecisionType:    models.DecisionType(candidate.Type),
+			DecisionResult:  decisionResult,
+			Actions:         actionsJSON,
+			ConfidenceScore: confidenceOf(result),
+			DryRun:          true,
+			Shadow:          true,
+			ShadowOf:        &resp.DecisionID,
+			ExecutedAt:      time.Now(),
+		}
+		if err := s.decisionStore.Store(ctx, record); err != nil {
+			s.logger.Warn("failed to store shadow decision", "decision_id", resp.DecisionID, "error", err)
+			return
+		}
+
+		trace := &models.DecisionTrace{
+			TraceID:         fmt.Sprintf("trace-%d", time.Now().UnixNano()),
+			DecisionID:      shadowID,
+			PolicyID:        candidate.ID,
+			PolicyVersion:   candidate.Version,
+			TraceData:       mustMarshal(result),
+			RulesEvaluated:  mustMarshal(allResults),
+			RulesMatched:    mustMarshal([]string{result.RuleID}),
+			FeaturesUsed:    mustMarshal(featuresUsed{ServiceFeatures: req.Features, Facts: result.Facts, Params: params, EvaluatedAt: result.EvaluatedAt}),
+			ExecutionTimeMs: int(time.Since(start).Milliseconds()),
+		}
+		if err := s.decisionStore.StoreTrace(ctx, trace); err != nil {
+			s.logger.Warn("failed to store shadow trace", "decision_id", shadowID, "error", err)
+		}
+	}
+
+	if !agree {
+		s.logger.Info("candidate policy disagrees",
+			"decision_id", resp.DecisionID,
+			"policy_id", candidate.ID,
+			"candidate_version", candidate.Version,
+			"live_result", resp.DecisionResult,
+			"shadow_result", decisionResult,
+			"live_rule", liveResult.RuleID,
+			"shadow_rule", result.RuleID,
+		)
+	}
+}
+
+// confidenceOf returns the confidence stored for an evaluation result
+func confidenceOf(result *policy.EvaluationResult) *float64 {
+	confidence := 0.8
+	if result.Confidence > 0 {
+		confidence = result.Confidence
+	}
+	return &confidence
+}
+
+// agrees reports whether two decisions have the same result and action types
+func agrees(liveResult models.DecisionResult, liveActions []models.Action, shadowResult models.DecisionResult, shadowActions []models.Action) bool {
+	if liveResult != shadowResult || len(liveActions) != len(shadowActions) {
+		return false
+	}
+	types := func(actions []models.Action) []string {
+		t := make([]string, len(actions))
+		for i, a := range actions {
+			t[i] = string(a.Type)
+		}
+		sort.Strings(t)
+		return t
+	}
+	live, shadow := types(liveActions), types(shadowActions)
+	for i := range live {
+		if live[i] != shadow[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func ruleLabel(ruleID string) string {
+	if ruleID == "" {
+		return noRule
+	}
+	return ruleID
+}
+
+// shadowKey identifies the shadow evaluations of a candidate counted together:
+// those where the live policy matched the same rule
+type shadowKey struct {
+	policyID string
+	version  string // of the candidate
+	rule     string // matched by the live policy
+}
+
+// shadowStats counts shadow evaluations and agreements since startup
+type shadowStats struct {
+	mu     sync.Mutex
+	counts map[shadowKey]*[2]int // evaluations, agreements
+}
+
+func newShadowStats() *shadowStats {
+	return &shadowStats{counts: make(map[shadowKey]*[2]int)}
+}
+
+func (st *shadowStats) record(policyID, version, rule string, agree bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	key := shadowKey{policyID: policyID, version: version, rule: rule}
+	c, ok := st.counts[key]
+	if !ok {
+		c = &[2]int{}
+		st.counts[key] = c
+	}
+	c[0]++
+	if agree {
+		c[1]++
+	}
+}
+
+var (
+	shadowEvaluationsDesc = prometheus.NewDesc(
+		"ade_shadow_evaluations_total",
+		"Shadow evaluations of a candidate policy version, by the rule the live policy matched",
+		[]string{"policy", "candidate_version", "rule"}, nil,
+	)
+	shadowAgreementsDesc = prometheus.NewDesc(
+		"ade_shadow_agreements_total",
+		"Shadow evaluations where the candidate policy version decided as the live policy did",
+		[]string{"policy", "candidate_version", "rule"}, nil,
+	)
+	shadowAgreementRateDesc = prometheus.NewDesc(
+		"ade_shadow_agreement_rate",
+		"Share of shadow evaluations where the candidate policy version agreed with the live policy",
+		[]string{"policy", "candidate_version", "rule"}, nil,
+	)
+)
+
+// shadowCollector exports the shadow evaluation counts
+type shadowCollector struct {
+	stats *shadowStats
+}
+
+// MetricsCollector returns a Prometheus collector for the agreement of
+// candidate policy versions with the live ones, per rule
+func (s *Service) MetricsCollector() prometheus.Collector {
+	return &shadowCollector{stats: s.shadowStats}
+}
+
+func (sc *shadowCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- shadowEvaluationsDesc
+	ch <- shadowAgreementsDesc
+	ch <- shadowAgreementRateDesc
+}
+
+func (sc *shadowCollector) Collect(ch chan<- prometheus.Metric) {
+	sc.stats.mu.Lock()
+	defer sc.stats.mu.Unlock()
+
+	for key, c := range sc.stats.counts {
+		labels := []string{key.policyID, key.version, key.rule}
+		ch <- prometheus.MustNewConstMetric(shadowEvaluationsDesc, prometheus.CounterValue, float64(c[0]), labels...)
+		ch <- prometheus.MustNewConstMetric(shadowAgreementsDesc, prometheus.CounterValue, float64(c[1]), labels...)
+		ch <- prometheus.MustNewConstMetric(shadowAgreementRateDesc, prometheus.GaugeValue, float64(c[1])/float64(c[0]), labels...)
+	}
+}
+
+// RuleAgreement is how often a candidate agreed with the live policy when
+// the live policy matched a rule
+type RuleAgreement struct {
+	RuleID        string  `json:"rule_id"`
+	Evaluations   int     `json:"evaluations"`
+	Agreements    int     `json:"agreements"`
+	AgreementRate float64 `json:"agreement_rate"`
+}
+
+// ShadowReport compares the shadow evaluations of a policy's candidate
+// versions with the live decisions, listing where the candidate would have
+// acted differently
+type ShadowReport struct {
+	PolicyID      string                     `json:"policy_id"`
+	Evaluations   int                        `json:"evaluations"`
+	Agreements    int                        `json:"agreements"`
+	AgreementRate float64                    `json:"agreement_rate"`
+	Rules         []RuleAgreement            `json:"rules"`
+	Disagreements []*models.ShadowComparison `json:"disagreements"`
+}
+
+// ShadowReport reports on the stored shadow evaluations of a policy matching
+// the filters
+func (s *Service) ShadowReport(ctx context.Context, filters models.DecisionFilters) (*ShadowReport, error) {
+	if s.decisionStore == nil {
+		return nil, fmt.Errorf("decision store not available")
+	}
+	comparisons, err := s.decisionStore.ListShadowComparisons(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return buildShadowReport(filters.PolicyID, comparisons), nil
+}
+
+// buildShadowReport aggregates shadow comparisons by the rule the live policy
+// matched
+func buildShadowReport(policyID string, comparisons []*models.ShadowComparison) *ShadowReport {
+	report := &ShadowReport{PolicyID: policyID, Rules: []RuleAgreement{}, Disagreements: []*models.ShadowComparison{}}
+	rules := make(map[string]*RuleAgreement)
+	for _, c := range comparisons {
+		var liveActions, shadowActions []models.Action
+		json.Unmarshal(c.LiveActions, &liveActions)
+		json.Unmarshal(c.ShadowActions, &shadowActions)
+		agree := agrees(c.LiveResult, liveActions, c.ShadowResult, shadowActions)
+
+		rule, ok := rules[ruleLabel(c.LiveRule)]
+		if !ok {
+			rule = &RuleAgreement{RuleID: ruleLabel(c.LiveRule)}
+			rules[rule.RuleID] = rule
+		}
+		rule.Evaluations++
+		report.Evaluations++
+		if agree {
+			rule.Agreements++
+			report.Agreements++
+		} else {
+			report.Disagreements = append(report.Disagreements, c)
+		}
+	}
+
+	for _, rule := range rules {
+		rule.AgreementRate = float64(rule.Agreements) / float64(rule.Evaluations)
+		report.Rules = append(report.Rules, *rule)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool { return report.Rules[i].RuleID < report.Rules[j].RuleID })
+	if report.Evaluations > 0 {
+		report.AgreementRate = float64(report.Agreements) / float64(report.Evaluations)
+	}
+	return report
+}
@@ -0,0 +1,76 @@
+package decision
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeDecisionShadow(t *testing.T) {
+	live := testPolicy(policy.ExecutionModeAuto)
+	registry := policy.NewRegistry()
+	require.NoError(t, registry.Add(live))
+
+	// The candidate scales up only from 97% CPU
+	candidate := &policy.Policy{ID: live.ID, Version: "2.0", Extends: live.ID, Rules: []policy.Rule{{
+		ID:   "high_cpu",
+		When: policy.Condition{Fact: "CPUCurrent", Op: ">=", Value: 97.0},
+	}}}
+	require.NoError(t, registry.SetCandidate(candidate))
+
+	dispatcher := &fakeDispatcher{}
+	svc := NewService(policy.NewEngine(nil), nil, nil, nil)
+	svc.SetActionDispatcher(dispatcher)
+	svc.SetCandidates(registry)
+
+	resp, err := svc.MakeDecision(context.Background(), testRequest(false), live)
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 1)
+	assert.Len(t, dispatcher.executed, 1, "only the live decision acts")
+
+	req := testRequest(false)
+	req.Features.CPUCurrent = 10
+	_, err = svc.MakeDecision(context.Background(), req, live)
+	require.NoError(t, err)
+
+	// Dry runs are not shadowed
+	_, err = svc.MakeDecision(context.Background(), testRequest(true), live)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[shadowKey]*[2]int{
+		{policyID: live.ID, version: "2.0", rule: "high_cpu"}: {1, 0},
+		{policyID: live.ID, version: "2.0", rule: noRule}:     {1, 1},
+	}, svc.shadowStats.counts)
+}
+
+func TestBuildShadowReport(t *testing.T) {
+	scaleUp := mustMarshal([]models.Action{{Type: models.ActionTypeScaleUp}})
+	none := json.RawMessage(`[]`)
+	comparisons := []*models.ShadowComparison{
+		{DecisionID: "dec-1", LiveRule: "high_cpu", ShadowRule: "high_cpu", LiveResult: models.DecisionResultAllow, ShadowResult: models.DecisionResultAllow, LiveActions: scaleUp, ShadowActions: scaleUp},
+		{DecisionID: "dec-2", LiveRule: "high_cpu", LiveResult: models.DecisionResultAllow, ShadowResult: models.DecisionResultAllow, LiveActions: scaleUp, ShadowActions: none},
+		{DecisionID: "dec-3", LiveResult: models.DecisionResultAllow, ShadowResult: models.DecisionResultAllow, LiveActions: none, ShadowActions: none},
+		{DecisionID: "dec-4", LiveResult: models.DecisionResultAllow, ShadowResult: models.DecisionResultThrottle, LiveActions: none, ShadowActions: none},
+	}
+
+	report := buildShadowReport("test_policy", comparisons)
+	assert.Equal(t, 4, report.Evaluations)
+	assert.Equal(t, 2, report.Agreements)
+	assert.Equal(t, 0.5, report.AgreementRate)
+	assert.Equal(t, []RuleAgreement{
+		{RuleID: "high_cpu", Evaluations: 2, Agreements: 1, AgreementRate: 0.5},
+		{RuleID: noRule, Evaluations: 2, Agreements: 1, AgreementRate: 0.5},
+	}, report.Rules)
+	require.Len(t, report.Disagreements, 2)
+	assert.Equal(t, "dec-2", report.Disagreements[0].DecisionID)
+	assert.Equal(t, "dec-4", report.Disagreements[1].DecisionID)
+
+	empty := buildShadowReport("test_policy", nil)
+	assert.Zero(t, empty.AgreementRate)
+	assert.NotNil(t, empty.Disagreements)
+}
@@ -0,0 +1,146 @@
+package experiment
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// Handler handles HTTP requests for policy experiments
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new experiment handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the experiment routes
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/experiments", h.handleExperiments)
+	mux.HandleFunc("/experiments/{id}", h.handleGetExperiment)
+	mux.HandleFunc("/experiments/{id}/stop", h.handleStopExperiment)
+	mux.HandleFunc("/experiments/{id}/report", h.handleReport)
+}
+
+// handleExperiments lists (GET, running=true for the running ones) or
+// starts (POST) experiments
+func (h *Handler) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		experiments, err := h.service.List(r.Context(), r.URL.Query().Get("running") == "true")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to list experiments: "+err.Error())
+			return
+		}
+		if experiments == nil {
+			experiments = []*models.Experiment{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"experiments": experiments,
+			"count":       len(experiments),
+		})
+
+	case http.MethodPost:
+		var e models.Experiment
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if err := h.service.Create(r.Context(), &e); err != nil {
+			var validationErr models.ValidationError
+			switch {
+			case errors.As(err, &validationErr):
+				writeError(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, models.ErrPolicyNotFound):
+				writeError(w, http.StatusNotFound, err.Error())
+			case errors.Is(err, ErrExperimentRunning):
+				writeError(w, http.StatusConflict, err.Error())
+			default:
+				writeError(w, http.StatusInternalServerError, "failed to start experiment: "+err.Error())
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(e)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *Handler) handleGetExperiment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	experimentID := r.PathValue("id")
+	e, err := h.service.Get(r.Context(), experimentID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "experiment not found: "+experimentID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get experiment: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+}
+
+func (h *Handler) handleStopExperiment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	experimentID := r.PathValue("id")
+	e, err := h.service.Stop(r.Context(), experimentID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "no running experiment "+experimentID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to stop experiment: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+}
+
+func (h *Handler) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	experimentID := r.PathValue("id")
+	report, err := h.service.Report(r.Context(), experimentID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "experiment not found: "+experimentID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to build experiment report: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,372 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
+)
+
+// ErrExperimentRunning is returned when a policy already has a running experiment
+var ErrExperimentRunning = errors.New("policy already has a running experiment")
+
+// significanceLevel is the p-value under which a difference is significant
+const significanceLevel = 0.05
+
+// refreshInterval is how long the running experiments read from the store
+// are used before Assign reads them again, so experiments started or
+// stopped on another replica take effect here too
+const refreshInterval = 15 * time.Second
+
+// Policies looks up the policies experiment arms run; policy.Registry
+// implements it
+type Policies interface {
+	Get(id string) (*policy.Policy, error)
+}
+
+// Service runs policy experiments: it assigns services to arms by a stable
+// hash of their ID and reports on the impact each arm's decisions had
+type Service struct {
+	store    *postgres.ExperimentStore
+	policies Policies
+	logger   *slog.Logger
+
+	mu       sync.RWMutex
+	running  map[string]*models.Experiment // by the policy they split
+	stopped  map[string]*models.Experiment // kept when there is no store
+	assigned map[string]bool               // experiment/service assignments already stored
+	loadedAt time.Time                     // when running was last read from the store
+}
+
+// NewService creates a new experiment service
+func NewService(store *postgres.ExperimentStore, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		store:    store,
+		logger:   logger,
+		running:  make(map[string]*models.Experiment),
+		stopped:  make(map[string]*models.Experiment),
+		assigned: make(map[string]bool),
+	}
+}
+
+// SetPolicies makes new experiments check that the policies they name exist
+func (s *Service) SetPolicies(policies Policies) {
+	s.policies = policies
+}
+
+// Load loads the running experiments from the store, replacing those held
+func (s *Service) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	loadedAt := time.Now()
+	experiments, err := s.store.List(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	running := make(map[string]*models.Experiment, len(experiments))
+	for _, e := range experiments {
+		running[e.PolicyID] = e
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = running
+	s.loadedAt = loadedAt
+	return nil
+}
+
+// refresh loads the running experiments again once refreshInterval has
+// passed since they were last loaded. Until a load succeeds the experiments
+// already held are used.
+func (s *Service) refresh(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+	s.mu.Lock()
+	stale := time.Since(s.loadedAt) >= refreshInterval
+	if stale {
+		// Other callers keep using the experiments held while this one loads
+		s.loadedAt = time.Now()
+	}
+	s.mu.Unlock()
+	if !stale {
+		return
+	}
+
+	if err := s.Load(ctx); err != nil {
+		s.logger.Warn("failed to refresh running experiments", "error", err)
+	}
+}
+
+// Create validates and starts an experiment
+func (s *Service) Create(ctx context.Context, e *models.Experiment) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if s.policies != nil {
+		for _, id := range append([]string{e.PolicyID}, armPolicies(e)...) {
+			if _, err := s.policies.Get(id); err != nil {
+				return err
+			}
+		}
+	}
+	// Another replica may have started or stopped an experiment
+	if err := s.Load(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.running[e.PolicyID]; ok {
+		return fmt.Errorf("%w: %s", ErrExperimentRunning, e.PolicyID)
+	}
+	_, exists := s.stopped[e.ExperimentID]
+	for _, running := range s.running {
+		exists = exists || running.ExperimentID == e.ExperimentID
+	}
+	if exists {
+		return models.NewValidationError("experiment_id", "experiment "+e.ExperimentID+" already exists")
+	}
+
+	e.Status = models.ExperimentStatusRunning
+	e.StartedAt = time.Now()
+	e.StoppedAt = nil
+	if s.store != nil {
+		if err := s.store.Create(ctx, e); err != nil {
+			return err
+		}
+	} else {
+		e.CreatedAt = e.StartedAt
+	}
+	s.running[e.PolicyID] = e
+
+	s.logger.Info("experiment started", "experiment_id", e.ExperimentID, "policy_id", e.PolicyID, "arms", len(e.Arms))
+	return nil
+}
+
+// Stop stops a running experiment; its policy's decisions go back to the policy
+func (s *Service) Stop(ctx context.Context, experimentID string) (*models.Experiment, error) {
+	if err := s.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var e *models.Experiment
+	for _, running := range s.running {
+		if running.ExperimentID == experimentID {
+			e = running
+		}
+	}
+	if e == nil {
+		return nil, models.ErrNotFound
+	}
+
+	stopped := *e
+	now := time.Now()
+	stopped.Status = models.ExperimentStatusStopped
+	stopped.StoppedAt = &now
+	if s.store != nil {
+		if err := s.store.Stop(ctx, &stopped); err != nil {
+			return nil, err
+		}
+	} else {
+		s.stopped[experimentID] = &stopped
+	}
+	delete(s.running, e.PolicyID)
+
+	s.logger.Info("experiment stopped", "experiment_id", experimentID, "policy_id", e.PolicyID)
+	return &stopped, nil
+}
+
+// Get returns an experiment
+func (s *Service) Get(ctx context.Context, experimentID string) (*models.Experiment, error) {
+	if s.store != nil {
+		return s.store.GetByID(ctx, experimentID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.running {
+		if e.ExperimentID == experimentID {
+			return e, nil
+		}
+	}
+	if e, ok := s.stopped[experimentID]; ok {
+		return e, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+// List returns the experiments, newest first
+func (s *Service) List(ctx context.Context, runningOnly bool) ([]*models.Experiment, error) {
+	if s.store != nil {
+		return s.store.List(ctx, runningOnly)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	experiments := make([]*models.Experiment, 0, len(s.running)+len(s.stopped))
+	for _, e := range s.running {
+		experiments = append(experiments, e)
+	}
+	if !runningOnly {
+		for _, e := range s.stopped {
+			experiments = append(experiments, e)
+		}
+	}
+	sort.Slice(experiments, func(i, j int) bool {
+		return experiments[i].StartedAt.After(experiments[j].StartedAt)
+	})
+	return experiments, nil
+}
+
+// Assign returns the arm of the running experiment on a policy a service is
+// in, or nil when the policy has no running experiment. The first
+// assignment of each service is stored.
+func (s *Service) Assign(ctx context.Context, policyID, serviceID string) *models.ExperimentAssignment {
+	s.refresh(ctx)
+
+	s.mu.RLock()
+	e, ok := s.running[policyID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	arm := assignArm(e, serviceID)
+	assignment := &models.ExperimentAssignment{
+		ExperimentID: e.ExperimentID,
+		ServiceID:    serviceID,
+		Arm:          arm.Name,
+		PolicyID:     arm.PolicyID,
+		AssignedAt:   time.Now(),
+	}
+	s.recordAssignment(ctx, assignment)
+	return assignment
+}
+
+func (s *Service) recordAssignment(ctx context.Context, a *models.ExperimentAssignment) {
+	if s.store == nil {
+		return
+	}
+	key := a.ExperimentID + "/" + a.ServiceID
+	s.mu.RLock()
+	done := s.assigned[key]
+	s.mu.RUnlock()
+	if done {
+		return
+	}
+
+	if err := s.store.RecordAssignment(ctx, a); err != nil {
+		s.logger.Warn("failed to record experiment assignment", "experiment_id", a.ExperimentID, "service_id", a.ServiceID, "error", err)
+		return
+	}
+	s.mu.Lock()
+	s.assigned[key] = true
+	s.mu.Unlock()
+}
+
+// assignArm picks the arm of a service by a stable hash of the experiment
+// and service IDs, so a service stays in its arm and each experiment splits
+// services independently
+func assignArm(e *models.Experiment, serviceID string) models.ExperimentArm {
+	h := fnv.New32a()
+	h.Write([]byte(e.ExperimentID + "/" + serviceID))
+	bucket := int(h.Sum32() % 100)
+
+	for _, arm := range e.Arms {
+		if bucket < arm.Weight {
+			return arm
+		}
+		bucket -= arm.Weight
+	}
+	return e.Arms[len(e.Arms)-1]
+}
+
+func armPolicies(e *models.Experiment) []string {
+	ids := make([]string, len(e.Arms))
+	for i, arm := range e.Arms {
+		ids[i] = arm.PolicyID
+	}
+	return ids
+}
+
+// ArmReport is the outcome of an experiment arm, compared with the control
+type ArmReport struct {
+	models.ExperimentArmOutcome
+	PolicyID     string        `json:"policy_id"`
+	Control      bool          `json:"control,omitempty"`
+	Significance *Significance `json:"significance,omitempty"` // nil for the control and when there is too little feedback
+	Significant  bool          `json:"significant"`
+}
+
+// Report compares the impact of an experiment's arms
+type Report struct {
+	Experiment *models.Experiment `json:"experiment"`
+	Arms       []ArmReport        `json:"arms"`
+	Best       string             `json:"best,omitempty"` // arm with the best impact, once some arm differs significantly from the control
+}
+
+// Report reports on the decisions each arm of an experiment made and the
+// impact scores they received, testing every arm against the control
+func (s *Service) Report(ctx context.Context, experimentID string) (*Report, error) {
+	e, err := s.Get(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	if s.store == nil {
+		return nil, fmt.Errorf("experiment store not available")
+	}
+	outcomes, err := s.store.ArmOutcomes(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	return buildReport(e, outcomes), nil
+}
+
+// buildReport lists every arm of an experiment in order with its outcome.
+// The best arm is the control or an arm significantly better than it, and is
+// named only once some arm differs significantly from the control.
+func buildReport(e *models.Experiment, outcomes []models.ExperimentArmOutcome) *Report {
+	byArm := make(map[string]models.ExperimentArmOutcome, len(outcomes))
+	for _, o := range outcomes {
+		byArm[o.Arm] = o
+	}
+
+	report := &Report{Experiment: e, Arms: make([]ArmReport, len(e.Arms))}
+	control := byArm[e.Arms[0].Name]
+	best, bestImpact, significant := e.Arms[0].Name, control.MeanImpact, false
+	for i, arm := range e.Arms {
+		outcome := byArm[arm.Name]
+		outcome.Arm = arm.Name
+		r := ArmReport{ExperimentArmOutcome: outcome, PolicyID: arm.PolicyID, Control: i == 0}
+		if i > 0 {
+			r.Significance = welchTTest(outcome.FeedbackCount, outcome.MeanImpact, outcome.ImpactVariance,
+				control.FeedbackCount, control.MeanImpact, control.ImpactVariance)
+			r.Significant = r.Significance != nil && r.Significance.PValue < significanceLevel
+			significant = significant || r.Significant
+			if r.Significant && outcome.MeanImpact > bestImpact {
+				best, bestImpact = arm.Name, outcome.MeanImpact
+			}
+		}
+		report.Arms[i] = r
+	}
+	if significant {
+		report.Best = best
+	}
+	return report
+}
@@ -0,0 +1,126 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExperiment() *models.Experiment {
+	return &models.Experiment{
+		ExperimentID: "autoscale-v2",
+		PolicyID:     "autoscale_policy",
+		Arms: []models.ExperimentArm{
+			{Name: "control", PolicyID: "autoscale_policy", Weight: 80},
+			{Name: "v2", PolicyID: "autoscale_v2", Weight: 20},
+		},
+	}
+}
+
+func TestExperimentValidate(t *testing.T) {
+	require.NoError(t, testExperiment().Validate())
+
+	for name, change := range map[string]func(e *models.Experiment){
+		"one arm":         func(e *models.Experiment) { e.Arms = e.Arms[:1] },
+		"weights":         func(e *models.Experiment) { e.Arms[1].Weight = 30 },
+		"duplicate arm":   func(e *models.Experiment) { e.Arms[1].Name = "control" },
+		"arm policy":      func(e *models.Experiment) { e.Arms[1].PolicyID = "" },
+		"policy required": func(e *models.Experiment) { e.PolicyID = "" },
+	} {
+		e := testExperiment()
+		change(e)
+		assert.Error(t, e.Validate(), name)
+	}
+}
+
+func TestAssignArm(t *testing.T) {
+	e := testExperiment()
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		serviceID := fmt.Sprintf("service-%d", i)
+		arm := assignArm(e, serviceID)
+		assert.Equal(t, arm, assignArm(e, serviceID), "assignment is stable")
+		counts[arm.Name]++
+	}
+	assert.InDelta(t, 400, counts["v2"], 80)
+	assert.Equal(t, 2000, counts["control"]+counts["v2"])
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	registry := policy.NewRegistry()
+	require.NoError(t, registry.Add(&policy.Policy{ID: "autoscale_policy", Version: "1.0",
+		Rules: []policy.Rule{{ID: "scale_up", Name: "Scale Up", Action: policy.Action{Type: "scale_up"}}}}))
+	svc := NewService(nil, nil)
+	svc.SetPolicies(registry)
+
+	assert.ErrorIs(t, svc.Create(ctx, testExperiment()), models.ErrPolicyNotFound)
+	require.NoError(t, registry.Add(&policy.Policy{ID: "autoscale_v2", Version: "2.0", Extends: "autoscale_policy"}))
+	require.NoError(t, svc.Create(ctx, testExperiment()))
+	assert.ErrorIs(t, svc.Create(ctx, testExperiment()), ErrExperimentRunning)
+
+	assert.Nil(t, svc.Assign(ctx, "ratelimit_default", "checkout"))
+	assignment := svc.Assign(ctx, "autoscale_policy", "checkout")
+	require.NotNil(t, assignment)
+	assert.Equal(t, "autoscale-v2", assignment.ExperimentID)
+	assert.Equal(t, assignArm(testExperiment(), "checkout").PolicyID, assignment.PolicyID)
+
+	stopped, err := svc.Stop(ctx, "autoscale-v2")
+	require.NoError(t, err)
+	assert.Equal(t, models.ExperimentStatusStopped, stopped.Status)
+	assert.Nil(t, svc.Assign(ctx, "autoscale_policy", "checkout"))
+	_, err = svc.Stop(ctx, "autoscale-v2")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	experiments, err := svc.List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, experiments, 1)
+	assert.Equal(t, models.ExperimentStatusStopped, experiments[0].Status)
+}
+
+func TestWelchTTest(t *testing.T) {
+	s := welchTTest(20, 0.3, 0.04, 20, 0.1, 0.05)
+	require.NotNil(t, s)
+	assert.InDelta(t, 0.2, s.Difference, 1e-9)
+	assert.InDelta(t, 2.981, s.TStat, 1e-3)
+	assert.InDelta(t, 37.54, s.DF, 1e-2)
+	assert.InDelta(t, 0.00501, s.PValue, 1e-4)
+
+	// Reference values of the two-sided t distribution
+	assert.InDelta(t, 0.0734, incompleteBeta(5, 0.5, 10.0/14), 1e-4)  // t=2, df=10
+	assert.InDelta(t, 0.3253, incompleteBeta(15, 0.5, 30.0/31), 1e-4) // t=1, df=30
+
+	assert.Nil(t, welchTTest(1, 0.3, 0, 20, 0.1, 0.05))
+	assert.Nil(t, welchTTest(5, 0.3, 0, 5, 0.1, 0))
+}
+
+func TestBuildReport(t *testing.T) {
+	e := testExperiment()
+	report := buildReport(e, []models.ExperimentArmOutcome{
+		{Arm: "v2", Decisions: 60, FeedbackCount: 20, MeanImpact: 0.3, ImpactVariance: 0.04},
+		{Arm: "control", Decisions: 240, FeedbackCount: 20, MeanImpact: 0.1, ImpactVariance: 0.05},
+	})
+	require.Len(t, report.Arms, 2)
+	assert.True(t, report.Arms[0].Control)
+	assert.Nil(t, report.Arms[0].Significance)
+	assert.True(t, report.Arms[1].Significant)
+	assert.Equal(t, "v2", report.Best)
+
+	// A significantly worse arm makes the control the best
+	report = buildReport(e, []models.ExperimentArmOutcome{
+		{Arm: "v2", FeedbackCount: 20, MeanImpact: -0.1, ImpactVariance: 0.04},
+		{Arm: "control", FeedbackCount: 20, MeanImpact: 0.1, ImpactVariance: 0.05},
+	})
+	assert.Equal(t, "control", report.Best)
+
+	// Without enough feedback nothing is named
+	report = buildReport(e, []models.ExperimentArmOutcome{{Arm: "control", Decisions: 3, FeedbackCount: 1, MeanImpact: 0.2}})
+	assert.Equal(t, "v2", report.Arms[1].Arm)
+	assert.Zero(t, report.Arms[1].Decisions)
+	assert.Empty(t, report.Best)
+}
@@ -0,0 +1,94 @@
+package experiment
+
+import "math"
+
+// Significance is a Welch's t-test of the difference in mean impact between
+// an arm and the control
+type Significance struct {
+	Difference float64 `json:"difference"` // arm mean minus control mean
+	TStat      float64 `json:"t_stat"`
+	DF         float64 `json:"degrees_of_freedom"`
+	PValue     float64 `json:"p_value"` // two-sided
+}
+
+// welchTTest compares two samples by size, mean and sample variance. It
+// returns nil when either sample has fewer than two values or both have no
+// variance, as there is nothing to estimate then.
+func welchTTest(n1 int, mean1, var1 float64, n2 int, mean2, var2 float64) *Significance {
+	if n1 < 2 || n2 < 2 {
+		return nil
+	}
+	se1, se2 := var1/float64(n1), var2/float64(n2)
+	if se1+se2 == 0 {
+		return nil
+	}
+
+	t := (mean1 - mean2) / math.Sqrt(se1+se2)
+	df := (se1 + se2) * (se1 + se2) / (se1*se1/float64(n1-1) + se2*se2/float64(n2-1))
+	return &Significance{
+		Difference: mean1 - mean2,
+		TStat:      t,
+		DF:         df,
+		PValue:     incompleteBeta(df/2, 0.5, df/(df+t*t)),
+	}
+}
+
+// incompleteBeta is the regularized incomplete beta function I_x(a, b),
+// evaluated by its continued fraction
+func incompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lbeta, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	front := math.Exp(lbeta - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	// The continued fraction converges quickly only below this point
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(b, a, 1-x)/b
+	}
+	return front * betaFraction(a, b, x) / a
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta
+// function with the modified Lentz method
+func betaFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-12
+		tiny          = 1e-300
+	)
+
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	f := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			f *= c * d
+		}
+		if math.Abs(c*d-1) < epsilon {
+			break
+		}
+	}
+	return f
+}
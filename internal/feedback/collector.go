@@ -0,0 +1,127 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/scheduler"
+	"github.com/aegis-decision-engine/ade/internal/state"
+)
+
+// FeatureSource calculates current features for a service; *state.Service implements it
+type FeatureSource interface {
+	CalculateFeatures(ctx context.Context, req *state.CalculateFeaturesRequest) (*models.ServiceFeatures, error)
+}
+
+// Collector measures the impact of completed actions without external callers.
+// It snapshots features when an action completes and schedules a follow-up
+// after the observation window that records delayed feedback.
+type Collector struct {
+	features      FeatureSource
+	scheduler     *scheduler.Scheduler
+	service       *Service
+	window        time.Duration
+	featureWindow time.Duration
+	logger        *slog.Logger
+}
+
+// NewCollector creates a new feedback collector
+func NewCollector(features FeatureSource, sched *scheduler.Scheduler, service *Service, window, featureWindow time.Duration, logger *slog.Logger) *Collector {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	if featureWindow <= 0 {
+		featureWindow = 5 * time.Minute
+	}
+	return &Collector{
+		features:      features,
+		scheduler:     sched,
+		service:       service,
+		window:        window,
+		featureWindow: featureWindow,
+		logger:        logger,
+	}
+}
+
+// followUp is the payload of a scheduled feedback job
+type followUp struct {
+	ActionID      string
+	DecisionID    string
+	ServiceID     string
+	MetricsBefore map[string]float64
+}
+
+// ActionCompleted snapshots the target's features and schedules the follow-up measurement
+func (c *Collector) ActionCompleted(ctx context.Context, req *action.ActionRequest, result *action.ActionResult) {
+	if result.DryRun {
+		return
+	}
+
+	before, err := c.features.CalculateFeatures(ctx, &state.CalculateFeaturesRequest{
+		ServiceID: req.TargetService,
+		Window:    c.featureWindow,
+	})
+	if err != nil {
+		c.logger.Warn("failed to snapshot features for feedback",
+			"action_id", req.ActionID,
+			"service_id", req.TargetService,
+			"error", err,
+		)
+		return
+	}
+
+	c.scheduler.Schedule(&scheduler.Job{
+		ID:        "feedback-" + req.ActionID,
+		ExecuteAt: time.Now().Add(c.window),
+		Payload: &followUp{
+			ActionID:      req.ActionID,
+			DecisionID:    req.DecisionID,
+			ServiceID:     req.TargetService,
+			MetricsBefore: MetricsFromFeatures(before),
+		},
+		Handler: c.collect,
+	})
+}
+
+func (c *Collector) collect(ctx context.Context, payload interface{}) error {
+	job, ok := payload.(*followUp)
+	if !ok {
+		return fmt.Errorf("unexpected feedback payload %T", payload)
+	}
+
+	after, err := c.features.CalculateFeatures(ctx, &state.CalculateFeaturesRequest{
+		ServiceID: job.ServiceID,
+		Window:    c.featureWindow,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to recompute features: %w", err)
+	}
+
+	_, err = c.service.RecordFeedback(ctx, &FeedbackRequest{
+		ActionID:              job.ActionID,
+		DecisionID:            job.DecisionID,
+		ServiceID:             job.ServiceID,
+		FeedbackType:          string(models.FeedbackTypeDelayed),
+		MetricsBefore:         job.MetricsBefore,
+		MetricsAfter:          MetricsFromFeatures(after),
+		ObservationWindowMins: int(c.window.Minutes()),
+	})
+	return err
+}
+
+// MetricsFromFeatures maps service features to the metric names used by feedback analysis
+func MetricsFromFeatures(f *models.ServiceFeatures) map[string]float64 {
+	return map[string]float64{
+		"cpu":        f.CPUCurrent,
+		"latency":    f.LatencyP95,
+		"error_rate": f.ErrorRate,
+		"throughput": f.RequestsPerSec,
+	}
+}
@@ -0,0 +1,60 @@
+package feedback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/scheduler"
+	"github.com/aegis-decision-engine/ade/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubFeatures struct {
+	snapshots []*models.ServiceFeatures
+	calls     int
+}
+
+func (s *stubFeatures) CalculateFeatures(ctx context.Context, req *state.CalculateFeaturesRequest) (*models.ServiceFeatures, error) {
+	f := s.snapshots[s.calls]
+	s.calls++
+	return f, nil
+}
+
+func TestCollectorSchedulesDelayedFeedback(t *testing.T) {
+	features := &stubFeatures{snapshots: []*models.ServiceFeatures{
+		{CPUCurrent: 90, LatencyP95: 400, ErrorRate: 0.05, RequestsPerSec: 100},
+		{CPUCurrent: 50, LatencyP95: 200, ErrorRate: 0.01, RequestsPerSec: 100},
+	}}
+	sched := scheduler.NewScheduler(nil)
+	collector := NewCollector(features, sched, NewService(nil, nil, nil, nil), 10*time.Minute, time.Minute, nil)
+
+	req := &action.ActionRequest{ActionID: "act-1", DecisionID: "dec-1", TargetService: "checkout"}
+	collector.ActionCompleted(context.Background(), req, &action.ActionResult{ActionID: "act-1", Status: "completed"})
+
+	require.Len(t, sched.Jobs(), 1)
+	job, ok := sched.Job("feedback-act-1")
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), job.ExecuteAt, time.Second)
+
+	payload := job.Payload.(*followUp)
+	assert.Equal(t, 90.0, payload.MetricsBefore["cpu"])
+
+	require.NoError(t, job.Handler(context.Background(), job.Payload))
+	assert.Equal(t, 2, features.calls)
+}
+
+func TestCollectorSkipsDryRun(t *testing.T) {
+	features := &stubFeatures{}
+	sched := scheduler.NewScheduler(nil)
+	collector := NewCollector(features, sched, NewService(nil, nil, nil, nil), 0, 0, nil)
+
+	req := &action.ActionRequest{ActionID: "act-1", DecisionID: "dec-1", TargetService: "checkout"}
+	collector.ActionCompleted(context.Background(), req, &action.ActionResult{DryRun: true})
+
+	assert.Empty(t, sched.Jobs())
+	assert.Zero(t, features.calls)
+}
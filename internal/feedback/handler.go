@@ -2,7 +2,13 @@ package feedback
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
 )
 
 // Handler handles HTTP requests for feedback
@@ -17,17 +23,25 @@ func NewHandler(service *Service) *Handler {
 
 // RegisterRoutes registers the feedback routes
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/feedback", h.handleRecordFeedback)
+	mux.HandleFunc("/feedback", h.handleFeedback)
 	mux.HandleFunc("/feedback/{id}", h.handleGetFeedback)
 	mux.HandleFunc("/rollback", h.handleRollback)
 	mux.HandleFunc("/services/{id}/drift", h.handleCheckDrift)
+	mux.HandleFunc("/feedback/reports/policy-impact", h.handlePolicyImpact)
 }
 
-func (h *Handler) handleRecordFeedback(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.handleRecordFeedback(w, r)
+	case http.MethodGet:
+		h.handleListFeedback(w, r)
+	default:
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
 	}
+}
+
+func (h *Handler) handleRecordFeedback(w http.ResponseWriter, r *http.Request) {
 
 	var req FeedbackRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -46,6 +60,37 @@ func (h *Handler) handleRecordFeedback(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+func (h *Handler) handleListFeedback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filters := models.FeedbackFilters{
+		ActionID:   q.Get("action_id"),
+		DecisionID: q.Get("decision_id"),
+		ServiceID:  q.Get("service_id"),
+		DriftOnly:  q.Get("drift_only") == "true",
+		Limit:      100,
+	}
+	if l := q.Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			filters.Limit = n
+		}
+	}
+
+	records, err := h.service.ListFeedback(r.Context(), filters)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list feedback: "+err.Error())
+		return
+	}
+	if records == nil {
+		records = []*models.FeedbackRecord{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"feedback": records,
+		"count":    len(records),
+	})
+}
+
 func (h *Handler) handleRollback(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -60,7 +105,14 @@ func (h *Handler) handleRollback(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.service.Rollback(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "rollback failed: "+err.Error())
+		switch {
+		case errors.Is(err, action.ErrNotInvertible):
+			writeError(w, http.StatusConflict, err.Error()+"; set force to roll back via webhook")
+		case models.IsNotFound(err):
+			writeError(w, http.StatusNotFound, "action not found: "+req.ActionID)
+		default:
+			writeError(w, http.StatusInternalServerError, "rollback failed: "+err.Error())
+		}
 		return
 	}
 
@@ -81,13 +133,50 @@ func (h *Handler) handleCheckDrift(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: implement actual drift check against current metrics
+	window := 24 * time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			window = d
+		}
+	}
+	bucket := time.Hour
+	if v := r.URL.Query().Get("bucket"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			bucket = d
+		}
+	}
+
+	history, err := h.service.GetDriftHistory(r.Context(), serviceID, window, bucket)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "drift history failed: "+err.Error())
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"service_id":    serviceID,
-		"drift_detected": false,
-		"message":       "drift check not yet implemented",
-	})
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *Handler) handlePolicyImpact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	window := 24 * time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			window = d
+		}
+	}
+
+	report, err := h.service.GetPolicyImpactReport(r.Context(), window)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "policy impact report failed: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
 }
 
 func (h *Handler) handleGetFeedback(w http.ResponseWriter, r *http.Request) {
@@ -96,8 +185,24 @@ func (h *Handler) handleGetFeedback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: implement
-	writeError(w, http.StatusNotImplemented, "not implemented")
+	feedbackID := r.PathValue("id")
+	if feedbackID == "" {
+		writeError(w, http.StatusBadRequest, "feedback id required")
+		return
+	}
+
+	record, err := h.service.GetFeedback(r.Context(), feedbackID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "feedback not found: "+feedbackID)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get feedback: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
 }
 
 func writeError(w http.ResponseWriter, status int, message string) {
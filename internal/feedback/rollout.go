@@ -0,0 +1,78 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/state"
+)
+
+// pauseImpact is the impact score below which a rollout stage is held for
+// review even when no rollback is recommended
+const pauseImpact = -0.3
+
+// RolloutObserver judges the stages of staged actions with the same impact and
+// drift analysis as recorded feedback. Each assessment is recorded as delayed
+// feedback on the action.
+type RolloutObserver struct {
+	features      FeatureSource
+	service       *Service
+	featureWindow time.Duration
+}
+
+// NewRolloutObserver creates a rollout observer
+func NewRolloutObserver(features FeatureSource, service *Service, featureWindow time.Duration) *RolloutObserver {
+	if featureWindow <= 0 {
+		featureWindow = 5 * time.Minute
+	}
+	return &RolloutObserver{
+		features:      features,
+		service:       service,
+		featureWindow: featureWindow,
+	}
+}
+
+// Metrics returns the current metrics of a service
+func (o *RolloutObserver) Metrics(ctx context.Context, serviceID string) (map[string]float64, error) {
+	f, err := o.features.CalculateFeatures(ctx, &state.CalculateFeaturesRequest{
+		ServiceID: serviceID,
+		Window:    o.featureWindow,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate features: %w", err)
+	}
+	return MetricsFromFeatures(f), nil
+}
+
+// Assess records feedback for a stage and maps it to a verdict: a recommended
+// rollback rolls the stage back, drift or a negative impact pauses the rollout
+func (o *RolloutObserver) Assess(ctx context.Context, req *action.ActionRequest, stage int, before, after map[string]float64) (string, float64, error) {
+	var window time.Duration
+	if req.Rollout != nil {
+		window = req.Rollout.WindowDuration(0)
+	}
+
+	result, err := o.service.RecordFeedback(ctx, &FeedbackRequest{
+		ActionID:              req.ActionID,
+		DecisionID:            req.DecisionID,
+		ServiceID:             req.TargetService,
+		FeedbackType:          string(models.FeedbackTypeDelayed),
+		MetricsBefore:         before,
+		MetricsAfter:          after,
+		ObservationWindowMins: int(window.Minutes()),
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("stage %d: %w", stage, err)
+	}
+
+	switch {
+	case result.RollbackRecommended:
+		return models.RolloutVerdictRollback, result.ImpactScore, nil
+	case result.DriftDetected, result.ImpactScore < pauseImpact:
+		return models.RolloutVerdictPause, result.ImpactScore, nil
+	}
+	return models.RolloutVerdictProceed, result.ImpactScore, nil
+}
@@ -0,0 +1,37 @@
+package feedback
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutObserverVerdicts(t *testing.T) {
+	features := &stubFeatures{snapshots: []*models.ServiceFeatures{
+		{CPUCurrent: 50, LatencyP95: 200, ErrorRate: 0.01, RequestsPerSec: 100},
+	}}
+	observer := NewRolloutObserver(features, NewService(nil, nil, nil, nil), 0)
+	req := &action.ActionRequest{ActionID: "act-1", DecisionID: "dec-1", TargetService: "checkout",
+		Rollout: &models.Rollout{Window: "10m"}}
+
+	before, err := observer.Metrics(context.Background(), "checkout")
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, before["latency"])
+
+	verdict, _, err := observer.Assess(context.Background(), req, 0, before, before)
+	require.NoError(t, err)
+	assert.Equal(t, models.RolloutVerdictProceed, verdict)
+
+	degraded := map[string]float64{"cpu": 95, "latency": 2000, "error_rate": 0.5, "throughput": 10}
+	verdict, impact, err := observer.Assess(context.Background(), req, 0, before, degraded)
+	require.NoError(t, err)
+	assert.Equal(t, models.RolloutVerdictRollback, verdict)
+	assert.Less(t, impact, 0.0)
+
+	_, _, err = observer.Assess(context.Background(), req, 0, nil, degraded)
+	assert.Error(t, err)
+}
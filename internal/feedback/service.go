@@ -2,24 +2,37 @@ package feedback
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
+	"strings"
 	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
 )
 
 // Service handles feedback and drift detection
 type Service struct {
-	logger *slog.Logger
+	feedbackStore *postgres.FeedbackStore
+	actionStore   *postgres.ActionStore
+	actionService *action.Service
+	logger        *slog.Logger
 }
 
 // NewService creates a new feedback service
-func NewService(logger *slog.Logger) *Service {
+func NewService(feedbackStore *postgres.FeedbackStore, actionStore *postgres.ActionStore, actionService *action.Service, logger *slog.Logger) *Service {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	return &Service{
-		logger: logger,
+		feedbackStore: feedbackStore,
+		actionStore:   actionStore,
+		actionService: actionService,
+		logger:        logger,
 	}
 }
 
@@ -37,6 +50,7 @@ type FeedbackRequest struct {
 // FeedbackResult represents the result of feedback analysis
 type FeedbackResult struct {
 	FeedbackID           string                 `json:"feedback_id"`
+	FeedbackType         string                 `json:"feedback_type"`
 	ActionID             string                 `json:"action_id"`
 	DecisionID           string                 `json:"decision_id"`
 	ServiceID            string                 `json:"service_id"`
@@ -75,6 +89,9 @@ func (s *Service) RecordFeedback(ctx context.Context, req *FeedbackRequest) (*Fe
 	if req.ObservationWindowMins == 0 {
 		req.ObservationWindowMins = 5
 	}
+	if req.FeedbackType == "" {
+		req.FeedbackType = string(models.FeedbackTypeImmediate)
+	}
 
 	feedbackID := fmt.Sprintf("fbk-%d", time.Now().UnixNano())
 
@@ -89,6 +106,7 @@ func (s *Service) RecordFeedback(ctx context.Context, req *FeedbackRequest) (*Fe
 
 	result := &FeedbackResult{
 		FeedbackID:          feedbackID,
+		FeedbackType:        req.FeedbackType,
 		ActionID:            req.ActionID,
 		DecisionID:          req.DecisionID,
 		ServiceID:           req.ServiceID,
@@ -100,6 +118,13 @@ func (s *Service) RecordFeedback(ctx context.Context, req *FeedbackRequest) (*Fe
 		RecordedAt:          time.Now(),
 	}
 
+	// Persist feedback
+	if s.feedbackStore != nil {
+		if err := s.feedbackStore.Store(ctx, toRecord(req, result)); err != nil {
+			return nil, fmt.Errorf("failed to store feedback: %w", err)
+		}
+	}
+
 	s.logger.Info("feedback recorded",
 		"feedback_id", feedbackID,
 		"action_id", req.ActionID,
@@ -111,56 +136,286 @@ func (s *Service) RecordFeedback(ctx context.Context, req *FeedbackRequest) (*Fe
 	return result, nil
 }
 
+// GetFeedback retrieves a feedback record by ID
+func (s *Service) GetFeedback(ctx context.Context, feedbackID string) (*models.FeedbackRecord, error) {
+	if s.feedbackStore == nil {
+		return nil, fmt.Errorf("feedback store not available")
+	}
+	return s.feedbackStore.GetByID(ctx, feedbackID)
+}
+
+// ListFeedback lists feedback records with filters
+func (s *Service) ListFeedback(ctx context.Context, filters models.FeedbackFilters) ([]*models.FeedbackRecord, error) {
+	if s.feedbackStore == nil {
+		return nil, fmt.Errorf("feedback store not available")
+	}
+	return s.feedbackStore.ListByFilters(ctx, filters)
+}
+
+// DriftHistory summarizes the drift observed for a service over time
+type DriftHistory struct {
+	ServiceID     string                   `json:"service_id"`
+	From          time.Time                `json:"from"`
+	BucketSize    string                   `json:"bucket_size"`
+	FeedbackCount int64                    `json:"feedback_count"`
+	DriftCount    int64                    `json:"drift_count"`
+	DriftRate     float64                  `json:"drift_rate"`
+	Buckets       []models.DriftBucket     `json:"buckets"`
+	RecentDrifts  []*models.FeedbackRecord `json:"recent_drifts"`
+}
+
+// GetDriftHistory aggregates the drift history of a service
+func (s *Service) GetDriftHistory(ctx context.Context, serviceID string, window, bucket time.Duration) (*DriftHistory, error) {
+	if s.feedbackStore == nil {
+		return nil, fmt.Errorf("feedback store not available")
+	}
+
+	from := time.Now().Add(-window)
+	buckets, err := s.feedbackStore.DriftHistory(ctx, serviceID, from, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	recent, err := s.feedbackStore.ListByFilters(ctx, models.FeedbackFilters{
+		ServiceID: serviceID,
+		DriftOnly: true,
+		From:      from,
+		Limit:     10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := &DriftHistory{
+		ServiceID:    serviceID,
+		From:         from,
+		BucketSize:   bucket.String(),
+		Buckets:      buckets,
+		RecentDrifts: recent,
+	}
+	if history.Buckets == nil {
+		history.Buckets = []models.DriftBucket{}
+	}
+	if history.RecentDrifts == nil {
+		history.RecentDrifts = []*models.FeedbackRecord{}
+	}
+	for _, b := range buckets {
+		history.FeedbackCount += b.FeedbackCount
+		history.DriftCount += b.DriftCount
+	}
+	if history.FeedbackCount > 0 {
+		history.DriftRate = float64(history.DriftCount) / float64(history.FeedbackCount)
+	}
+
+	return history, nil
+}
+
+// PolicyImpactReport summarizes the impact of every policy over a window
+type PolicyImpactReport struct {
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	Policies []models.PolicyImpact `json:"policies"`
+}
+
+// GetPolicyImpactReport builds the policy impact report for the last window
+func (s *Service) GetPolicyImpactReport(ctx context.Context, window time.Duration) (*PolicyImpactReport, error) {
+	if s.feedbackStore == nil {
+		return nil, fmt.Errorf("feedback store not available")
+	}
+
+	to := time.Now()
+	from := to.Add(-window)
+	impacts, err := s.feedbackStore.PolicyImpact(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if impacts == nil {
+		impacts = []models.PolicyImpact{}
+	}
+
+	return &PolicyImpactReport{From: from, To: to, Policies: impacts}, nil
+}
+
+// Summary renders the report as one line per policy
+func (r *PolicyImpactReport) Summary() string {
+	if len(r.Policies) == 0 {
+		return "No decisions in this period."
+	}
+
+	var b strings.Builder
+	for _, p := range r.Policies {
+		impact := "n/a"
+		if p.AvgImpactScore != nil {
+			impact = fmt.Sprintf("%.2f", *p.AvgImpactScore)
+		}
+		fmt.Fprintf(&b, "%s: %d decisions, %d actions (%d failed), avg impact %s, %d drifts, %d rollbacks recommended\n",
+			p.PolicyID, p.Decisions, p.Actions, p.FailedActions, impact, p.DriftCount, p.RollbackRecommended)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 // RollbackRequest represents a request to rollback an action
 type RollbackRequest struct {
-	ActionID      string `json:"action_id"`
-	DecisionID    string `json:"decision_id"`
-	ServiceID     string `json:"service_id"`
-	Reason        string `json:"reason"`
-	Force         bool   `json:"force"` // Force rollback even if not recommended
+	ActionID   string `json:"action_id"`
+	DecisionID string `json:"decision_id"`
+	ServiceID  string `json:"service_id"`
+	FeedbackID string `json:"feedback_id,omitempty"` // Feedback that triggered the rollback, defaults to the latest for the action
+	Reason     string `json:"reason"`
+	Force      bool   `json:"force"` // Roll back via a generic webhook when no inverse exists
 }
 
 // RollbackResult represents the result of a rollback
 type RollbackResult struct {
-	RollbackID    string    `json:"rollback_id"`
-	ActionID      string    `json:"action_id"`
-	Status        string    `json:"status"`
-	Reason        string    `json:"reason"`
-	ExecutedAt    time.Time `json:"executed_at"`
+	RollbackID       string            `json:"rollback_id"`
+	ActionID         string            `json:"action_id"`
+	RollbackActionID string            `json:"rollback_action_id"`
+	InverseType      models.ActionType `json:"inverse_type"`
+	Forced           bool              `json:"forced"`
+	Status           string            `json:"status"`
+	Reason           string            `json:"reason"`
+	ExecutedAt       time.Time         `json:"executed_at"`
 }
 
-// Rollback executes a rollback
+// Rollback executes the inverse of a completed action
 func (s *Service) Rollback(ctx context.Context, req *RollbackRequest) (*RollbackResult, error) {
 	if req.ActionID == "" {
 		return nil, fmt.Errorf("action_id is required")
 	}
-	if req.ServiceID == "" {
-		return nil, fmt.Errorf("service_id is required")
+	if s.actionStore == nil || s.actionService == nil {
+		return nil, fmt.Errorf("action store not available")
+	}
+
+	record, err := s.actionStore.GetByID(ctx, req.ActionID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ServiceID != "" && req.ServiceID != record.TargetService {
+		return nil, fmt.Errorf("action %s targets %s, not %s", req.ActionID, record.TargetService, req.ServiceID)
+	}
+	if record.Status != models.ActionStatusCompleted {
+		return nil, fmt.Errorf("action %s is %s; only completed actions can be rolled back", req.ActionID, record.Status)
+	}
+	if record.DryRun {
+		return nil, fmt.Errorf("action %s was a dry run; there is nothing to roll back", req.ActionID)
+	}
+	if err := s.checkNotRolledBack(ctx, req.ActionID); err != nil {
+		return nil, err
+	}
+
+	original, err := action.RequestFromRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	forced := false
+
+	inverse, err := s.actionService.Inverse(ctx, original)
+	if err != nil {
+		if !errors.Is(err, action.ErrNotInvertible) || !req.Force {
+			return nil, err
+		}
+		inverse = action.ForcedRollback(original)
+		forced = true
 	}
 
 	rollbackID := fmt.Sprintf("rbk-%d", time.Now().UnixNano())
+	inverse.ActionID = rollbackID
 
 	s.logger.Info("executing rollback",
 		"rollback_id", rollbackID,
 		"action_id", req.ActionID,
+		"inverse_type", inverse.ActionType,
 		"reason", req.Reason,
 		"force", req.Force,
 	)
 
-	// In a real implementation, this would:
-	// 1. Look up the original action
-	// 2. Execute the inverse action (e.g., scale_down if original was scale_up)
-	// 3. Record the rollback
+	actionResult, err := s.actionService.Execute(ctx, inverse)
+	if err != nil {
+		return nil, fmt.Errorf("inverse action failed: %w", err)
+	}
+
+	// Dry runs, kill-switch results and actions still awaiting approval or a
+	// rollout have not undone anything yet
+	if actionResult.Status == "completed" {
+		s.markRollbackExecuted(ctx, req)
+	}
 
 	return &RollbackResult{
-		RollbackID: rollbackID,
-		ActionID:   req.ActionID,
-		Status:     "executed",
-		Reason:     req.Reason,
-		ExecutedAt: time.Now(),
+		RollbackID:       rollbackID,
+		ActionID:         req.ActionID,
+		RollbackActionID: inverse.ActionID,
+		InverseType:      inverse.ActionType,
+		Forced:           forced,
+		Status:           actionResult.Status,
+		Reason:           req.Reason,
+		ExecutedAt:       actionResult.ExecutedAt,
 	}, nil
 }
 
+// checkNotRolledBack refuses a rollback when the action already has one that
+// has not failed, been cancelled or been rejected
+func (s *Service) checkNotRolledBack(ctx context.Context, actionID string) error {
+	rollbacks, err := s.actionStore.ListActions(ctx, models.ActionFilters{RollbackOf: actionID})
+	if err != nil {
+		return fmt.Errorf("failed to check earlier rollbacks: %w", err)
+	}
+	for _, rb := range rollbacks {
+		switch rb.Status {
+		case models.ActionStatusFailed, models.ActionStatusCancelled, models.ActionStatusRejected:
+		default:
+			return fmt.Errorf("action %s is already rolled back by %s (%s)", actionID, rb.ActionID, rb.Status)
+		}
+	}
+	return nil
+}
+
+// markRollbackExecuted flags the feedback that led to a rollback
+func (s *Service) markRollbackExecuted(ctx context.Context, req *RollbackRequest) {
+	if s.feedbackStore == nil {
+		return
+	}
+
+	feedbackID := req.FeedbackID
+	if feedbackID == "" {
+		records, err := s.feedbackStore.ListByFilters(ctx, models.FeedbackFilters{ActionID: req.ActionID, Limit: 1})
+		if err != nil || len(records) == 0 {
+			return
+		}
+		feedbackID = records[0].FeedbackID
+	}
+
+	if err := s.feedbackStore.MarkRollbackExecuted(ctx, feedbackID); err != nil {
+		s.logger.Warn("failed to mark rollback on feedback", "error", err, "feedback_id", feedbackID)
+	}
+}
+
+func toRecord(req *FeedbackRequest, result *FeedbackResult) *models.FeedbackRecord {
+	impact := result.ImpactScore
+	record := &models.FeedbackRecord{
+		FeedbackID:            result.FeedbackID,
+		ActionID:              result.ActionID,
+		DecisionID:            result.DecisionID,
+		ServiceID:             result.ServiceID,
+		FeedbackType:          models.FeedbackType(result.FeedbackType),
+		MetricsBefore:         mustMarshal(req.MetricsBefore),
+		MetricsAfter:          mustMarshal(req.MetricsAfter),
+		ImpactScore:           &impact,
+		DriftDetected:         result.DriftDetected,
+		RollbackRecommended:   result.RollbackRecommended,
+		RollbackExecuted:      result.RollbackExecuted,
+		ObservationWindowMins: req.ObservationWindowMins,
+		RecordedAt:            result.RecordedAt,
+	}
+	if result.DriftDetected {
+		record.DriftDetails = mustMarshal(result.DriftDetails)
+	}
+	return record
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
 func (s *Service) validateRequest(req *FeedbackRequest) error {
 	if req.ActionID == "" {
 		return fmt.Errorf("action_id is required")
@@ -177,6 +432,11 @@ func (s *Service) validateRequest(req *FeedbackRequest) error {
 	if len(req.MetricsAfter) == 0 {
 		return fmt.Errorf("metrics_after is required")
 	}
+	switch models.FeedbackType(req.FeedbackType) {
+	case "", models.FeedbackTypeImmediate, models.FeedbackTypeDelayed, models.FeedbackTypeScheduled:
+	default:
+		return fmt.Errorf("invalid feedback_type: %s", req.FeedbackType)
+	}
 	return nil
 }
 
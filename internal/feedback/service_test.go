@@ -0,0 +1,65 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFeedbackDefaultsType(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil)
+
+	req := QuickFeedback("act-1", "dec-1", "checkout",
+		map[string]float64{"cpu": 90, "latency": 400},
+		map[string]float64{"cpu": 50, "latency": 200},
+	)
+	req.FeedbackType = ""
+
+	result, err := svc.RecordFeedback(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "immediate", result.FeedbackType)
+	assert.Greater(t, result.ImpactScore, 0.0)
+}
+
+func TestRecordFeedbackRejectsUnknownType(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil)
+
+	req := QuickFeedback("act-1", "dec-1", "checkout",
+		map[string]float64{"cpu": 90},
+		map[string]float64{"cpu": 50},
+	)
+	req.FeedbackType = "eventually"
+
+	_, err := svc.RecordFeedback(context.Background(), req)
+	assert.Error(t, err)
+}
+
+func TestToRecord(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil)
+
+	req := QuickFeedback("act-1", "dec-1", "checkout",
+		map[string]float64{"error_rate": 0.01, "latency": 100},
+		map[string]float64{"error_rate": 0.2, "latency": 300},
+	)
+	result, err := svc.RecordFeedback(context.Background(), req)
+	require.NoError(t, err)
+
+	record := toRecord(req, result)
+	assert.Equal(t, result.FeedbackID, record.FeedbackID)
+	assert.Equal(t, models.FeedbackTypeImmediate, record.FeedbackType)
+	require.NotNil(t, record.ImpactScore)
+	assert.Equal(t, result.ImpactScore, *record.ImpactScore)
+	assert.True(t, record.DriftDetected)
+
+	var drift DriftDetails
+	require.NoError(t, json.Unmarshal(record.DriftDetails, &drift))
+	assert.Equal(t, "error_drift", drift.DriftType)
+
+	var before map[string]float64
+	require.NoError(t, json.Unmarshal(record.MetricsBefore, &before))
+	assert.Equal(t, 0.01, before["error_rate"])
+}
@@ -0,0 +1,603 @@
+// Package guardrail bounds the blast radius of actions: how many instances
+// may be added per hour, the replica range of a service, how much actions may
+// cost per day and how many services may have an open circuit at once. A
+// kill switch turns every action into a dry run, and freeze windows block
+// every action while they are open.
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the guardrails, as reported in violations
+const (
+	MaxInstancesPerHour = "max_instances_per_hour"
+	MinReplicas         = "min_replicas"
+	MaxReplicas         = "max_replicas"
+	DailyCostBudget     = "daily_cost_budget"
+	MaxOpenCircuits     = "max_open_circuits"
+	KillSwitch          = "kill_switch"
+	FreezeWindow        = "freeze_window"
+	UsageUnavailable    = "usage_unavailable"
+)
+
+// usageTimeout bounds the lookup of the usage recorded in the store
+const usageTimeout = 5 * time.Second
+
+// ErrBlocked matches every Violation
+var ErrBlocked = errors.New("blocked by guardrail")
+
+// Limits bound the actions on a service, or on all services together. Zero
+// means no limit.
+type Limits struct {
+	MaxInstancesPerHour int     `yaml:"max_instances_per_hour,omitempty" json:"max_instances_per_hour,omitempty"`
+	MinReplicas         int     `yaml:"min_replicas,omitempty" json:"min_replicas,omitempty"`
+	MaxReplicas         int     `yaml:"max_replicas,omitempty" json:"max_replicas,omitempty"`
+	DailyCostBudget     float64 `yaml:"daily_cost_budget,omitempty" json:"daily_cost_budget,omitempty"`
+}
+
+// Config holds the guardrails. Global instance and cost limits apply to the
+// sum over all services; global replica bounds apply to every service that
+// does not set its own.
+type Config struct {
+	Global          Limits            `yaml:"global" json:"global"`
+	Services        map[string]Limits `yaml:"services,omitempty" json:"services,omitempty"`
+	MaxOpenCircuits int               `yaml:"max_open_circuits,omitempty" json:"max_open_circuits,omitempty"`
+}
+
+// Validate validates the guardrail configuration
+func (c *Config) Validate() error {
+	check := func(scope string, l Limits) error {
+		if l.MaxInstancesPerHour < 0 || l.MinReplicas < 0 || l.MaxReplicas < 0 || l.DailyCostBudget < 0 {
+			return fmt.Errorf("guardrails %s: limits cannot be negative", scope)
+		}
+		if l.MaxReplicas > 0 && l.MinReplicas > l.MaxReplicas {
+			return fmt.Errorf("guardrails %s: min_replicas %d above max_replicas %d", scope, l.MinReplicas, l.MaxReplicas)
+		}
+		return nil
+	}
+	if err := check("global", c.Global); err != nil {
+		return err
+	}
+	for service, l := range c.Services {
+		if err := check(service, l); err != nil {
+			return err
+		}
+	}
+	if c.MaxOpenCircuits < 0 {
+		return fmt.Errorf("guardrails: max_open_circuits cannot be negative")
+	}
+	return nil
+}
+
+// LoadConfig reads guardrails from a YAML file with a top-level "guardrails" key
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read guardrails: %w", err)
+	}
+
+	var file struct {
+		Guardrails Config `yaml:"guardrails"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse guardrails: %w", err)
+	}
+	if err := file.Guardrails.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &file.Guardrails, nil
+}
+
+// FreezeCalendar reports the freeze window, if any, a time falls in
+type FreezeCalendar interface {
+	Frozen(at time.Time) (string, bool)
+}
+
+// Violation names the guardrail that blocked an action
+type Violation struct {
+	Guardrail string            `json:"guardrail"`
+	Scope     string            `json:"scope"` // "global" or the service the limit is set for
+	Service   string            `json:"service"`
+	Action    models.ActionType `json:"action"`
+	Reason    string            `json:"reason"`
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("guardrail %s (%s) blocked %s on %s: %s", v.Guardrail, v.Scope, v.Action, v.Service, v.Reason)
+}
+
+// Is makes errors.Is(v, ErrBlocked) true
+func (v *Violation) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+// Request describes an action for the guardrails
+type Request struct {
+	ActionID  string // the action's own reservation is not counted against it
+	Rollback  bool   // restores an earlier state: not limited, only its circuit change counts
+	Service   string
+	Type      models.ActionType
+	Instances int  // instances a scale_up adds
+	Replicas  *int // replica count the action sets outright, if any
+	Cost      float64
+}
+
+// NewRequest describes an action from its payload. A scale_up adds
+// "instances" (default 1) unless it sets "replicas" outright, in which case
+// only the replica bounds apply to it.
+func NewRequest(service string, actionType models.ActionType, payload map[string]interface{}, cost float64) Request {
+	r := Request{Service: service, Type: actionType, Cost: cost}
+	if n, ok := number(payload["replicas"]); ok {
+		r.Replicas = &n
+	} else if actionType == models.ActionTypeScaleUp {
+		r.Instances = 1
+		if n, ok := number(payload["instances"]); ok {
+			r.Instances = n
+		}
+	}
+	return r
+}
+
+func number(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(math.Round(n)), true
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// usage is an amount taken from a budget at a point in time
+type usage struct {
+	service string
+	at      time.Time
+	amount  float64
+}
+
+// ledger is the usage per service actions are checked against
+type ledger struct {
+	instances    map[string]int     // added in the last hour
+	spend        map[string]float64 // spent in the last 24h
+	openCircuits map[string]bool
+}
+
+func ledgerOf(t *models.UsageTotals) *ledger {
+	l := &ledger{instances: t.Instances, spend: t.Spend, openCircuits: make(map[string]bool, len(t.OpenCircuits))}
+	for _, service := range t.OpenCircuits {
+		l.openCircuits[service] = true
+	}
+	return l
+}
+
+// UsageStore records the usage reserved by the actions that passed the
+// guardrails on every replica; postgres.GuardrailStore implements it
+type UsageStore interface {
+	Usage(ctx context.Context, instancesSince, spendSince time.Time, exclude string) (*models.UsageTotals, error)
+	ReserveUsage(ctx context.Context, u *models.GuardrailUsage, instancesSince, spendSince time.Time, check func(*models.UsageTotals) error) error
+	ReleaseUsage(ctx context.Context, actionID string) error
+}
+
+// Guardrails checks actions against the configured limits and keeps track of
+// what they used. With a UsageStore an action is checked and its usage
+// reserved in one step under a lock every replica takes, so the limits hold
+// across replicas and restarts; without one the usage is kept in memory and
+// each process enforces the limits on the actions it runs.
+type Guardrails struct {
+	mu           sync.Mutex
+	config       Config
+	store        UsageStore
+	instances    []usage
+	spend        []usage
+	openCircuits map[string]bool
+	killSwitch   atomic.Bool
+	freeze       FreezeCalendar
+	now          func() time.Time
+}
+
+// New creates guardrails from config
+func New(config Config) *Guardrails {
+	return &Guardrails{
+		config:       config,
+		openCircuits: make(map[string]bool),
+		now:          time.Now,
+	}
+}
+
+// SetKillSwitch turns every action into a dry run while on
+func (g *Guardrails) SetKillSwitch(on bool) {
+	g.killSwitch.Store(on)
+}
+
+// KillSwitch reports whether the kill switch is on
+func (g *Guardrails) KillSwitch() bool {
+	return g.killSwitch.Load()
+}
+
+// SetFreezeCalendar blocks every action while one of the calendar's freeze
+// windows is open
+func (g *Guardrails) SetFreezeCalendar(c FreezeCalendar) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.freeze = c
+}
+
+// SetUsageStore reserves usage in store, shared by every replica, instead of
+// in this process
+func (g *Guardrails) SetUsageStore(store UsageStore) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.store = store
+}
+
+// Bounds returns the replica range of a service, zero meaning unbounded
+func (g *Guardrails) Bounds(service string) (min, max int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.bounds(service)
+}
+
+func (g *Guardrails) bounds(service string) (min, max int) {
+	min, max = g.config.Global.MinReplicas, g.config.Global.MaxReplicas
+	if l, ok := g.config.Services[service]; ok {
+		if l.MinReplicas > 0 {
+			min = l.MinReplicas
+		}
+		if l.MaxReplicas > 0 {
+			max = l.MaxReplicas
+		}
+	}
+	return min, max
+}
+
+// Check returns the violation r would cause, or nil
+func (g *Guardrails) Check(r Request) *Violation {
+	stored, err := g.storedLedger(r.ActionID)
+	if err != nil {
+		return unavailable(r, err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if stored != nil {
+		return g.check(r, stored)
+	}
+	return g.check(r, g.localLedger())
+}
+
+// Reserve checks r and, when it passes, counts it against the limits. The
+// returned func gives the reservation back, for actions that then fail.
+// Rollbacks are not checked, and only their circuit change is counted.
+func (g *Guardrails) Reserve(r Request) (func(), error) {
+	g.mu.Lock()
+	store := g.store
+	g.mu.Unlock()
+	if store != nil {
+		return g.reserveStored(store, r)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	l := g.localLedger()
+	if r.Rollback {
+		r.Instances, r.Cost = 0, 0
+	} else if v := g.check(r, l); v != nil {
+		return nil, v
+	}
+
+	now := g.now()
+	var undo []func()
+	if r.Instances > 0 {
+		u := usage{service: r.Service, at: now, amount: float64(r.Instances)}
+		g.instances = append(g.instances, u)
+		undo = append(undo, func() { g.instances = remove(g.instances, u) })
+	}
+	if r.Cost > 0 {
+		u := usage{service: r.Service, at: now, amount: r.Cost}
+		g.spend = append(g.spend, u)
+		undo = append(undo, func() { g.spend = remove(g.spend, u) })
+	}
+	switch r.Type {
+	case models.ActionTypeOpenCircuit:
+		if !g.openCircuits[r.Service] {
+			g.openCircuits[r.Service] = true
+			undo = append(undo, func() { delete(g.openCircuits, r.Service) })
+		}
+	case models.ActionTypeCloseCircuit:
+		if g.openCircuits[r.Service] {
+			delete(g.openCircuits, r.Service)
+			undo = append(undo, func() { g.openCircuits[r.Service] = true })
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			for _, u := range undo {
+				u()
+			}
+		})
+	}, nil
+}
+
+// reserveStored checks r against the usage in store and records its usage
+// in the same transaction. An action with no usage to record is only checked.
+func (g *Guardrails) reserveStored(store UsageStore, r Request) (func(), error) {
+	now := g.now()
+	u := &models.GuardrailUsage{ActionID: r.ActionID, ServiceID: r.Service, ReservedAt: now}
+	if !r.Rollback {
+		u.Instances, u.Cost = r.Instances, r.Cost
+	}
+	switch r.Type {
+	case models.ActionTypeOpenCircuit:
+		u.Circuit = models.CircuitOpen
+	case models.ActionTypeCloseCircuit:
+		u.Circuit = models.CircuitClose
+	}
+	if u.Instances == 0 && u.Cost == 0 && u.Circuit == "" {
+		if !r.Rollback {
+			if v := g.Check(r); v != nil {
+				return nil, v
+			}
+		}
+		return func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), usageTimeout)
+	defer cancel()
+
+	var violation *Violation
+	err := store.ReserveUsage(ctx, u, now.Add(-time.Hour), now.Add(-24*time.Hour), func(t *models.UsageTotals) error {
+		if r.Rollback {
+			return nil
+		}
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if violation = g.check(r, ledgerOf(t)); violation != nil {
+			return violation
+		}
+		return nil
+	})
+	if violation != nil {
+		return nil, violation
+	}
+	if err != nil {
+		return nil, unavailable(r, err)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), usageTimeout)
+			defer cancel()
+			if err := store.ReleaseUsage(ctx, r.ActionID); err != nil {
+				slog.Warn("failed to give back guardrail usage", "action_id", r.ActionID, "error", err)
+			}
+		})
+	}, nil
+}
+
+// localLedger prunes the usage reserved in this process and sums it. The
+// caller holds g.mu.
+func (g *Guardrails) localLedger() *ledger {
+	now := g.now()
+	g.instances = prune(g.instances, now.Add(-time.Hour))
+	g.spend = prune(g.spend, now.Add(-24*time.Hour))
+
+	l := &ledger{instances: make(map[string]int), spend: make(map[string]float64), openCircuits: g.openCircuits}
+	for _, u := range g.instances {
+		l.instances[u.service] += int(u.amount)
+	}
+	for _, u := range g.spend {
+		l.spend[u.service] += u.amount
+	}
+	return l
+}
+
+// storedLedger sums the usage reserved in the store, leaving out the action
+// exclude. It returns nil without a store.
+func (g *Guardrails) storedLedger(exclude string) (*ledger, error) {
+	g.mu.Lock()
+	store, now := g.store, g.now()
+	g.mu.Unlock()
+	if store == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), usageTimeout)
+	defer cancel()
+
+	totals, err := store.Usage(ctx, now.Add(-time.Hour), now.Add(-24*time.Hour), exclude)
+	if err != nil {
+		return nil, err
+	}
+	return ledgerOf(totals), nil
+}
+
+// unavailable blocks r when its usage cannot be counted
+func unavailable(r Request, err error) *Violation {
+	return &Violation{
+		Guardrail: UsageUnavailable,
+		Scope:     "global",
+		Service:   r.Service,
+		Action:    r.Type,
+		Reason:    "failed to load action usage: " + err.Error(),
+	}
+}
+
+func (g *Guardrails) check(r Request, l *ledger) *Violation {
+	now := g.now()
+	service := g.config.Services[r.Service]
+	violation := func(guardrail, scope, reason string, args ...interface{}) *Violation {
+		return &Violation{
+			Guardrail: guardrail,
+			Scope:     scope,
+			Service:   r.Service,
+			Action:    r.Type,
+			Reason:    fmt.Sprintf(reason, args...),
+		}
+	}
+	scope := func(serviceLimit int) string {
+		if serviceLimit > 0 {
+			return r.Service
+		}
+		return "global"
+	}
+
+	if g.freeze != nil {
+		if name, frozen := g.freeze.Frozen(now); frozen {
+			return violation(FreezeWindow, "global", "actions are frozen during %s", name)
+		}
+	}
+
+	if r.Replicas != nil {
+		min, max := g.bounds(r.Service)
+		if min > 0 && *r.Replicas < min {
+			return violation(MinReplicas, scope(service.MinReplicas),
+				"%d replicas is below the minimum of %d", *r.Replicas, min)
+		}
+		if max > 0 && *r.Replicas > max {
+			return violation(MaxReplicas, scope(service.MaxReplicas),
+				"%d replicas is above the maximum of %d", *r.Replicas, max)
+		}
+	}
+
+	if r.Instances > 0 {
+		if limit := service.MaxInstancesPerHour; limit > 0 {
+			if used := l.instances[r.Service]; used+r.Instances > limit {
+				return violation(MaxInstancesPerHour, r.Service,
+					"%d instances added in the last hour, adding %d exceeds the limit of %d", used, r.Instances, limit)
+			}
+		}
+		if limit := g.config.Global.MaxInstancesPerHour; limit > 0 {
+			if used := total(l.instances); used+r.Instances > limit {
+				return violation(MaxInstancesPerHour, "global",
+					"%d instances added in the last hour, adding %d exceeds the limit of %d", used, r.Instances, limit)
+			}
+		}
+	}
+
+	if r.Cost > 0 {
+		if budget := service.DailyCostBudget; budget > 0 {
+			if spent := l.spend[r.Service]; spent+r.Cost > budget {
+				return violation(DailyCostBudget, r.Service,
+					"%.2f spent in the last 24h, %.2f more exceeds the budget of %.2f", spent, r.Cost, budget)
+			}
+		}
+		if budget := g.config.Global.DailyCostBudget; budget > 0 {
+			if spent := total(l.spend); spent+r.Cost > budget {
+				return violation(DailyCostBudget, "global",
+					"%.2f spent in the last 24h, %.2f more exceeds the budget of %.2f", spent, r.Cost, budget)
+			}
+		}
+	}
+
+	if r.Type == models.ActionTypeOpenCircuit && g.config.MaxOpenCircuits > 0 && !l.openCircuits[r.Service] {
+		if len(l.openCircuits) >= g.config.MaxOpenCircuits {
+			return violation(MaxOpenCircuits, "global",
+				"%d services already have an open circuit, the limit is %d", len(l.openCircuits), g.config.MaxOpenCircuits)
+		}
+	}
+
+	return nil
+}
+
+// prune drops usage older than since
+func prune(usages []usage, since time.Time) []usage {
+	kept := usages[:0]
+	for _, u := range usages {
+		if u.at.After(since) {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+// total adds up the usage of every service
+func total[T int | float64](usage map[string]T) T {
+	var sum T
+	for _, used := range usage {
+		sum += used
+	}
+	return sum
+}
+
+func remove(usages []usage, u usage) []usage {
+	for i := range usages {
+		if usages[i] == u {
+			return append(usages[:i], usages[i+1:]...)
+		}
+	}
+	return usages
+}
+
+// Status is a snapshot of the guardrails and what has been used
+type Status struct {
+	Config            Config             `json:"config"`
+	KillSwitch        bool               `json:"kill_switch"`
+	FrozenBy          string             `json:"frozen_by,omitempty"` // the open freeze window, if any
+	InstancesLastHour map[string]int     `json:"instances_last_hour"`
+	SpendLast24h      map[string]float64 `json:"spend_last_24h"`
+	OpenCircuits      []string           `json:"open_circuits"`
+}
+
+// Status returns the guardrails and their current usage per service
+func (g *Guardrails) Status() (*Status, error) {
+	stored, err := g.storedLedger("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load action usage: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	l := stored
+	if l == nil {
+		l = g.localLedger()
+	}
+
+	status := &Status{
+		Config:            g.config,
+		KillSwitch:        g.KillSwitch(),
+		InstancesLastHour: make(map[string]int),
+		SpendLast24h:      make(map[string]float64),
+		OpenCircuits:      []string{},
+	}
+	for service, used := range l.instances {
+		status.InstancesLastHour[service] = used
+	}
+	for service, spent := range l.spend {
+		status.SpendLast24h[service] = spent
+	}
+	for service := range l.openCircuits {
+		status.OpenCircuits = append(status.OpenCircuits, service)
+	}
+	sort.Strings(status.OpenCircuits)
+	if g.freeze != nil {
+		if name, frozen := g.freeze.Frozen(now); frozen {
+			status.FrozenBy = name
+		}
+	}
+
+	return status, nil
+}
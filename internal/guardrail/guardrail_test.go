@@ -0,0 +1,329 @@
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scaleUp(service string, instances int) Request {
+	return NewRequest(service, models.ActionTypeScaleUp, map[string]interface{}{"instances": float64(instances)}, 0)
+}
+
+func status(t *testing.T, g *Guardrails) *Status {
+	t.Helper()
+	s, err := g.Status()
+	require.NoError(t, err)
+	return s
+}
+
+func TestInstancesPerHour(t *testing.T) {
+	g := New(Config{
+		Global:   Limits{MaxInstancesPerHour: 10},
+		Services: map[string]Limits{"checkout": {MaxInstancesPerHour: 4}},
+	})
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	_, err := g.Reserve(scaleUp("checkout", 3))
+	require.NoError(t, err)
+
+	_, err = g.Reserve(scaleUp("checkout", 2))
+	var v *Violation
+	require.ErrorAs(t, err, &v)
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.Equal(t, MaxInstancesPerHour, v.Guardrail)
+	assert.Equal(t, "checkout", v.Scope)
+
+	// The global limit covers every service
+	_, err = g.Reserve(scaleUp("payments", 8))
+	require.ErrorAs(t, err, &v)
+	assert.Equal(t, "global", v.Scope)
+	_, err = g.Reserve(scaleUp("payments", 7))
+	require.NoError(t, err)
+
+	// Usage older than an hour no longer counts
+	now = now.Add(61 * time.Minute)
+	_, err = g.Reserve(scaleUp("checkout", 4))
+	assert.NoError(t, err)
+}
+
+func TestReserveGivesBack(t *testing.T) {
+	g := New(Config{Global: Limits{DailyCostBudget: 100}})
+
+	giveBack, err := g.Reserve(NewRequest("checkout", models.ActionTypeScaleUp, nil, 80))
+	require.NoError(t, err)
+	assert.NotNil(t, g.Check(NewRequest("checkout", models.ActionTypeScaleUp, nil, 30)))
+
+	giveBack()
+	giveBack()
+	assert.Nil(t, g.Check(NewRequest("checkout", models.ActionTypeScaleUp, nil, 30)))
+	assert.Empty(t, status(t, g).SpendLast24h)
+}
+
+func TestReplicaBounds(t *testing.T) {
+	g := New(Config{
+		Global:   Limits{MinReplicas: 2, MaxReplicas: 50},
+		Services: map[string]Limits{"checkout": {MaxReplicas: 10}},
+	})
+
+	min, max := g.Bounds("checkout")
+	assert.Equal(t, 2, min)
+	assert.Equal(t, 10, max)
+
+	v := g.Check(NewRequest("checkout", models.ActionTypeScaleUp, map[string]interface{}{"replicas": float64(12)}, 0))
+	require.NotNil(t, v)
+	assert.Equal(t, MaxReplicas, v.Guardrail)
+	assert.Equal(t, "checkout", v.Scope)
+
+	v = g.Check(NewRequest("payments", models.ActionTypeScaleDown, map[string]interface{}{"replicas": float64(1)}, 0))
+	require.NotNil(t, v)
+	assert.Equal(t, MinReplicas, v.Guardrail)
+	assert.Equal(t, "global", v.Scope)
+}
+
+func TestMaxOpenCircuits(t *testing.T) {
+	g := New(Config{MaxOpenCircuits: 1})
+	open := func(service string) Request { return NewRequest(service, models.ActionTypeOpenCircuit, nil, 0) }
+
+	_, err := g.Reserve(open("checkout"))
+	require.NoError(t, err)
+	// Opening an already open circuit does not count twice
+	_, err = g.Reserve(open("checkout"))
+	require.NoError(t, err)
+
+	_, err = g.Reserve(open("payments"))
+	assert.ErrorIs(t, err, ErrBlocked)
+
+	_, err = g.Reserve(NewRequest("checkout", models.ActionTypeCloseCircuit, nil, 0))
+	require.NoError(t, err)
+	_, err = g.Reserve(open("payments"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, status(t, g).OpenCircuits)
+}
+
+// freezeUntil is frozen before a time
+type freezeUntil time.Time
+
+func (f freezeUntil) Frozen(at time.Time) (string, bool) {
+	return "release-freeze", at.Before(time.Time(f))
+}
+
+func TestFreezeWindow(t *testing.T) {
+	g := New(Config{})
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	g.SetFreezeCalendar(freezeUntil(now.Add(time.Hour)))
+
+	_, err := g.Reserve(scaleUp("checkout", 1))
+	var v *Violation
+	require.ErrorAs(t, err, &v)
+	assert.Equal(t, FreezeWindow, v.Guardrail)
+	assert.Equal(t, "global", v.Scope)
+	assert.Equal(t, "release-freeze", status(t, g).FrozenBy)
+
+	now = now.Add(2 * time.Hour)
+	_, err = g.Reserve(scaleUp("checkout", 1))
+	assert.NoError(t, err)
+	assert.Empty(t, status(t, g).FrozenBy)
+}
+
+// fakeUsageStore keeps reservations in memory, reserving one at a time
+type fakeUsageStore struct {
+	mu    sync.Mutex
+	usage map[string]*models.GuardrailUsage
+	err   error
+}
+
+func (f *fakeUsageStore) Usage(ctx context.Context, instancesSince, spendSince time.Time, exclude string) (*models.UsageTotals, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.totals(instancesSince, spendSince, exclude)
+}
+
+func (f *fakeUsageStore) totals(instancesSince, spendSince time.Time, exclude string) (*models.UsageTotals, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	t := &models.UsageTotals{Instances: map[string]int{}, Spend: map[string]float64{}}
+	latest := map[string]*models.GuardrailUsage{}
+	for id, u := range f.usage {
+		if id == exclude {
+			continue
+		}
+		if u.Instances > 0 && !u.ReservedAt.Before(instancesSince) {
+			t.Instances[u.ServiceID] += u.Instances
+		}
+		if u.Cost > 0 && !u.ReservedAt.Before(spendSince) {
+			t.Spend[u.ServiceID] += u.Cost
+		}
+		if l := latest[u.ServiceID]; u.Circuit != "" && (l == nil || u.ReservedAt.After(l.ReservedAt)) {
+			latest[u.ServiceID] = u
+		}
+	}
+	for service, u := range latest {
+		if u.Circuit == models.CircuitOpen {
+			t.OpenCircuits = append(t.OpenCircuits, service)
+		}
+	}
+	sort.Strings(t.OpenCircuits)
+	return t, nil
+}
+
+func (f *fakeUsageStore) ReserveUsage(ctx context.Context, u *models.GuardrailUsage, instancesSince, spendSince time.Time, check func(*models.UsageTotals) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.totals(instancesSince, spendSince, u.ActionID)
+	if err != nil {
+		return err
+	}
+	if err := check(t); err != nil {
+		return err
+	}
+	f.usage[u.ActionID] = u
+	return nil
+}
+
+func (f *fakeUsageStore) ReleaseUsage(ctx context.Context, actionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.usage, actionID)
+	return nil
+}
+
+func TestUsageStore(t *testing.T) {
+	g := New(Config{
+		Global:          Limits{DailyCostBudget: 100},
+		Services:        map[string]Limits{"checkout": {MaxInstancesPerHour: 4}},
+		MaxOpenCircuits: 1,
+	})
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	store := &fakeUsageStore{usage: map[string]*models.GuardrailUsage{
+		"act-0": {ActionID: "act-0", ServiceID: "checkout", Instances: 3, ReservedAt: now.Add(-2 * time.Hour)},
+	}}
+	g.SetUsageStore(store)
+
+	request := func(id string, instances int, cost float64) Request {
+		r := NewRequest("checkout", models.ActionTypeScaleUp, map[string]interface{}{"instances": float64(instances)}, cost)
+		r.ActionID = id
+		return r
+	}
+
+	// Of two actions that only fit one at a time, exactly one is reserved
+	var wg sync.WaitGroup
+	results := make([]error, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i] = g.Reserve(request(fmt.Sprintf("act-%d", i+1), 3, 60))
+		}(i)
+	}
+	wg.Wait()
+	passed := 0
+	for _, err := range results {
+		if err == nil {
+			passed++
+		} else {
+			assert.ErrorIs(t, err, ErrBlocked)
+		}
+	}
+	assert.Equal(t, 1, passed)
+	assert.Len(t, store.usage, 2)
+
+	// An action's own reservation does not count against it
+	reserved := "act-1"
+	if results[0] != nil {
+		reserved = "act-2"
+	}
+	v := g.Check(request("act-3", 2, 0))
+	require.NotNil(t, v)
+	assert.Equal(t, MaxInstancesPerHour, v.Guardrail)
+	assert.Nil(t, g.Check(request(reserved, 3, 60)))
+
+	s := status(t, g)
+	assert.Equal(t, map[string]int{"checkout": 3}, s.InstancesLastHour)
+	assert.Equal(t, map[string]float64{"checkout": 60}, s.SpendLast24h)
+
+	// Giving back a reservation frees its usage
+	giveBack, err := g.Reserve(request("act-4", 0, 30))
+	require.NoError(t, err)
+	_, err = g.Reserve(request("act-5", 0, 30))
+	assert.ErrorIs(t, err, ErrBlocked)
+	giveBack()
+	_, err = g.Reserve(request("act-5", 0, 30))
+	assert.NoError(t, err)
+
+	// Rollbacks are not limited and only their circuit change counts
+	open := NewRequest("payments", models.ActionTypeOpenCircuit, nil, 0)
+	open.ActionID = "act-6"
+	_, err = g.Reserve(open)
+	require.NoError(t, err)
+	search := NewRequest("search", models.ActionTypeOpenCircuit, nil, 0)
+	search.ActionID = "act-7"
+	_, err = g.Reserve(search)
+	require.ErrorAs(t, err, &v)
+	assert.Equal(t, MaxOpenCircuits, v.Guardrail)
+
+	now = now.Add(time.Minute)
+	rollback := NewRequest("payments", models.ActionTypeCloseCircuit, nil, 500)
+	rollback.ActionID, rollback.Rollback = "rbk-6", true
+	_, err = g.Reserve(rollback)
+	require.NoError(t, err)
+	assert.Zero(t, store.usage["rbk-6"].Cost)
+	_, err = g.Reserve(search)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"search"}, status(t, g).OpenCircuits)
+
+	// Without its usage an action is blocked
+	store.err = errors.New("connection refused")
+	v = g.Check(request("act-8", 1, 0))
+	require.NotNil(t, v)
+	assert.Equal(t, UsageUnavailable, v.Guardrail)
+	_, err = g.Reserve(request("act-8", 1, 0))
+	require.ErrorAs(t, err, &v)
+	assert.Equal(t, UsageUnavailable, v.Guardrail)
+	_, err = g.Status()
+	assert.Error(t, err)
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guardrails.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+guardrails:
+  max_open_circuits: 2
+  global:
+    daily_cost_budget: 500
+  services:
+    checkout:
+      max_instances_per_hour: 5
+      max_replicas: 20
+`), 0o644))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, config.MaxOpenCircuits)
+	assert.Equal(t, 500.0, config.Global.DailyCostBudget)
+	assert.Equal(t, Limits{MaxInstancesPerHour: 5, MaxReplicas: 20}, config.Services["checkout"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+guardrails:
+  services:
+    checkout:
+      min_replicas: 5
+      max_replicas: 2
+`), 0o644))
+	_, err = LoadConfig(path)
+	assert.Error(t, err)
+}
@@ -3,7 +3,11 @@ package ingest
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/models"
@@ -11,91 +15,261 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-// Consumer consumes events from Kafka
+// MessageReader reads and commits messages of a consumer group;
+// *kafka.Reader implements it
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Stats() kafka.ReaderStats
+	Close() error
+}
+
+// BatchStore persists events in one transaction, skipping events already
+// stored; postgres.EventStore implements it
+type BatchStore interface {
+	StoreBatch(ctx context.Context, events []*models.Event) error
+}
+
+// ConsumerConfig holds the consumer configuration
+type ConsumerConfig struct {
+	BatchSize    int           // most events written per transaction
+	BatchTimeout time.Duration // longest wait to fill a batch after its first message
+	BaseBackoff  time.Duration // first wait before retrying a failed write
+	MaxBackoff   time.Duration
+}
+
+// DefaultConsumerConfig returns default configuration
+func DefaultConsumerConfig() ConsumerConfig {
+	return ConsumerConfig{
+		BatchSize:    100,
+		BatchTimeout: time.Second,
+		BaseBackoff:  500 * time.Millisecond,
+		MaxBackoff:   30 * time.Second,
+	}
+}
+
+// Consumer writes the events published in log-first mode to the database.
+// It commits a batch's offsets only once the batch is stored, so an event
+// is redelivered rather than lost when a write fails or the consumer stops,
+// and the store skips events it already has.
 type Consumer struct {
-	reader      *kafka.Reader
-	eventStore  *postgres.EventStore
-	logger      *slog.Logger
-	stopChan    chan struct{}
+	reader MessageReader
+	store  BatchStore
+	config ConsumerConfig
+	logger *slog.Logger
+
+	mu           sync.Mutex
+	stopFetching context.CancelFunc
+	abortWrites  context.CancelFunc
+	done         chan struct{}
+
+	stored         atomic.Int64
+	invalid        atomic.Int64
+	rejected       atomic.Int64
+	storeFailures  atomic.Int64
+	commitFailures atomic.Int64
 }
 
 // NewConsumer creates a new Kafka consumer
-func NewConsumer(reader *kafka.Reader, eventStore *postgres.EventStore, logger *slog.Logger) *Consumer {
+func NewConsumer(reader MessageReader, store BatchStore, config ConsumerConfig, logger *slog.Logger) *Consumer {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	defaults := DefaultConsumerConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = defaults.BatchTimeout
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = defaults.BaseBackoff
+	}
+	if config.MaxBackoff < config.BaseBackoff {
+		config.MaxBackoff = config.BaseBackoff
+	}
 	return &Consumer{
-		reader:     reader,
-		eventStore: eventStore,
-		logger:     logger,
-		stopChan:   make(chan struct{}),
+		reader: reader,
+		store:  store,
+		config: config,
+		logger: logger,
+		done:   make(chan struct{}),
 	}
 }
 
-// Start begins consuming messages
+// Start consumes messages until ctx is cancelled or Stop is called. The batch
+// being fetched when it stops is still written and committed.
 func (c *Consumer) Start(ctx context.Context) error {
-	c.logger.Info("starting kafka consumer")
-	
-	for {
+	fetchCtx, stopFetching := context.WithCancel(ctx)
+	writeCtx, abortWrites := context.WithCancel(context.WithoutCancel(ctx))
+	c.mu.Lock()
+	c.stopFetching, c.abortWrites = stopFetching, abortWrites
+	c.mu.Unlock()
+	defer close(c.done)
+	defer abortWrites()
+	defer stopFetching()
+
+	c.logger.Info("starting kafka consumer", "batch_size", c.config.BatchSize, "batch_timeout", c.config.BatchTimeout)
+
+	for fetchCtx.Err() == nil {
+		batch, err := c.fetchBatch(fetchCtx)
+		if len(batch) > 0 {
+			if err := c.flush(fetchCtx, writeCtx, batch); err != nil {
+				c.logger.Warn("batch left uncommitted for redelivery", "error", err, "messages", len(batch))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	c.logger.Info("kafka consumer stopped")
+	return nil
+}
+
+// Stop stops fetching and waits for the current batch to be written and
+// committed. When ctx ends first the write is aborted and the batch is
+// redelivered on restart.
+func (c *Consumer) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	stopFetching, abortWrites := c.stopFetching, c.abortWrites
+	c.mu.Unlock()
+
+	if stopFetching != nil {
+		stopFetching()
 		select {
+		case <-c.done:
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-c.stopChan:
-			return nil
-		default:
+			abortWrites()
+			<-c.done
 		}
+	}
+	return c.reader.Close()
+}
 
-		msg, err := c.reader.ReadMessage(ctx)
+// fetchBatch fetches messages until the batch is full or BatchTimeout has
+// passed since its first message. It returns an error only when the reader
+// is closed.
+func (c *Consumer) fetchBatch(ctx context.Context) ([]kafka.Message, error) {
+	var batch []kafka.Message
+	fetchCtx := ctx
+	for len(batch) < c.config.BatchSize {
+		msg, err := c.reader.FetchMessage(fetchCtx)
 		if err != nil {
-			if ctx.Err() != nil {
-				return ctx.Err()
+			if fetchCtx.Err() != nil {
+				return batch, nil
 			}
-			c.logger.Error("failed to read message", "error", err)
-			time.Sleep(time.Second)
-			continue
+			if errors.Is(err, io.EOF) {
+				return batch, err
+			}
+			c.logger.Error("failed to fetch message", "error", err)
+			if len(batch) == 0 {
+				sleep(ctx, time.Second)
+			}
+			return batch, nil
 		}
 
-		if err := c.processMessage(ctx, msg); err != nil {
-			c.logger.Error("failed to process message", "error", err, "offset", msg.Offset)
+		batch = append(batch, msg)
+		if len(batch) == 1 {
+			var cancel context.CancelFunc
+			fetchCtx, cancel = context.WithTimeout(ctx, c.config.BatchTimeout)
+			defer cancel()
 		}
 	}
+	return batch, nil
 }
 
-// Stop stops the consumer
-func (c *Consumer) Stop() error {
-	close(c.stopChan)
-	return c.reader.Close()
-}
+// flush stores a batch's events and then commits its offsets. A failed write
+// is retried with backoff until it succeeds or the consumer stops, and
+// nothing is committed until it succeeds. Events the database rejects are
+// dropped rather than retried, so they cannot hold up their partition.
+func (c *Consumer) flush(fetchCtx, writeCtx context.Context, batch []kafka.Message) error {
+	events := c.decode(batch)
 
-func (c *Consumer) processMessage(ctx context.Context, msg kafka.Message) error {
-	var event models.Event
-	if err := json.Unmarshal(msg.Value, &event); err != nil {
-		return err
+	backoff := c.config.BaseBackoff
+	for len(events) > 0 {
+		err := c.store.StoreBatch(writeCtx, events)
+		if err != nil && postgres.IsPermanent(err) {
+			// One rejected event fails the whole transaction, so the events
+			// are stored one by one to find it
+			events, err = c.storeEach(writeCtx, events)
+		}
+		if err == nil {
+			break
+		}
+		c.storeFailures.Add(1)
+		c.logger.Error("failed to store event batch", "error", err, "events", len(events), "retry_in", backoff)
+		if fetchCtx.Err() != nil || writeCtx.Err() != nil {
+			return err
+		}
+		sleep(fetchCtx, backoff)
+		backoff = min(backoff*2, c.config.MaxBackoff)
 	}
+	c.stored.Add(int64(len(events)))
 
-	// Only process if we have an event store
-	if c.eventStore == nil {
-		c.logger.Debug("skipping persistence, no event store")
+	// The events are stored: if the commit fails they are redelivered and
+	// skipped as duplicates, and the next commit covers their offsets
+	if err := c.reader.CommitMessages(writeCtx, batch...); err != nil {
+		c.commitFailures.Add(1)
+		c.logger.Warn("failed to commit offsets", "error", err, "messages", len(batch))
 		return nil
 	}
 
-	// Check if already processed (idempotency)
-	existing, err := c.eventStore.GetByID(ctx, event.EventID)
-	if err == nil && existing != nil {
-		c.logger.Debug("event already processed", "event_id", event.EventID)
-		return nil
-	}
+	last := batch[len(batch)-1]
+	c.logger.Debug("event batch consumed from kafka",
+		"events", len(events),
+		"partition", last.Partition,
+		"offset", last.Offset,
+	)
+	return nil
+}
 
-	// Store event
-	if err := c.eventStore.Store(ctx, &event); err != nil {
-		return err
+// storeEach stores events one at a time and drops those the database
+// rejects. On any other error it returns the events not dropped so far, to be
+// retried; storing an event twice is harmless.
+func (c *Consumer) storeEach(ctx context.Context, events []*models.Event) ([]*models.Event, error) {
+	kept := make([]*models.Event, 0, len(events))
+	for i, event := range events {
+		err := c.store.StoreBatch(ctx, []*models.Event{event})
+		if err == nil {
+			kept = append(kept, event)
+			continue
+		}
+		if !postgres.IsPermanent(err) {
+			return append(kept, events[i:]...), err
+		}
+		c.rejected.Add(1)
+		c.logger.Error("dropping event rejected by the database", "error", err, "event_id", event.EventID)
 	}
+	return kept, nil
+}
 
-	c.logger.Info("event consumed from kafka",
-		"event_id", event.EventID,
-		"service_id", event.ServiceID,
-		"offset", msg.Offset,
-	)
+// decode decodes a batch's events. Messages that can never be stored are
+// logged and skipped, so they are committed instead of blocking the
+// partition.
+func (c *Consumer) decode(batch []kafka.Message) []*models.Event {
+	events := make([]*models.Event, 0, len(batch))
+	for _, msg := range batch {
+		var event models.Event
+		err := json.Unmarshal(msg.Value, &event)
+		if err == nil {
+			err = event.Validate()
+		}
+		if err != nil {
+			c.invalid.Add(1)
+			c.logger.Error("skipping invalid event message", "error", err, "partition", msg.Partition, "offset", msg.Offset)
+			continue
+		}
+		events = append(events, &event)
+	}
+	return events
+}
 
-	return nil
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
 }
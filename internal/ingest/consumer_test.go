@@ -0,0 +1,207 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReader serves queued messages, then blocks until the context ends
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed []int64
+	calls     []string
+	closed    bool
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	r.calls = append(r.calls, "commit")
+	return nil
+}
+
+func (r *fakeReader) Stats() kafka.ReaderStats {
+	return kafka.ReaderStats{Topic: "ade.events", Lag: 3}
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) snapshot() ([]int64, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...), append([]string(nil), r.calls...)
+}
+
+// fakeStore fails the first failures writes, and rejects every write with
+// an event of the rejected type
+type fakeStore struct {
+	reader   *fakeReader
+	failures int
+	rejected models.EventType
+	batches  [][]string
+}
+
+func (s *fakeStore) StoreBatch(ctx context.Context, events []*models.Event) error {
+	s.reader.mu.Lock()
+	defer s.reader.mu.Unlock()
+	s.reader.calls = append(s.reader.calls, "store")
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("database unavailable")
+	}
+	for _, e := range events {
+		if s.rejected != "" && e.EventType == s.rejected {
+			return &pgconn.PgError{Code: "23514", ConstraintName: "chk_event_type"}
+		}
+	}
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.EventID
+	}
+	s.batches = append(s.batches, ids)
+	return nil
+}
+
+func eventMessage(t *testing.T, offset int64, eventID string) kafka.Message {
+	value, err := json.Marshal(models.Event{
+		EventID:        eventID,
+		IdempotencyKey: "idemp-" + eventID,
+		ServiceID:      "api",
+		EventType:      models.EventTypeMetrics,
+		Payload:        json.RawMessage(`{"cpu": 50}`),
+	})
+	require.NoError(t, err)
+	return kafka.Message{Offset: offset, Value: value}
+}
+
+func testConsumer(reader *fakeReader, store *fakeStore) *Consumer {
+	return NewConsumer(reader, store, ConsumerConfig{
+		BatchSize:    2,
+		BatchTimeout: 20 * time.Millisecond,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+	}, nil)
+}
+
+// consume runs the consumer until every queued message is committed
+func consume(t *testing.T, c *Consumer, reader *fakeReader, want int) {
+	t.Helper()
+	go c.Start(context.Background())
+	require.Eventually(t, func() bool {
+		committed, _ := reader.snapshot()
+		return len(committed) == want
+	}, time.Second, time.Millisecond)
+	require.NoError(t, c.Stop(context.Background()))
+}
+
+func TestConsumerBatchesAndCommitsAfterStore(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{
+		eventMessage(t, 0, "evt-1"),
+		eventMessage(t, 1, "evt-2"),
+		eventMessage(t, 2, "evt-3"),
+	}}
+	store := &fakeStore{reader: reader}
+	c := testConsumer(reader, store)
+
+	consume(t, c, reader, 3)
+
+	committed, calls := reader.snapshot()
+	assert.Equal(t, [][]string{{"evt-1", "evt-2"}, {"evt-3"}}, store.batches)
+	assert.Equal(t, []int64{0, 1, 2}, committed)
+	assert.Equal(t, []string{"store", "commit", "store", "commit"}, calls)
+	assert.True(t, reader.closed)
+}
+
+func TestConsumerRetriesFailedStoreBeforeCommit(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{eventMessage(t, 0, "evt-1")}}
+	store := &fakeStore{reader: reader, failures: 2}
+	c := testConsumer(reader, store)
+
+	consume(t, c, reader, 1)
+
+	_, calls := reader.snapshot()
+	assert.Equal(t, []string{"store", "store", "store", "commit"}, calls)
+	assert.Equal(t, int64(2), c.storeFailures.Load())
+	assert.Equal(t, int64(1), c.stored.Load())
+}
+
+func TestConsumerSkipsInvalidMessages(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{
+		{Offset: 0, Value: []byte("not json")},
+		{Offset: 1, Value: []byte(`{"event_id": "evt-1"}`)},
+		{Offset: 2, Value: []byte(`{"event_id": "evt-2", "idempotency_key": "k", "service_id": "api", "event_type": "deploy", "payload": {}}`)},
+	}}
+	store := &fakeStore{reader: reader}
+	c := testConsumer(reader, store)
+
+	consume(t, c, reader, 3)
+
+	_, calls := reader.snapshot()
+	assert.Empty(t, store.batches)
+	assert.Equal(t, []string{"commit", "commit"}, calls)
+	assert.Equal(t, int64(3), c.invalid.Load())
+}
+
+func TestConsumerDropsRejectedEvents(t *testing.T) {
+	rejected := eventMessage(t, 1, "evt-2")
+	rejected.Value = []byte(strings.Replace(string(rejected.Value), `"metrics"`, `"alert"`, 1))
+	reader := &fakeReader{messages: []kafka.Message{eventMessage(t, 0, "evt-1"), rejected}}
+	store := &fakeStore{reader: reader, rejected: models.EventTypeAlert}
+	c := testConsumer(reader, store)
+
+	consume(t, c, reader, 2)
+
+	assert.Equal(t, [][]string{{"evt-1"}}, store.batches)
+	assert.Equal(t, int64(1), c.rejected.Load())
+	assert.Equal(t, int64(1), c.stored.Load())
+	assert.Zero(t, c.storeFailures.Load())
+}
+
+func TestConsumerStopLeavesFailingBatchUncommitted(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{eventMessage(t, 0, "evt-1")}}
+	store := &fakeStore{reader: reader, failures: 1000}
+	c := testConsumer(reader, store)
+
+	stopped := make(chan error)
+	go func() { stopped <- c.Start(context.Background()) }()
+	require.Eventually(t, func() bool { return c.storeFailures.Load() > 0 }, time.Second, time.Millisecond)
+
+	require.NoError(t, c.Stop(context.Background()))
+	assert.NoError(t, <-stopped)
+	committed, _ := reader.snapshot()
+	assert.Empty(t, committed)
+	assert.True(t, reader.closed)
+}
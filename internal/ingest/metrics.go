@@ -0,0 +1,55 @@
+package ingest
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	consumerLagDesc = prometheus.NewDesc(
+		"ade_ingest_consumer_lag",
+		"Messages of the events topic the ingest consumer has not yet fetched",
+		[]string{"topic"}, nil,
+	)
+	consumerMessagesDesc = prometheus.NewDesc(
+		"ade_ingest_consumer_messages_total",
+		"Event messages the ingest consumer wrote to the database, skipped as invalid or dropped when the database rejected them",
+		[]string{"result"}, nil,
+	)
+	consumerStoreFailuresDesc = prometheus.NewDesc(
+		"ade_ingest_consumer_store_failures_total",
+		"Failed database writes of an event batch, each retried before its offsets are committed",
+		nil, nil,
+	)
+	consumerCommitFailuresDesc = prometheus.NewDesc(
+		"ade_ingest_consumer_commit_failures_total",
+		"Failed offset commits of stored event batches",
+		nil, nil,
+	)
+)
+
+// consumerCollector exports the consumer's lag and counters
+type consumerCollector struct {
+	consumer *Consumer
+}
+
+// MetricsCollector returns a Prometheus collector for the consumer's lag
+// and the messages it has written
+func (c *Consumer) MetricsCollector() prometheus.Collector {
+	return &consumerCollector{consumer: c}
+}
+
+func (cc *consumerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- consumerLagDesc
+	ch <- consumerMessagesDesc
+	ch <- consumerStoreFailuresDesc
+	ch <- consumerCommitFailuresDesc
+}
+
+func (cc *consumerCollector) Collect(ch chan<- prometheus.Metric) {
+	c := cc.consumer
+	stats := c.reader.Stats()
+	ch <- prometheus.MustNewConstMetric(consumerLagDesc, prometheus.GaugeValue, float64(stats.Lag), stats.Topic)
+	ch <- prometheus.MustNewConstMetric(consumerMessagesDesc, prometheus.CounterValue, float64(c.stored.Load()), "stored")
+	ch <- prometheus.MustNewConstMetric(consumerMessagesDesc, prometheus.CounterValue, float64(c.invalid.Load()), "invalid")
+	ch <- prometheus.MustNewConstMetric(consumerMessagesDesc, prometheus.CounterValue, float64(c.rejected.Load()), "rejected")
+	ch <- prometheus.MustNewConstMetric(consumerStoreFailuresDesc, prometheus.CounterValue, float64(c.storeFailures.Load()))
+	ch <- prometheus.MustNewConstMetric(consumerCommitFailuresDesc, prometheus.CounterValue, float64(c.commitFailures.Load()))
+}
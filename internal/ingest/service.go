@@ -12,10 +12,22 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
+// Mode selects where ingested events are written
+type Mode string
+
+const (
+	// ModeSync writes events to the database and does not publish them
+	ModeSync Mode = "sync"
+	// ModeLogFirst only publishes events to Kafka; the Consumer writes them
+	// to the database, so each event is written once
+	ModeLogFirst Mode = "log-first"
+)
+
 // Service handles event ingestion
 type Service struct {
 	eventStore  *postgres.EventStore
 	kafkaWriter *kafka.Writer
+	mode        Mode
 	logger      *slog.Logger
 }
 
@@ -27,10 +39,18 @@ func NewService(eventStore *postgres.EventStore, kafkaWriter *kafka.Writer, logg
 	return &Service{
 		eventStore:  eventStore,
 		kafkaWriter: kafkaWriter,
+		mode:        ModeSync,
 		logger:      logger,
 	}
 }
 
+// SetMode sets the ingest mode, ModeSync by default. In ModeLogFirst the
+// Kafka writer should be synchronous, as an event is accepted once it is
+// published.
+func (s *Service) SetMode(mode Mode) {
+	s.mode = mode
+}
+
 // IngestRequest represents a request to ingest an event
 type IngestRequest struct {
 	EventID        string          `json:"event_id"`
@@ -55,6 +75,9 @@ func (r *IngestRequest) Validate() error {
 	if r.EventType == "" {
 		return models.NewValidationError("event_type", "event type is required")
 	}
+	if !r.EventType.Valid() {
+		return models.NewValidationError("event_type", "event type must be metrics, alert or custom")
+	}
 	if len(r.Payload) == 0 {
 		return models.NewValidationError("payload", "payload is required")
 	}
@@ -91,9 +114,19 @@ func (s *Service) Ingest(ctx context.Context, req *IngestRequest) (*IngestRespon
 		Timestamp:      req.Timestamp,
 	}
 
-	// Store in database
-	stored := false
-	if s.eventStore != nil {
+	stored, published := false, false
+	if s.mode == ModeLogFirst {
+		// The topic is the system of record: the event is accepted only
+		// once it is published, and the consumer stores it
+		if s.kafkaWriter == nil {
+			return nil, fmt.Errorf("failed to publish event: no kafka writer")
+		}
+		if err := s.publishToKafka(ctx, event); err != nil {
+			s.logger.Error("failed to publish to kafka", "error", err, "event_id", req.EventID)
+			return nil, fmt.Errorf("failed to publish event: %w", err)
+		}
+		published = true
+	} else if s.eventStore != nil {
 		if err := s.eventStore.Store(ctx, event); err != nil {
 			s.logger.Error("failed to store event", "error", err, "event_id", req.EventID)
 			return nil, fmt.Errorf("failed to store event: %w", err)
@@ -101,20 +134,12 @@ func (s *Service) Ingest(ctx context.Context, req *IngestRequest) (*IngestRespon
 		stored = true
 	}
 
-	// Publish to Kafka
-	published := false
-	if s.kafkaWriter != nil {
-		if err := s.publishToKafka(ctx, event); err != nil {
-			s.logger.Warn("failed to publish to kafka", "error", err, "event_id", req.EventID)
-		} else {
-			published = true
-		}
-	}
-
 	s.logger.Info("event ingested", 
 		"event_id", req.EventID, 
 		"service_id", req.ServiceID,
 		"duration_ms", time.Since(start).Milliseconds(),
+		"mode", s.mode,
+		"stored", stored,
 		"published", published,
 	)
 
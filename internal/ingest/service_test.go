@@ -1,6 +1,7 @@
 package ingest
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -38,6 +39,17 @@ func TestIngestRequestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown event type",
+			request: IngestRequest{
+				EventID:        "evt-001",
+				IdempotencyKey: "idemp-001",
+				ServiceID:      "test-service",
+				EventType:      models.EventType("deploy"),
+				Payload:        json.RawMessage(`{}`),
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing service id",
 			request: IngestRequest{
@@ -90,3 +102,22 @@ func TestMetricsRequestHelper(t *testing.T) {
 	assert.Equal(t, 1000.0, payload.RequestsPerSec)
 	assert.Equal(t, 10, payload.QueueDepth)
 }
+
+func TestIngestModes(t *testing.T) {
+	req := MetricsRequest("test-service", 50, 100, 0.01, 1000, 5)
+
+	t.Run("sync does not publish", func(t *testing.T) {
+		s := NewService(nil, nil, nil)
+		resp, err := s.Ingest(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, resp.Stored)
+		assert.False(t, resp.Published)
+	})
+
+	t.Run("log-first fails without publishing", func(t *testing.T) {
+		s := NewService(nil, nil, nil)
+		s.SetMode(ModeLogFirst)
+		_, err := s.Ingest(context.Background(), req)
+		assert.ErrorContains(t, err, "failed to publish event")
+	})
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ActionStatus represents the lifecycle state of an action
+type ActionStatus string
+
+const (
+	ActionStatusAwaitingApproval ActionStatus = "awaiting_approval"
+	ActionStatusPending          ActionStatus = "pending"
+	ActionStatusScheduled        ActionStatus = "scheduled"
+	ActionStatusExecuting        ActionStatus = "executing"
+	ActionStatusCompleted        ActionStatus = "completed"
+	ActionStatusFailed           ActionStatus = "failed"
+	ActionStatusCancelled        ActionStatus = "cancelled"
+	ActionStatusRejected         ActionStatus = "rejected"
+)
+
+// actionTransitions lists the statuses each status may move to
+var actionTransitions = map[ActionStatus][]ActionStatus{
+	ActionStatusAwaitingApproval: {ActionStatusPending, ActionStatusRejected},
+	ActionStatusPending:          {ActionStatusScheduled, ActionStatusExecuting, ActionStatusCancelled},
+	ActionStatusScheduled:        {ActionStatusExecuting, ActionStatusCancelled},
+	ActionStatusExecuting:        {ActionStatusCompleted, ActionStatusFailed},
+}
+
+// ValidTransition reports whether an action may move from one status to another
+func ValidTransition(from, to ActionStatus) bool {
+	for _, next := range actionTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible from status
+func (s ActionStatus) IsTerminal() bool {
+	return len(actionTransitions[s]) == 0
+}
+
+// ErrInvalidTransition is returned when an action status change is not allowed
+var ErrInvalidTransition = fmt.Errorf("invalid action status transition")
+
+// ErrApprovalExpired is returned when reviewing an action whose approval window has passed
+var ErrApprovalExpired = fmt.Errorf("action approval expired")
+
+// ActionRecord represents a persisted action and its execution lifecycle
+type ActionRecord struct {
+	ID                string          `json:"id" db:"id"`
+	ActionID          string          `json:"action_id" db:"action_id"`
+	DecisionID        string          `json:"decision_id" db:"decision_id"`
+	ActionType        ActionType      `json:"action_type" db:"action_type"`
+	ActionPayload     json.RawMessage `json:"action_payload" db:"action_payload"`
+	TargetService     string          `json:"target_service" db:"target_service"`
+	Status            ActionStatus    `json:"status" db:"status"`
+	DryRun            bool            `json:"dry_run" db:"dry_run"`
+	ScheduledAt       *time.Time      `json:"scheduled_at,omitempty" db:"scheduled_at"`
+	ExecutedAt        *time.Time      `json:"executed_at,omitempty" db:"executed_at"`
+	CompletedAt       *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+	ErrorMessage      string          `json:"error_message,omitempty" db:"error_message"`
+	RetryCount        int             `json:"retry_count" db:"retry_count"`
+	WebhookURL        string          `json:"webhook_url,omitempty" db:"webhook_url"`
+	WebhookResponse   json.RawMessage `json:"webhook_response,omitempty" db:"webhook_response"`
+	RollbackOf        string          `json:"rollback_of,omitempty" db:"rollback_of"`
+	ApprovalExpiresAt *time.Time      `json:"approval_expires_at,omitempty" db:"approval_expires_at"`
+	ReviewedBy        string          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt        *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewComment     string          `json:"review_comment,omitempty" db:"review_comment"`
+	Cost              float64         `json:"cost,omitempty" db:"cost"`
+	Rollout           *Rollout        `json:"rollout,omitempty" db:"rollout"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// ActionFilters for querying actions
+type ActionFilters struct {
+	DecisionID string
+	ServiceID  string
+	Status     string
+	RollbackOf string
+	Since      time.Time
+	Limit      int
+}
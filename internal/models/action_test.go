@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidTransition(t *testing.T) {
+	tests := []struct {
+		from ActionStatus
+		to   ActionStatus
+		want bool
+	}{
+		{ActionStatusAwaitingApproval, ActionStatusPending, true},
+		{ActionStatusAwaitingApproval, ActionStatusRejected, true},
+		{ActionStatusAwaitingApproval, ActionStatusExecuting, false},
+		{ActionStatusRejected, ActionStatusPending, false},
+		{ActionStatusPending, ActionStatusExecuting, true},
+		{ActionStatusPending, ActionStatusScheduled, true},
+		{ActionStatusPending, ActionStatusCancelled, true},
+		{ActionStatusScheduled, ActionStatusExecuting, true},
+		{ActionStatusScheduled, ActionStatusCancelled, true},
+		{ActionStatusExecuting, ActionStatusCompleted, true},
+		{ActionStatusExecuting, ActionStatusFailed, true},
+		{ActionStatusPending, ActionStatusCompleted, false},
+		{ActionStatusExecuting, ActionStatusCancelled, false},
+		{ActionStatusCompleted, ActionStatusExecuting, false},
+		{ActionStatusFailed, ActionStatusExecuting, false},
+		{ActionStatusCancelled, ActionStatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestActionStatusIsTerminal(t *testing.T) {
+	assert.True(t, ActionStatusCompleted.IsTerminal())
+	assert.True(t, ActionStatusFailed.IsTerminal())
+	assert.True(t, ActionStatusCancelled.IsTerminal())
+	assert.True(t, ActionStatusRejected.IsTerminal())
+	assert.False(t, ActionStatusAwaitingApproval.IsTerminal())
+	assert.False(t, ActionStatusPending.IsTerminal())
+	assert.False(t, ActionStatusExecuting.IsTerminal())
+}
@@ -54,17 +54,40 @@ type DecisionRecord struct {
 	ConfidenceScore *float64        `json:"confidence_score,omitempty" db:"confidence_score"`
 	SimulationRunID *string         `json:"simulation_run_id,omitempty" db:"simulation_run_id"`
 	DryRun          bool            `json:"dry_run" db:"dry_run"`
+	Shadow          bool            `json:"shadow,omitempty" db:"shadow"`       // evaluated with a candidate policy, never acted on
+	ShadowOf        *string         `json:"shadow_of,omitempty" db:"shadow_of"` // live decision a shadow record was made next to
+	ExperimentID    *string         `json:"experiment_id,omitempty" db:"experiment_id"`
+	ExperimentArm   *string         `json:"experiment_arm,omitempty" db:"experiment_arm"` // arm whose policy made the decision
 	ExecutedAt      time.Time       `json:"executed_at" db:"executed_at"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 }
 
+// ShadowComparison pairs a live decision with the shadow evaluation of the
+// candidate version of its policy
+type ShadowComparison struct {
+	DecisionID       string          `json:"decision_id"`
+	ShadowID         string          `json:"shadow_decision_id"`
+	ServiceID        string          `json:"service_id"`
+	PolicyID         string          `json:"policy_id"`
+	PolicyVersion    string          `json:"policy_version"`
+	CandidateVersion string          `json:"candidate_version"`
+	LiveRule         string          `json:"live_rule,omitempty"`
+	ShadowRule       string          `json:"shadow_rule,omitempty"`
+	LiveResult       DecisionResult  `json:"live_result"`
+	ShadowResult     DecisionResult  `json:"shadow_result"`
+	LiveActions      json.RawMessage `json:"live_actions"`
+	ShadowActions    json.RawMessage `json:"shadow_actions"`
+	ExecutedAt       time.Time       `json:"executed_at"`
+}
+
 // Action represents a single action to be executed
 type Action struct {
-	Type    ActionType      `json:"type"`
-	Payload json.RawMessage `json:"payload,omitempty"`
-	Target  string          `json:"target,omitempty"`
-	Cost    float64         `json:"cost,omitempty"`
-	Risk    float64         `json:"risk,omitempty"`
+	Type      ActionType      `json:"type"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Target    string          `json:"target,omitempty"`
+	Cost      float64         `json:"cost,omitempty"`
+	Risk      float64         `json:"risk,omitempty"`
+	BlockedBy string          `json:"blocked_by,omitempty"` // guardrail that kept the action from running
 }
 
 // DecisionTrace represents the audit trail of a decision
@@ -79,6 +102,7 @@ type DecisionTrace struct {
 	RulesMatched    json.RawMessage `json:"rules_matched" db:"rules_matched"`
 	FeaturesUsed    json.RawMessage `json:"features_used" db:"features_used"`
 	ExecutionTimeMs int             `json:"execution_time_ms" db:"execution_time_ms"`
+	Guardrails      json.RawMessage `json:"guardrails,omitempty" db:"guardrails"` // violations that blocked actions
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 }
 
@@ -96,12 +120,14 @@ type TraceRule struct {
 
 // DecisionRequest represents a request to make a decision
 type DecisionRequest struct {
-	ServiceID      string           `json:"service_id"`
-	DecisionType   DecisionType     `json:"decision_type"`
-	Features       *ServiceFeatures `json:"features,omitempty"`
-	DryRun         bool             `json:"dry_run"`
-	Simulate       bool             `json:"simulate"`
-	IdempotencyKey string           `json:"idempotency_key"`
+	ServiceID      string                 `json:"service_id"`
+	DecisionType   DecisionType           `json:"decision_type"`
+	Features       *ServiceFeatures       `json:"features,omitempty"`
+	Params         map[string]interface{} `json:"params,omitempty"` // overrides of the policy's params
+	Experiment     *ExperimentAssignment  `json:"-"`                // arm of the experiment the policy was picked by
+	DryRun         bool                   `json:"dry_run"`
+	Simulate       bool                   `json:"simulate"`
+	IdempotencyKey string                 `json:"idempotency_key"`
 }
 
 // DecisionResponse represents the response from a decision
@@ -109,18 +135,25 @@ type DecisionResponse struct {
 	DecisionID     string         `json:"decision_id"`
 	DecisionResult DecisionResult `json:"result"`
 	Actions        []Action       `json:"actions"`
+	ActionIDs      []string       `json:"action_ids,omitempty"` // actions dispatched for execution or approval
 	Confidence     float64        `json:"confidence"`
 	TraceID        string         `json:"trace_id"`
 	DryRun         bool           `json:"dry_run"`
+	VetoedBy       string         `json:"vetoed_by,omitempty"` // why a plugin vetoed the decision
+	ExperimentID   string         `json:"experiment_id,omitempty"`
+	ExperimentArm  string         `json:"experiment_arm,omitempty"`
 	Timestamp      time.Time      `json:"timestamp"`
 }
 
 // DecisionFilters for querying decisions
 type DecisionFilters struct {
-	ServiceID string
-	PolicyID  string
-	From      time.Time
-	To        time.Time
-	Result    string
-	Limit     int
+	ServiceID     string
+	PolicyID      string
+	PolicyVersion string
+	Shadow        bool // list shadow records instead of live decisions
+	ExperimentID  string
+	From          time.Time
+	To            time.Time
+	Result        string
+	Limit         int
 }
@@ -15,6 +15,15 @@ const (
 	EventTypeCustom  EventType = "custom"
 )
 
+// Valid reports whether the event type is one the events table accepts
+func (t EventType) Valid() bool {
+	switch t {
+	case EventTypeMetrics, EventTypeAlert, EventTypeCustom:
+		return true
+	}
+	return false
+}
+
 // Event represents an incoming event to the system
 type Event struct {
 	ID             string          `json:"id" db:"id"`
@@ -42,6 +51,9 @@ func (e *Event) Validate() error {
 	if e.EventType == "" {
 		return NewValidationError("event_type", "event type is required")
 	}
+	if !e.EventType.Valid() {
+		return NewValidationError("event_type", "event type must be metrics, alert or custom")
+	}
 	if len(e.Payload) == 0 {
 		return NewValidationError("payload", "payload is required")
 	}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExperimentStatus represents the state of a policy experiment
+type ExperimentStatus string
+
+const (
+	ExperimentStatusRunning ExperimentStatus = "running"
+	ExperimentStatusStopped ExperimentStatus = "stopped"
+)
+
+// ExperimentArm is one of the policies an experiment runs live, for a share
+// of the services
+type ExperimentArm struct {
+	Name     string `json:"name"`
+	PolicyID string `json:"policy_id"`
+	Weight   int    `json:"weight"` // percent of services
+}
+
+// Experiment splits the decisions of a policy between arms by service. The
+// first arm is the control the others are compared with.
+type Experiment struct {
+	ID           string           `json:"id" db:"id"`
+	ExperimentID string           `json:"experiment_id" db:"experiment_id"`
+	PolicyID     string           `json:"policy_id" db:"policy_id"` // decisions of this policy are split
+	Description  string           `json:"description,omitempty" db:"description"`
+	Arms         []ExperimentArm  `json:"arms" db:"arms"`
+	Status       ExperimentStatus `json:"status" db:"status"`
+	StartedAt    time.Time        `json:"started_at" db:"started_at"`
+	StoppedAt    *time.Time       `json:"stopped_at,omitempty" db:"stopped_at"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+}
+
+// Validate validates the experiment definition
+func (e *Experiment) Validate() error {
+	if e.ExperimentID == "" {
+		return NewValidationError("experiment_id", "experiment ID is required")
+	}
+	if e.PolicyID == "" {
+		return NewValidationError("policy_id", "policy ID is required")
+	}
+	if len(e.Arms) < 2 {
+		return NewValidationError("arms", "an experiment needs at least two arms")
+	}
+	names := make(map[string]bool, len(e.Arms))
+	total := 0
+	for i, arm := range e.Arms {
+		if arm.Name == "" || arm.PolicyID == "" {
+			return NewValidationError("arms", fmt.Sprintf("arm %d needs a name and a policy_id", i))
+		}
+		if names[arm.Name] {
+			return NewValidationError("arms", fmt.Sprintf("duplicate arm %s", arm.Name))
+		}
+		names[arm.Name] = true
+		if arm.Weight <= 0 {
+			return NewValidationError("arms", fmt.Sprintf("arm %s needs a positive weight", arm.Name))
+		}
+		total += arm.Weight
+	}
+	if total != 100 {
+		return NewValidationError("arms", fmt.Sprintf("arm weights add up to %d, not 100", total))
+	}
+	return nil
+}
+
+// ExperimentAssignment is the arm of an experiment a service is in
+type ExperimentAssignment struct {
+	ExperimentID string    `json:"experiment_id" db:"experiment_id"`
+	ServiceID    string    `json:"service_id" db:"service_id"`
+	Arm          string    `json:"arm" db:"arm"`
+	PolicyID     string    `json:"policy_id" db:"policy_id"`
+	AssignedAt   time.Time `json:"assigned_at" db:"assigned_at"`
+}
+
+// ExperimentArmOutcome aggregates the decisions an experiment arm made and
+// the impact scores of the feedback they received
+type ExperimentArmOutcome struct {
+	Arm            string  `json:"arm"`
+	Services       int     `json:"services"`
+	Decisions      int     `json:"decisions"`
+	FeedbackCount  int     `json:"feedback_count"`
+	MeanImpact     float64 `json:"mean_impact"`
+	ImpactVariance float64 `json:"impact_variance"` // sample variance
+}
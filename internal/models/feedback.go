@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FeedbackType represents when feedback was collected relative to the action
+type FeedbackType string
+
+const (
+	FeedbackTypeImmediate FeedbackType = "immediate"
+	FeedbackTypeDelayed   FeedbackType = "delayed"
+	FeedbackTypeScheduled FeedbackType = "scheduled"
+)
+
+// FeedbackRecord represents the measured outcome of an action
+type FeedbackRecord struct {
+	ID                    string          `json:"id" db:"id"`
+	FeedbackID            string          `json:"feedback_id" db:"feedback_id"`
+	ActionID              string          `json:"action_id" db:"action_id"`
+	DecisionID            string          `json:"decision_id" db:"decision_id"`
+	ServiceID             string          `json:"service_id" db:"service_id"`
+	FeedbackType          FeedbackType    `json:"feedback_type" db:"feedback_type"`
+	MetricsBefore         json.RawMessage `json:"metrics_before" db:"metrics_before"`
+	MetricsAfter          json.RawMessage `json:"metrics_after" db:"metrics_after"`
+	ImpactScore           *float64        `json:"impact_score,omitempty" db:"impact_score"`
+	DriftDetected         bool            `json:"drift_detected" db:"drift_detected"`
+	DriftDetails          json.RawMessage `json:"drift_details,omitempty" db:"drift_details"`
+	RollbackRecommended   bool            `json:"rollback_recommended" db:"rollback_recommended"`
+	RollbackExecuted      bool            `json:"rollback_executed" db:"rollback_executed"`
+	ObservationWindowMins int             `json:"observation_window_minutes" db:"observation_window_minutes"`
+	RecordedAt            time.Time       `json:"recorded_at" db:"recorded_at"`
+	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
+}
+
+// FeedbackFilters for querying feedback
+type FeedbackFilters struct {
+	ActionID   string
+	DecisionID string
+	ServiceID  string
+	DriftOnly  bool
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+// DriftBucket aggregates the feedback of a service over one time bucket
+type DriftBucket struct {
+	BucketStart         time.Time `json:"bucket_start"`
+	FeedbackCount       int64     `json:"feedback_count"`
+	DriftCount          int64     `json:"drift_count"`
+	RollbackRecommended int64     `json:"rollback_recommended"`
+	RollbackExecuted    int64     `json:"rollback_executed"`
+	AvgImpactScore      float64   `json:"avg_impact_score"`
+	MinImpactScore      float64   `json:"min_impact_score"`
+}
+
+// PolicyImpact summarizes the decisions of one policy and the measured impact of their actions
+type PolicyImpact struct {
+	PolicyID            string   `json:"policy_id"`
+	Decisions           int64    `json:"decisions"`
+	Actions             int64    `json:"actions"`
+	FailedActions       int64    `json:"failed_actions"`
+	FeedbackCount       int64    `json:"feedback_count"`
+	AvgImpactScore      *float64 `json:"avg_impact_score,omitempty"`
+	DriftCount          int64    `json:"drift_count"`
+	RollbackRecommended int64    `json:"rollback_recommended"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Circuit changes a guardrail reservation records
+const (
+	CircuitOpen  = "open"
+	CircuitClose = "close"
+)
+
+// GuardrailUsage is what an action that passed the guardrails counts
+// against their limits
+type GuardrailUsage struct {
+	ActionID   string    `json:"action_id" db:"action_id"`
+	ServiceID  string    `json:"service_id" db:"service_id"`
+	Instances  int       `json:"instances" db:"instances"`
+	Cost       float64   `json:"cost" db:"cost"`
+	Circuit    string    `json:"circuit,omitempty" db:"circuit"` // CircuitOpen or CircuitClose for circuit actions
+	ReservedAt time.Time `json:"reserved_at" db:"reserved_at"`
+}
+
+// UsageTotals is the guardrail usage reserved per service
+type UsageTotals struct {
+	Instances    map[string]int     // instances added in the instance window
+	Spend        map[string]float64 // cost spent in the budget window
+	OpenCircuits []string           // services whose latest circuit change opened it
+}
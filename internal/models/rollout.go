@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RolloutStatus represents the state of a staged action
+type RolloutStatus string
+
+const (
+	RolloutStatusObserving  RolloutStatus = "observing"  // a stage is applied and its observation window is running
+	RolloutStatusEvaluating RolloutStatus = "evaluating" // claimed for evaluation or a manual promote/abort
+	RolloutStatusPaused     RolloutStatus = "paused"     // waiting for a manual promote or abort
+	RolloutStatusCompleted  RolloutStatus = "completed"
+	RolloutStatusRolledBack RolloutStatus = "rolled_back"
+)
+
+// Verdicts on an observed rollout stage
+const (
+	RolloutVerdictProceed  = "proceed"
+	RolloutVerdictPause    = "pause"
+	RolloutVerdictRollback = "rollback"
+	RolloutVerdictPromoted = "promoted" // moved on by hand
+)
+
+// RolloutStage is one step of a staged action. Fractions are cumulative: a
+// rollout of 0.1, 0.5 and 1 applies a tenth of the action, then up to half,
+// then the rest.
+type RolloutStage struct {
+	Fraction      float64            `json:"fraction"`
+	Zone          string             `json:"zone,omitempty"`
+	AppliedAt     *time.Time         `json:"applied_at,omitempty"`
+	Response      json.RawMessage    `json:"response,omitempty"`
+	MetricsBefore map[string]float64 `json:"metrics_before,omitempty"`
+	ImpactScore   *float64           `json:"impact_score,omitempty"`
+	Verdict       string             `json:"verdict,omitempty"`
+}
+
+// Rollout tracks the stages of a staged action
+type Rollout struct {
+	Stages      []RolloutStage `json:"stages"`
+	Window      string         `json:"window,omitempty"` // observation window after each stage, e.g. "10m"
+	Current     int            `json:"current"`          // index of the stage applied last
+	Status      RolloutStatus  `json:"status,omitempty"`
+	NextCheckAt *time.Time     `json:"next_check_at,omitempty"`
+	Reason      string         `json:"reason,omitempty"` // why the rollout paused or rolled back
+}
+
+// Validate validates the rollout plan
+func (r *Rollout) Validate() error {
+	if len(r.Stages) == 0 {
+		return fmt.Errorf("rollout needs at least one stage")
+	}
+	prev := 0.0
+	for i, stage := range r.Stages {
+		if stage.Fraction <= prev || stage.Fraction > 1 {
+			return fmt.Errorf("rollout stage %d: fraction must be above %.2f and at most 1", i, prev)
+		}
+		prev = stage.Fraction
+	}
+	if prev != 1 {
+		return fmt.Errorf("rollout must end with a stage of fraction 1")
+	}
+	if r.Window != "" {
+		if _, err := time.ParseDuration(r.Window); err != nil {
+			return fmt.Errorf("invalid rollout window: %w", err)
+		}
+	}
+	return nil
+}
+
+// WindowDuration returns the observation window, or def when none is set
+func (r *Rollout) WindowDuration(def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(r.Window); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// Done reports whether the rollout has finished either way
+func (r *Rollout) Done() bool {
+	return r.Status == RolloutStatusCompleted || r.Status == RolloutStatusRolledBack
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookDelivery records one attempt to deliver a webhook
+type WebhookDelivery struct {
+	ID           string    `json:"id" db:"id"`
+	WebhookID    string    `json:"webhook_id" db:"webhook_id"`
+	URL          string    `json:"url" db:"url"`
+	Attempt      int       `json:"attempt" db:"attempt"`
+	StatusCode   int       `json:"status_code,omitempty" db:"status_code"` // zero when no response was received
+	LatencyMs    int64     `json:"latency_ms" db:"latency_ms"`
+	ResponseBody string    `json:"response_body,omitempty" db:"response_body"`
+	Error        string    `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookDeadLetter is a webhook that failed every retry
+type WebhookDeadLetter struct {
+	ID              string            `json:"id" db:"id"`
+	WebhookID       string            `json:"webhook_id" db:"webhook_id"`
+	URL             string            `json:"url" db:"url"`
+	Method          string            `json:"method" db:"method"`
+	Headers         map[string]string `json:"headers" db:"headers"`
+	Payload         json.RawMessage   `json:"payload" db:"payload"`
+	Attempts        int               `json:"attempts" db:"attempts"`
+	LastError       string            `json:"last_error" db:"last_error"`
+	RedeliveryCount int               `json:"redelivery_count" db:"redelivery_count"`
+	RedeliveredAt   *time.Time        `json:"redelivered_at,omitempty" db:"redelivered_at"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// DeadLetterFilters for querying dead letters
+type DeadLetterFilters struct {
+	WebhookID string
+	Pending   bool // only dead letters not yet redelivered
+	Limit     int
+}
@@ -0,0 +1,152 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// Slack action IDs of the approval buttons
+const (
+	ApprovalActionApprove = "ade_approve"
+	ApprovalActionReject  = "ade_reject"
+)
+
+// maxSignatureAge is how old a signed Slack request may be before it is refused
+const maxSignatureAge = 5 * time.Minute
+
+// ApprovalRequested posts an approval request with Approve and Reject buttons
+func (s *SlackNotifier) ApprovalRequested(ctx context.Context, record *models.ActionRecord) error {
+	expires := "never"
+	if record.ApprovalExpiresAt != nil {
+		expires = record.ApprovalExpiresAt.Format(time.RFC3339)
+	}
+
+	msg := SlackMessage{
+		Text: fmt.Sprintf("ADE action %s awaiting approval", record.ActionID),
+		Blocks: []Block{
+			{
+				Type: "section",
+				Text: &TextObject{
+					Type: "mrkdwn",
+					Text: fmt.Sprintf("*Approval required:* `%s` on *%s*", record.ActionType, record.TargetService),
+				},
+				Fields: []*TextObject{
+					{Type: "mrkdwn", Text: "*Action*\n" + record.ActionID},
+					{Type: "mrkdwn", Text: "*Decision*\n" + record.DecisionID},
+					{Type: "mrkdwn", Text: "*Params*\n```" + string(record.ActionPayload) + "```"},
+					{Type: "mrkdwn", Text: "*Expires*\n" + expires},
+				},
+			},
+			{
+				Type:    "actions",
+				BlockID: "ade_approval",
+				Elements: []Element{
+					{
+						Type:     "button",
+						Text:     &TextObject{Type: "plain_text", Text: "Approve"},
+						ActionID: ApprovalActionApprove,
+						Value:    record.ActionID,
+						Style:    "primary",
+					},
+					{
+						Type:     "button",
+						Text:     &TextObject{Type: "plain_text", Text: "Reject"},
+						ActionID: ApprovalActionReject,
+						Value:    record.ActionID,
+						Style:    "danger",
+					},
+				},
+			},
+		},
+	}
+
+	return s.send(ctx, msg)
+}
+
+// RespondToInteraction replaces the interactive message with text
+func (s *SlackNotifier) RespondToInteraction(ctx context.Context, responseURL, text string) error {
+	return s.post(ctx, responseURL, map[string]interface{}{
+		"replace_original": true,
+		"text":             text,
+	})
+}
+
+// Interaction is the payload Slack posts when a user clicks a button
+type Interaction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+}
+
+// Reviewer identifies the Slack user who clicked, for the audit trail
+func (i *Interaction) Reviewer() string {
+	if i.User.Username != "" {
+		return "slack:" + i.User.Username
+	}
+	return "slack:" + i.User.ID
+}
+
+// ParseInteraction decodes the form-encoded body of a Slack interaction request
+func ParseInteraction(body []byte) (*Interaction, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid interaction body: %w", err)
+	}
+
+	payload := form.Get("payload")
+	if payload == "" {
+		return nil, fmt.Errorf("interaction payload is missing")
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal([]byte(payload), &interaction); err != nil {
+		return nil, fmt.Errorf("invalid interaction payload: %w", err)
+	}
+	return &interaction, nil
+}
+
+// VerifySlackSignature checks the X-Slack-Signature of a request against the
+// app's signing secret and refuses requests older than five minutes
+func VerifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("slack signing secret not configured")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid slack request timestamp")
+	}
+	age := now.Sub(time.Unix(ts, 0))
+	if age > maxSignatureAge || age < -maxSignatureAge {
+		return fmt.Errorf("slack request timestamp too old")
+	}
+
+	if !hmac.Equal([]byte(SlackSignature(secret, timestamp, body)), []byte(signature)) {
+		return fmt.Errorf("slack signature mismatch")
+	}
+	return nil
+}
+
+// SlackSignature computes the v0 signature Slack sends with a request
+func SlackSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("payload=%7B%7D")
+	ts := strconv.FormatInt(now.Unix(), 10)
+	valid := SlackSignature("secret", ts, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		now       time.Time
+		wantErr   bool
+	}{
+		{name: "valid", secret: "secret", timestamp: ts, signature: valid, now: now},
+		{name: "wrong secret", secret: "other", timestamp: ts, signature: valid, now: now, wantErr: true},
+		{name: "tampered signature", secret: "secret", timestamp: ts, signature: "v0=00", now: now, wantErr: true},
+		{name: "stale", secret: "secret", timestamp: ts, signature: valid, now: now.Add(6 * time.Minute), wantErr: true},
+		{name: "bad timestamp", secret: "secret", timestamp: "soon", signature: valid, now: now, wantErr: true},
+		{name: "no secret", secret: "", timestamp: ts, signature: valid, now: now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySlackSignature(tt.secret, tt.timestamp, tt.signature, body, tt.now)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseInteraction(t *testing.T) {
+	payload := `{"type":"block_actions","user":{"id":"U1","username":"alice"},` +
+		`"actions":[{"action_id":"ade_approve","value":"act-1"}],"response_url":"https://hooks.slack.test/r"}`
+	body := []byte(url.Values{"payload": {payload}}.Encode())
+
+	interaction, err := ParseInteraction(body)
+	require.NoError(t, err)
+	require.Len(t, interaction.Actions, 1)
+	assert.Equal(t, ApprovalActionApprove, interaction.Actions[0].ActionID)
+	assert.Equal(t, "act-1", interaction.Actions[0].Value)
+	assert.Equal(t, "slack:alice", interaction.Reviewer())
+	assert.Equal(t, "https://hooks.slack.test/r", interaction.ResponseURL)
+
+	_, err = ParseInteraction([]byte("other=1"))
+	assert.Error(t, err)
+}
@@ -34,8 +34,20 @@ type SlackMessage struct {
 
 // Block represents a Slack block
 type Block struct {
-	Type string      `json:"type"`
-	Text *TextObject `json:"text,omitempty"`
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *TextObject   `json:"text,omitempty"`
+	Fields   []*TextObject `json:"fields,omitempty"`
+	Elements []Element     `json:"elements,omitempty"`
+}
+
+// Element represents an interactive element, such as a button, in an actions block
+type Element struct {
+	Type     string      `json:"type"`
+	Text     *TextObject `json:"text,omitempty"`
+	ActionID string      `json:"action_id,omitempty"`
+	Value    string      `json:"value,omitempty"`
+	Style    string      `json:"style,omitempty"`
 }
 
 // TextObject represents text in a block
@@ -121,12 +133,16 @@ func (s *SlackNotifier) NotifyAlert(ctx context.Context, alert AlertNotification
 }
 
 func (s *SlackNotifier) send(ctx context.Context, msg SlackMessage) error {
+	return s.post(ctx, s.webhookURL, msg)
+}
+
+func (s *SlackNotifier) post(ctx context.Context, url string, msg interface{}) error {
 	body, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal slack message: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
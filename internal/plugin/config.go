@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Factory creates an uninitialized plugin
+type Factory func() Plugin
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// RegisterFactory makes a plugin available by name to the plugins file.
+// Plugins compiled into the server call it from an init function.
+func RegisterFactory(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic("plugin: factory registered twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Factories returns the names of the plugins available to the plugins file
+func Factories() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config enables a plugin and holds the config it is initialized with. A
+// plugin with a command runs as a separate process.
+type Config struct {
+	Name       string                 `yaml:"name"`
+	Enabled    *bool                  `yaml:"enabled"` // defaults to true
+	Command    string                 `yaml:"command"`
+	Args       []string               `yaml:"args"`
+	FailClosed bool                   `yaml:"fail_closed"` // veto while a process plugin is down
+	Config     map[string]interface{} `yaml:"config"`
+}
+
+// IsEnabled reports whether the plugin should be loaded
+func (c Config) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// LoadConfig reads plugin configs from a YAML file with a top-level "plugins" list
+func LoadConfig(path string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins: %w", err)
+	}
+
+	var file struct {
+		Plugins []Config `yaml:"plugins"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse plugins: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.Plugins))
+	for i, c := range file.Plugins {
+		if c.Name == "" {
+			return nil, fmt.Errorf("plugin %d: name is required", i)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("plugin %s configured twice", c.Name)
+		}
+		seen[c.Name] = true
+	}
+
+	return file.Plugins, nil
+}
+
+// Load creates, initializes and registers each enabled plugin. A plugin that
+// has neither a factory nor a command, or fails to initialize, stops the load.
+func (m *Manager) Load(configs []Config) error {
+	for _, c := range configs {
+		if !c.IsEnabled() {
+			m.logger.Info("plugin disabled", "name", c.Name)
+			continue
+		}
+
+		var p Plugin
+		if c.Command != "" {
+			p = NewProcessPlugin(c.Name, c.Command, c.Args, c.FailClosed, m.processOptions)
+		} else {
+			factoriesMu.RLock()
+			factory, ok := factories[c.Name]
+			factoriesMu.RUnlock()
+			if !ok {
+				return fmt.Errorf("unknown plugin %s", c.Name)
+			}
+			p = factory()
+		}
+
+		if err := p.Initialize(c.Config, m.logger.With("plugin", c.Name)); err != nil {
+			return fmt.Errorf("failed to initialize plugin %s: %w", c.Name, err)
+		}
+		if err := m.Register(p); err != nil {
+			p.Shutdown()
+			return err
+		}
+	}
+	return nil
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
 )
 
 // Plugin interface that all plugins must implement
@@ -15,18 +19,36 @@ type Plugin interface {
 	Shutdown() error
 }
 
-// DecisionPlugin is called during decision making
+// DecisionPlugin is called during decision making. BeforeDecision may adjust
+// the features the policy sees, or veto the decision by returning an error.
+// AfterDecision may change the decision before it is stored and its actions
+// are dispatched.
 type DecisionPlugin interface {
 	Plugin
-	BeforeDecision(ctx context.Context, features interface{}) error
-	AfterDecision(ctx context.Context, decision interface{}) error
+	BeforeDecision(ctx context.Context, features *models.ServiceFeatures) error
+	AfterDecision(ctx context.Context, decision *models.DecisionResponse) error
 }
 
-// ActionPlugin is called during action execution
+// ActionPlugin is called during action execution. BeforeAction may adjust the
+// request, or veto the action by returning an error. AfterAction sees the
+// outcome of every action that ran, including dry runs and failures.
 type ActionPlugin interface {
 	Plugin
-	BeforeAction(ctx context.Context, action interface{}) error
-	AfterAction(ctx context.Context, result interface{}) error
+	BeforeAction(ctx context.Context, req *action.ActionRequest) error
+	AfterAction(ctx context.Context, req *action.ActionRequest, result *action.ActionResult) error
+}
+
+// RollbackPlugin supplies custom inverse actions for rollbacks
+type RollbackPlugin interface {
+	Plugin
+	InvertsTypes() []models.ActionType
+	Invert(ctx context.Context, original *action.ActionRequest) (*action.ActionRequest, error)
+}
+
+// FactPlugin provides facts to policies under its namespace
+type FactPlugin interface {
+	Plugin
+	policy.FactProvider
 }
 
 // Manager manages plugins
@@ -35,19 +57,32 @@ type Manager struct {
 	plugins         []Plugin
 	decisionPlugins []DecisionPlugin
 	actionPlugins   []ActionPlugin
+	rollbackPlugins []RollbackPlugin
+	processOptions  ProcessOptions
 	logger          *slog.Logger
 }
 
 // NewManager creates a new plugin manager
 func NewManager(logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &Manager{
 		plugins:         make([]Plugin, 0),
 		decisionPlugins: make([]DecisionPlugin, 0),
 		actionPlugins:   make([]ActionPlugin, 0),
+		rollbackPlugins: make([]RollbackPlugin, 0),
+		processOptions:  DefaultProcessOptions(),
 		logger:          logger,
 	}
 }
 
+// SetProcessOptions sets the timeouts and restart backoff of the process
+// plugins loaded afterwards
+func (m *Manager) SetProcessOptions(options ProcessOptions) {
+	m.processOptions = options
+}
+
 // Register registers a plugin
 func (m *Manager) Register(plugin Plugin) error {
 	m.mu.Lock()
@@ -69,6 +104,9 @@ func (m *Manager) Register(plugin Plugin) error {
 	if ap, ok := plugin.(ActionPlugin); ok {
 		m.actionPlugins = append(m.actionPlugins, ap)
 	}
+	if rp, ok := plugin.(RollbackPlugin); ok {
+		m.rollbackPlugins = append(m.rollbackPlugins, rp)
+	}
 
 	m.logger.Info("plugin registered", "name", plugin.Name(), "version", plugin.Version())
 	return nil
@@ -91,6 +129,7 @@ func (m *Manager) Unregister(name string) error {
 			// Remove from categorized slices
 			m.decisionPlugins = filterDecisionPlugins(m.decisionPlugins, name)
 			m.actionPlugins = filterActionPlugins(m.actionPlugins, name)
+			m.rollbackPlugins = filterRollbackPlugins(m.rollbackPlugins, name)
 
 			m.logger.Info("plugin unregistered", "name", name)
 			return nil
@@ -100,8 +139,9 @@ func (m *Manager) Unregister(name string) error {
 	return fmt.Errorf("plugin %s not found", name)
 }
 
-// ExecuteBeforeDecision executes all decision plugins before decision
-func (m *Manager) ExecuteBeforeDecision(ctx context.Context, features interface{}) error {
+// ExecuteBeforeDecision executes all decision plugins before decision. The
+// first plugin to return an error vetoes the decision.
+func (m *Manager) ExecuteBeforeDecision(ctx context.Context, features *models.ServiceFeatures) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -116,7 +156,7 @@ func (m *Manager) ExecuteBeforeDecision(ctx context.Context, features interface{
 }
 
 // ExecuteAfterDecision executes all decision plugins after decision
-func (m *Manager) ExecuteAfterDecision(ctx context.Context, decision interface{}) error {
+func (m *Manager) ExecuteAfterDecision(ctx context.Context, decision *models.DecisionResponse) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -129,13 +169,14 @@ func (m *Manager) ExecuteAfterDecision(ctx context.Context, decision interface{}
 	return nil
 }
 
-// ExecuteBeforeAction executes all action plugins before action
-func (m *Manager) ExecuteBeforeAction(ctx context.Context, action interface{}) error {
+// ExecuteBeforeAction executes all action plugins before action. The first
+// plugin to return an error vetoes the action.
+func (m *Manager) ExecuteBeforeAction(ctx context.Context, req *action.ActionRequest) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for _, plugin := range m.actionPlugins {
-		if err := plugin.BeforeAction(ctx, action); err != nil {
+		if err := plugin.BeforeAction(ctx, req); err != nil {
 			m.logger.Error("action plugin error", "plugin", plugin.Name(), "error", err)
 			return fmt.Errorf("plugin %s: %w", plugin.Name(), err)
 		}
@@ -145,12 +186,12 @@ func (m *Manager) ExecuteBeforeAction(ctx context.Context, action interface{}) e
 }
 
 // ExecuteAfterAction executes all action plugins after action
-func (m *Manager) ExecuteAfterAction(ctx context.Context, result interface{}) error {
+func (m *Manager) ExecuteAfterAction(ctx context.Context, req *action.ActionRequest, result *action.ActionResult) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for _, plugin := range m.actionPlugins {
-		if err := plugin.AfterAction(ctx, result); err != nil {
+		if err := plugin.AfterAction(ctx, req, result); err != nil {
 			m.logger.Error("action plugin error", "plugin", plugin.Name(), "error", err)
 		}
 	}
@@ -158,6 +199,41 @@ func (m *Manager) ExecuteAfterAction(ctx context.Context, result interface{}) er
 	return nil
 }
 
+// RegisterInverters installs the inverses of all rollback plugins on the action service
+func (m *Manager) RegisterInverters(svc *action.Service) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, plugin := range m.rollbackPlugins {
+		for _, actionType := range plugin.InvertsTypes() {
+			svc.RegisterInverter(actionType, plugin)
+			m.logger.Info("rollback inverter registered", "plugin", plugin.Name(), "action_type", actionType)
+		}
+	}
+}
+
+// RegisterFactProviders makes the facts of all fact plugins available to the
+// policy engine. Process plugins provide facts only when they declare the
+// fact hook.
+func (m *Manager) RegisterFactProviders(engine *policy.Engine, options policy.FactOptions) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, plugin := range m.plugins {
+		fp, ok := plugin.(FactPlugin)
+		if !ok {
+			continue
+		}
+		if pp, ok := plugin.(*ProcessPlugin); ok && !pp.implements(HookFact) {
+			continue
+		}
+		if err := engine.RegisterFactProvider(fp, options); err != nil {
+			return fmt.Errorf("plugin %s: %w", plugin.Name(), err)
+		}
+	}
+	return nil
+}
+
 // Shutdown shuts down all plugins
 func (m *Manager) Shutdown() {
 	m.mu.Lock()
@@ -172,6 +248,7 @@ func (m *Manager) Shutdown() {
 	m.plugins = m.plugins[:0]
 	m.decisionPlugins = m.decisionPlugins[:0]
 	m.actionPlugins = m.actionPlugins[:0]
+	m.rollbackPlugins = m.rollbackPlugins[:0]
 }
 
 // ListPlugins returns list of registered plugins
@@ -209,3 +286,13 @@ func filterActionPlugins(plugins []ActionPlugin, name string) []ActionPlugin {
 	}
 	return result
 }
+
+func filterRollbackPlugins(plugins []RollbackPlugin, name string) []RollbackPlugin {
+	result := make([]RollbackPlugin, 0, len(plugins))
+	for _, p := range plugins {
+		if p.Name() != name {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freezePlugin vetoes actions on frozen services and records what it saw
+type freezePlugin struct {
+	frozen   string
+	results  []*action.ActionResult
+	shutdown bool
+}
+
+func (p *freezePlugin) Name() string    { return "freeze" }
+func (p *freezePlugin) Version() string { return "1.0" }
+
+func (p *freezePlugin) Initialize(config map[string]interface{}, logger *slog.Logger) error {
+	frozen, _ := config["service"].(string)
+	if frozen == "" {
+		return errors.New("service is required")
+	}
+	p.frozen = frozen
+	return nil
+}
+
+func (p *freezePlugin) Shutdown() error {
+	p.shutdown = true
+	return nil
+}
+
+func (p *freezePlugin) BeforeDecision(ctx context.Context, features *models.ServiceFeatures) error {
+	if features.ServiceID == p.frozen {
+		return errors.New("service is frozen")
+	}
+	return nil
+}
+
+func (p *freezePlugin) AfterDecision(ctx context.Context, decision *models.DecisionResponse) error {
+	return nil
+}
+
+func (p *freezePlugin) BeforeAction(ctx context.Context, req *action.ActionRequest) error {
+	if req.TargetService == p.frozen {
+		return errors.New("service is frozen")
+	}
+	return nil
+}
+
+func (p *freezePlugin) AfterAction(ctx context.Context, req *action.ActionRequest, result *action.ActionResult) error {
+	p.results = append(p.results, result)
+	return nil
+}
+
+var loaded *freezePlugin
+
+func init() {
+	RegisterFactory("freeze", func() Plugin {
+		loaded = &freezePlugin{}
+		return loaded
+	})
+}
+
+func TestLoadAndHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+plugins:
+  - name: freeze
+    config:
+      service: checkout
+`), 0o644))
+
+	configs, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	m := NewManager(nil)
+	require.NoError(t, m.Load(configs))
+	assert.Equal(t, []map[string]string{{"name": "freeze", "version": "1.0"}}, m.ListPlugins())
+
+	err = m.ExecuteBeforeDecision(context.Background(), &models.ServiceFeatures{ServiceID: "checkout"})
+	assert.ErrorContains(t, err, "plugin freeze: service is frozen")
+	assert.NoError(t, m.ExecuteBeforeDecision(context.Background(), &models.ServiceFeatures{ServiceID: "payments"}))
+
+	svc := action.NewService(nil, "", true, nil)
+	svc.SetHooks(m)
+
+	_, err = svc.Execute(context.Background(), &action.ActionRequest{
+		ActionID: "act-1", DecisionID: "dec-1", ActionType: models.ActionTypeScaleUp, TargetService: "checkout",
+	})
+	assert.ErrorIs(t, err, action.ErrActionVetoed)
+
+	result, err := svc.Execute(context.Background(), &action.ActionRequest{
+		ActionID: "act-2", DecisionID: "dec-1", ActionType: models.ActionTypeScaleUp, TargetService: "payments",
+	})
+	require.NoError(t, err)
+	require.Len(t, loaded.results, 1)
+	assert.Same(t, result, loaded.results[0])
+
+	m.Shutdown()
+	assert.True(t, loaded.shutdown)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	m := NewManager(nil)
+	disabled := false
+	assert.NoError(t, m.Load([]Config{{Name: "freeze", Enabled: &disabled}}))
+	assert.Empty(t, m.ListPlugins())
+
+	assert.ErrorContains(t, m.Load([]Config{{Name: "missing"}}), "unknown plugin missing")
+	assert.ErrorContains(t, m.Load([]Config{{Name: "freeze"}}), "service is required")
+
+	path := filepath.Join(t.TempDir(), "plugins.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+plugins:
+  - name: freeze
+  - name: freeze
+`), 0o644))
+	_, err := LoadConfig(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,428 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// Hooks a process plugin may implement, as listed in its handshake
+const (
+	HookBeforeDecision = "before_decision"
+	HookAfterDecision  = "after_decision"
+	HookBeforeAction   = "before_action"
+	HookAfterAction    = "after_action"
+	HookFact           = "fact"
+)
+
+// ProcessOptions holds the settings shared by process plugins
+type ProcessOptions struct {
+	CallTimeout    time.Duration // per hook call
+	HealthInterval time.Duration // how often a running plugin is pinged, zero disables
+	MaxBackoff     time.Duration // longest wait between restarts of a crashed plugin
+}
+
+// DefaultProcessOptions returns the default process plugin settings
+func DefaultProcessOptions() ProcessOptions {
+	return ProcessOptions{
+		CallTimeout:    2 * time.Second,
+		HealthInterval: 10 * time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// handshakeResult is what a process plugin answers the handshake with
+type handshakeResult struct {
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	ProtocolVersion int      `json:"protocol_version"`
+	Hooks           []string `json:"hooks"`
+}
+
+// ProcessPlugin runs a plugin executable and calls it over newline-delimited
+// JSON-RPC 2.0 on its stdin and stdout; its stderr is logged. The plugin is
+// restarted with backoff when it exits or fails a health check. While it is
+// down, its before hooks allow everything unless the plugin fails closed.
+type ProcessPlugin struct {
+	name       string
+	command    string
+	args       []string
+	failClosed bool
+	options    ProcessOptions
+
+	config map[string]interface{}
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	conn    *rpcConn
+	cmd     *exec.Cmd
+	exited  chan struct{}
+	version string
+	hooks   map[string]bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewProcessPlugin creates a plugin that runs command. It starts on Initialize.
+func NewProcessPlugin(name, command string, args []string, failClosed bool, options ProcessOptions) *ProcessPlugin {
+	defaults := DefaultProcessOptions()
+	if options.CallTimeout <= 0 {
+		options.CallTimeout = defaults.CallTimeout
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaults.MaxBackoff
+	}
+	return &ProcessPlugin{
+		name:       name,
+		command:    command,
+		args:       args,
+		failClosed: failClosed,
+		options:    options,
+		logger:     slog.Default(),
+	}
+}
+
+// Name returns the configured name of the plugin
+func (p *ProcessPlugin) Name() string {
+	return p.name
+}
+
+// Version returns the version the plugin reported in its last handshake
+func (p *ProcessPlugin) Version() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.version
+}
+
+// Initialize starts the plugin and hands it config in the handshake
+func (p *ProcessPlugin) Initialize(config map[string]interface{}, logger *slog.Logger) error {
+	if logger != nil {
+		p.logger = logger
+	}
+	p.config = config
+
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.supervise()
+	return nil
+}
+
+// Shutdown asks the plugin to exit and kills it if it does not in time
+func (p *ProcessPlugin) Shutdown() error {
+	if p.stop == nil {
+		return nil
+	}
+	close(p.stop)
+	<-p.done
+
+	p.mu.RLock()
+	conn, cmd, exited := p.conn, p.cmd, p.exited
+	p.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.options.CallTimeout)
+	defer cancel()
+	err := conn.call(ctx, "shutdown", nil, nil)
+	conn.close(io.EOF)
+
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		<-exited
+	}
+	if err != nil && !errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return nil
+}
+
+// start launches the executable and performs the handshake
+func (p *ProcessPlugin) start() error {
+	cmd := exec.Command(p.command, p.args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin %s: %w", p.name, err)
+	}
+
+	go p.logOutput(stderr)
+	conn := newRPCConn(stdin, stdout)
+	exited := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		conn.close(fmt.Errorf("plugin exited: %v", err))
+		close(exited)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.options.CallTimeout)
+	defer cancel()
+	var hs handshakeResult
+	err = conn.call(ctx, "handshake", map[string]interface{}{
+		"protocol_version": ProtocolVersion,
+		"name":             p.name,
+		"config":           p.config,
+	}, &hs)
+	if err == nil && hs.ProtocolVersion != ProtocolVersion {
+		err = fmt.Errorf("plugin speaks protocol %d, want %d", hs.ProtocolVersion, ProtocolVersion)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		<-exited
+		return fmt.Errorf("plugin %s handshake failed: %w", p.name, err)
+	}
+
+	hooks := make(map[string]bool, len(hs.Hooks))
+	for _, h := range hs.Hooks {
+		hooks[h] = true
+	}
+
+	p.mu.Lock()
+	p.conn, p.cmd, p.exited = conn, cmd, exited
+	p.version = hs.Version
+	p.hooks = hooks
+	p.mu.Unlock()
+
+	p.logger.Info("process plugin started",
+		"name", p.name,
+		"version", hs.Version,
+		"pid", cmd.Process.Pid,
+		"hooks", hs.Hooks,
+	)
+	return nil
+}
+
+func (p *ProcessPlugin) logOutput(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.logger.Info("plugin output", "name", p.name, "line", scanner.Text())
+	}
+}
+
+// supervise restarts the plugin when it exits and kills it when it stops
+// answering health checks
+func (p *ProcessPlugin) supervise() {
+	defer close(p.done)
+
+	var health <-chan time.Time
+	if p.options.HealthInterval > 0 {
+		ticker := time.NewTicker(p.options.HealthInterval)
+		defer ticker.Stop()
+		health = ticker.C
+	}
+
+	initial := min(time.Second, p.options.MaxBackoff)
+	backoff := initial
+	startedAt := time.Now()
+	for {
+		p.mu.RLock()
+		cmd, exited := p.cmd, p.exited
+		p.mu.RUnlock()
+
+		select {
+		case <-p.stop:
+			return
+		case <-health:
+			if err := p.call(context.Background(), "health", nil, nil); err != nil {
+				p.logger.Warn("plugin failed health check, restarting", "name", p.name, "error", err)
+				cmd.Process.Kill()
+			}
+			continue
+		case <-exited:
+		}
+
+		// A plugin that stayed up for a while starts over with a short backoff
+		if time.Since(startedAt) > p.options.MaxBackoff {
+			backoff = initial
+		}
+		for {
+			p.logger.Warn("plugin exited, restarting", "name", p.name, "backoff", backoff)
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(backoff):
+			}
+			err := p.start()
+			backoff = min(backoff*2, p.options.MaxBackoff)
+			if err == nil {
+				startedAt = time.Now()
+				break
+			}
+			p.logger.Error("failed to restart plugin", "name", p.name, "error", err)
+		}
+	}
+}
+
+// call invokes method on the running plugin within the call timeout
+func (p *ProcessPlugin) call(ctx context.Context, method string, params, out interface{}) error {
+	p.mu.RLock()
+	conn := p.conn
+	p.mu.RUnlock()
+	if conn == nil {
+		return ErrUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.options.CallTimeout)
+	defer cancel()
+	return conn.call(ctx, method, params, out)
+}
+
+// implements reports whether the plugin declared hook in its handshake
+func (p *ProcessPlugin) implements(hook string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.hooks[hook]
+}
+
+// callHook calls a hook and maps failures to its outcome: an error the plugin
+// returns is passed on, and a plugin that is down or too slow only blocks
+// when it fails closed
+func (p *ProcessPlugin) callHook(ctx context.Context, hook string, params, out interface{}) error {
+	if !p.implements(hook) {
+		return nil
+	}
+	err := p.call(ctx, hook, params, out)
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		return err
+	}
+	if p.failClosed {
+		return fmt.Errorf("%s: %w", hook, err)
+	}
+	p.logger.Warn("plugin call failed, allowing", "name", p.name, "hook", hook, "error", err)
+	return nil
+}
+
+// BeforeDecision lets the plugin veto a decision or replace its features
+func (p *ProcessPlugin) BeforeDecision(ctx context.Context, features *models.ServiceFeatures) error {
+	var result struct {
+		Features *models.ServiceFeatures `json:"features"`
+	}
+	if err := p.callHook(ctx, HookBeforeDecision, map[string]interface{}{"features": features}, &result); err != nil {
+		return err
+	}
+	if result.Features != nil && features != nil {
+		*features = *result.Features
+	}
+	return nil
+}
+
+// AfterDecision lets the plugin replace a decision before it is stored
+func (p *ProcessPlugin) AfterDecision(ctx context.Context, decision *models.DecisionResponse) error {
+	var result struct {
+		Decision *models.DecisionResponse `json:"decision"`
+	}
+	if err := p.callHook(ctx, HookAfterDecision, map[string]interface{}{"decision": decision}, &result); err != nil {
+		return err
+	}
+	if result.Decision != nil {
+		*decision = *result.Decision
+	}
+	return nil
+}
+
+// BeforeAction lets the plugin veto an action or replace its request
+func (p *ProcessPlugin) BeforeAction(ctx context.Context, req *action.ActionRequest) error {
+	var result struct {
+		Action *action.ActionRequest `json:"action"`
+	}
+	if err := p.callHook(ctx, HookBeforeAction, map[string]interface{}{"action": req}, &result); err != nil {
+		return err
+	}
+	if result.Action != nil {
+		// The executor response is not sent to plugins, so keep it
+		response := req.Response
+		*req = *result.Action
+		req.Response = response
+	}
+	return nil
+}
+
+// AfterAction tells the plugin how an action went
+func (p *ProcessPlugin) AfterAction(ctx context.Context, req *action.ActionRequest, result *action.ActionResult) error {
+	return p.callHook(ctx, HookAfterAction, map[string]interface{}{"action": req, "result": result}, nil)
+}
+
+// Namespace returns the plugin name, under which its facts are referenced
+func (p *ProcessPlugin) Namespace() string {
+	return p.name
+}
+
+// Fact asks the plugin for one of its facts
+func (p *ProcessPlugin) Fact(ctx context.Context, serviceID, name string) (interface{}, error) {
+	var result struct {
+		Value interface{} `json:"value"`
+	}
+	err := p.call(ctx, HookFact, map[string]interface{}{"service_id": serviceID, "name": name}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Value, nil
+}
+
+// Discover adds a config for every executable in dir. An executable whose
+// name matches a configured plugin with no compiled-in factory runs that
+// plugin; the others are added enabled with no config.
+func Discover(dir string, configs []Config) ([]Config, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins dir: %w", err)
+	}
+
+	byName := make(map[string]int, len(configs))
+	for i, c := range configs {
+		byName[c.Name] = i
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	for _, file := range names {
+		name := file[:len(file)-len(filepath.Ext(file))]
+		path := filepath.Join(dir, file)
+		if i, ok := byName[name]; ok {
+			if _, compiled := factories[name]; !compiled && configs[i].Command == "" {
+				configs[i].Command = path
+			}
+			continue
+		}
+		byName[name] = len(configs)
+		configs = append(configs, Config{Name: name, Command: path})
+	}
+	return configs, nil
+}
@@ -0,0 +1,186 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/action"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperPlugin is not a real test: run with ADE_TEST_PLUGIN=1 it is a
+// process plugin that vetoes actions on its configured service, raises the
+// CPU it is shown, exits on actions targeting "crash" and stalls on "slow".
+// It reports a deploy in progress on its configured service.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("ADE_TEST_PLUGIN") != "1" {
+		return
+	}
+
+	frozen := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     uint64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+
+		var result interface{} = map[string]interface{}{}
+		var rpcErr *RPCError
+		switch req.Method {
+		case "handshake":
+			var params struct {
+				Config map[string]string `json:"config"`
+			}
+			json.Unmarshal(req.Params, &params)
+			frozen = params.Config["service"]
+			result = handshakeResult{Version: "0.1", ProtocolVersion: ProtocolVersion,
+				Hooks: []string{HookBeforeDecision, HookBeforeAction, HookFact}}
+		case HookFact:
+			var params struct {
+				ServiceID string `json:"service_id"`
+				Name      string `json:"name"`
+			}
+			json.Unmarshal(req.Params, &params)
+			if params.Name != "in_progress" {
+				rpcErr = &RPCError{Code: 2, Message: "unknown fact " + params.Name}
+				break
+			}
+			result = map[string]interface{}{"value": params.ServiceID == frozen}
+		case HookBeforeDecision:
+			var params struct {
+				Features models.ServiceFeatures `json:"features"`
+			}
+			json.Unmarshal(req.Params, &params)
+			params.Features.CPUCurrent = 99
+			result = params
+		case HookBeforeAction:
+			var params struct {
+				Action action.ActionRequest `json:"action"`
+			}
+			json.Unmarshal(req.Params, &params)
+			switch params.Action.TargetService {
+			case "crash":
+				os.Exit(3)
+			case "slow":
+				time.Sleep(time.Second)
+			case frozen:
+				rpcErr = &RPCError{Code: 1, Message: frozen + " is frozen"}
+			}
+		case "shutdown":
+			fmt.Printf(`{"jsonrpc":"2.0","id":%d,"result":{}}`+"\n", req.ID)
+			os.Exit(0)
+		}
+
+		data, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result, "error": rpcErr})
+		fmt.Println(string(data))
+	}
+	os.Exit(0)
+}
+
+func startHelper(t *testing.T, failClosed bool) *ProcessPlugin {
+	t.Setenv("ADE_TEST_PLUGIN", "1")
+	p := NewProcessPlugin("freeze", os.Args[0], []string{"-test.run=^TestHelperPlugin$"}, failClosed, ProcessOptions{
+		CallTimeout:    200 * time.Millisecond,
+		HealthInterval: 50 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	})
+	require.NoError(t, p.Initialize(map[string]interface{}{"service": "checkout"}, nil))
+	t.Cleanup(func() { p.Shutdown() })
+	return p
+}
+
+func actionOn(service string) *action.ActionRequest {
+	return &action.ActionRequest{ActionID: "act-1", DecisionID: "dec-1", ActionType: models.ActionTypeThrottle, TargetService: service}
+}
+
+func TestProcessPluginHooks(t *testing.T) {
+	p := startHelper(t, false)
+	ctx := context.Background()
+	assert.Equal(t, "0.1", p.Version())
+
+	err := p.BeforeAction(ctx, actionOn("checkout"))
+	var rpcErr *RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "checkout is frozen", rpcErr.Message)
+
+	req := actionOn("payments")
+	req.Response = json.RawMessage(`{"previous_replicas":3}`)
+	require.NoError(t, p.BeforeAction(ctx, req))
+	assert.Equal(t, "payments", req.TargetService)
+	assert.JSONEq(t, `{"previous_replicas":3}`, string(req.Response))
+
+	features := &models.ServiceFeatures{ServiceID: "payments", CPUCurrent: 10}
+	require.NoError(t, p.BeforeDecision(ctx, features))
+	assert.Equal(t, 99.0, features.CPUCurrent)
+
+	// Hooks the plugin did not declare are not called
+	assert.NoError(t, p.AfterAction(ctx, req, &action.ActionResult{}))
+}
+
+func TestProcessPluginFacts(t *testing.T) {
+	p := startHelper(t, false)
+	m := NewManager(nil)
+	m.plugins = append(m.plugins, p)
+	engine := policy.NewEngine(nil)
+	require.NoError(t, m.RegisterFactProviders(engine, policy.FactOptions{Timeout: time.Second}))
+
+	pol := &policy.Policy{ID: "deploys", Rules: []policy.Rule{{
+		ID:     "hold",
+		When:   policy.Condition{Fact: "freeze.in_progress", Op: "==", Value: true},
+		Action: policy.Action{Type: "open_circuit"},
+	}}}
+	result, _ := engine.Evaluate(context.Background(), pol, &models.ServiceFeatures{ServiceID: "checkout"})
+	assert.True(t, result.Matched)
+	assert.Equal(t, map[string]interface{}{"freeze.in_progress": true}, result.Facts)
+
+	result, _ = engine.Evaluate(context.Background(), pol, &models.ServiceFeatures{ServiceID: "payments"})
+	assert.False(t, result.Matched)
+}
+
+func TestProcessPluginTimeout(t *testing.T) {
+	open := startHelper(t, false)
+	assert.NoError(t, open.BeforeAction(context.Background(), actionOn("slow")))
+
+	closed := startHelper(t, true)
+	assert.ErrorIs(t, closed.BeforeAction(context.Background(), actionOn("slow")), context.DeadlineExceeded)
+}
+
+func TestProcessPluginRestartsAfterCrash(t *testing.T) {
+	p := startHelper(t, true)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, p.BeforeAction(ctx, actionOn("crash")), ErrUnavailable)
+
+	// The plugin comes back and enforces its veto again
+	require.Eventually(t, func() bool {
+		var rpcErr *RPCError
+		return errors.As(p.BeforeAction(ctx, actionOn("checkout")), &rpcErr)
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/enrich.py", []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(dir+"/veto", []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(dir+"/README", []byte("docs"), 0o644))
+
+	configs, err := Discover(dir, []Config{{Name: "veto", FailClosed: true}, {Name: "freeze"}})
+	require.NoError(t, err)
+	require.Len(t, configs, 3)
+	assert.Equal(t, dir+"/veto", configs[0].Command)
+	assert.True(t, configs[0].FailClosed)
+	assert.Empty(t, configs[1].Command)
+	assert.Equal(t, Config{Name: "enrich", Command: dir + "/enrich.py"}, configs[2])
+}
@@ -0,0 +1,167 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtocolVersion is the version of the process plugin protocol. A plugin
+// reports the version it speaks in its handshake and must match it.
+const ProtocolVersion = 1
+
+// ErrUnavailable is returned for calls to a process plugin that is not running
+var ErrUnavailable = errors.New("plugin unavailable")
+
+// RPCError is an error returned by a process plugin. From a before hook it
+// vetoes the decision or action.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// rpcRequest is a JSON-RPC 2.0 request, written as one line
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response, read as one line
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// rpcConn speaks newline-delimited JSON-RPC 2.0 over a plugin's stdin and
+// stdout. Calls may be concurrent; responses are matched to calls by ID.
+type rpcConn struct {
+	writeMu sync.Mutex
+	w       io.WriteCloser
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan *rpcResponse
+	err     error // why the connection closed
+
+	closed chan struct{}
+}
+
+// newRPCConn starts reading responses from r
+func newRPCConn(w io.WriteCloser, r io.Reader) *rpcConn {
+	c := &rpcConn{
+		w:       w,
+		pending: make(map[uint64]chan *rpcResponse),
+		closed:  make(chan struct{}),
+	}
+	go c.read(r)
+	return c
+}
+
+func (c *rpcConn) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	err := io.EOF
+	for scanner.Scan() {
+		var resp rpcResponse
+		if jsonErr := json.Unmarshal(scanner.Bytes(), &resp); jsonErr != nil {
+			err = fmt.Errorf("invalid response: %w", jsonErr)
+			break
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[resp.ID]
+		delete(c.pending, resp.ID)
+		c.mu.Unlock()
+		// Responses to calls that timed out are dropped
+		if ok {
+			ch <- &resp
+		}
+	}
+	if scanner.Err() != nil {
+		err = scanner.Err()
+	}
+	c.close(err)
+}
+
+// close fails every pending call with err
+func (c *rpcConn) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	close(c.closed)
+	c.w.Close()
+}
+
+// call sends a request and decodes the result into out, which may be nil
+func (c *rpcConn) call(ctx context.Context, method string, params, out interface{}) error {
+	ch := make(chan *rpcResponse, 1)
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %v", ErrUnavailable, c.err)
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		c.forget(id)
+		return fmt.Errorf("failed to encode %s: %w", method, err)
+	}
+
+	c.writeMu.Lock()
+	_, err = c.w.Write(append(data, '\n'))
+	c.writeMu.Unlock()
+	if err != nil {
+		c.forget(id)
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return fmt.Errorf("%w: plugin exited during %s", ErrUnavailable, method)
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if out != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, out); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.forget(id)
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+func (c *rpcConn) forget(id uint64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/models"
 )
 
-// Engine evaluates policies against features
+// Engine evaluates policies against features and provided facts
 type Engine struct {
 	logger *slog.Logger
+
+	mu        sync.RWMutex
+	providers map[string]*registeredProvider
+	calendars *Calendars
+	now       func() time.Time
 }
 
 // NewEngine creates a new policy engine
@@ -20,7 +26,21 @@ func NewEngine(logger *slog.Logger) *Engine {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Engine{logger: logger}
+	return &Engine{logger: logger, providers: make(map[string]*registeredProvider), now: time.Now}
+}
+
+// SetCalendars sets the named calendars time conditions may refer to
+func (e *Engine) SetCalendars(calendars *Calendars) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calendars = calendars
+}
+
+// SetClock sets the clock that gives the time of each evaluation
+func (e *Engine) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
 }
 
 // EvaluationResult represents the result of policy evaluation
@@ -32,10 +52,30 @@ type EvaluationResult struct {
 	Reason        string            `json:"reason,omitempty"`
 	Confidence    float64           `json:"confidence"`
 	EvaluatedAt   time.Time         `json:"evaluated_at"`
+	Facts         map[string]interface{} `json:"facts,omitempty"` // provided facts the evaluation used
 }
 
-// Evaluate evaluates a policy against service features
+// Evaluate evaluates a policy against service features at the time of the
+// engine's clock. Provided facts are resolved as rules reference them and
+// returned in the final result's Facts.
 func (e *Engine) Evaluate(ctx context.Context, policy *Policy, features *models.ServiceFeatures) (*EvaluationResult, []EvaluationResult) {
+	e.mu.RLock()
+	now := e.now()
+	e.mu.RUnlock()
+	return e.evaluate(ctx, policy, e.newFacts(ctx, features, now, nil))
+}
+
+// EvaluateRecorded evaluates a policy at the time and with the provided facts
+// of an earlier evaluation instead of asking the clock and the providers, so a
+// replay sees what the original decision saw
+func (e *Engine) EvaluateRecorded(ctx context.Context, policy *Policy, features *models.ServiceFeatures, at time.Time, recorded map[string]interface{}) (*EvaluationResult, []EvaluationResult) {
+	if recorded == nil {
+		recorded = map[string]interface{}{}
+	}
+	return e.evaluate(ctx, policy, e.newFacts(ctx, features, at, recorded))
+}
+
+func (e *Engine) evaluate(ctx context.Context, policy *Policy, facts *facts) (*EvaluationResult, []EvaluationResult) {
 	start := time.Now()
 	allResults := make([]EvaluationResult, 0, len(policy.Rules))
 
@@ -52,8 +92,8 @@ func (e *Engine) Evaluate(ctx context.Context, policy *Policy, features *models.
 
 	// Evaluate each rule
 	for _, rule := range sortedRules {
-		result := e.evaluateRule(&rule, features)
-		result.EvaluatedAt = time.Now()
+		result := e.evaluateRule(&rule, facts)
+		result.EvaluatedAt = facts.at
 		allResults = append(allResults, result)
 
 		if result.Matched {
@@ -64,6 +104,7 @@ func (e *Engine) Evaluate(ctx context.Context, policy *Policy, features *models.
 				"action", rule.Action.Type,
 				"duration_ms", time.Since(start).Milliseconds(),
 			)
+			result.Facts = facts.recordedFacts()
 			return &result, allResults
 		}
 	}
@@ -73,12 +114,13 @@ func (e *Engine) Evaluate(ctx context.Context, policy *Policy, features *models.
 		Matched:     false,
 		Reason:      "no rules matched",
 		Confidence:  1.0,
-		EvaluatedAt: time.Now(),
+		EvaluatedAt: facts.at,
+		Facts:       facts.recordedFacts(),
 	}, allResults
 }
 
-func (e *Engine) evaluateRule(rule *Rule, features *models.ServiceFeatures) EvaluationResult {
-	matched := e.evaluateCondition(&rule.When, features)
+func (e *Engine) evaluateRule(rule *Rule, facts *facts) EvaluationResult {
+	matched := e.evaluateCondition(&rule.When, facts)
 
 	if !matched {
 		return EvaluationResult{
@@ -96,15 +138,15 @@ func (e *Engine) evaluateRule(rule *Rule, features *models.ServiceFeatures) Eval
 		Action:        actionType,
 		ActionPayload: rule.Action.Params,
 		Reason:        fmt.Sprintf("condition matched for rule %s", rule.ID),
-		Confidence:    calculateConfidence(rule, features),
+		Confidence:    calculateConfidence(rule, facts.features),
 	}
 }
 
-func (e *Engine) evaluateCondition(cond *Condition, features *models.ServiceFeatures) bool {
+func (e *Engine) evaluateCondition(cond *Condition, facts *facts) bool {
 	// Handle compound conditions
 	if len(cond.All) > 0 {
 		for _, c := range cond.All {
-			if !e.evaluateCondition(&c, features) {
+			if !e.evaluateCondition(&c, facts) {
 				return false
 			}
 		}
@@ -113,7 +155,7 @@ func (e *Engine) evaluateCondition(cond *Condition, features *models.ServiceFeat
 
 	if len(cond.Any) > 0 {
 		for _, c := range cond.Any {
-			if e.evaluateCondition(&c, features) {
+			if e.evaluateCondition(&c, facts) {
 				return true
 			}
 		}
@@ -121,7 +163,11 @@ func (e *Engine) evaluateCondition(cond *Condition, features *models.ServiceFeat
 	}
 
 	if cond.Not != nil {
-		return !e.evaluateCondition(cond.Not, features)
+		return !e.evaluateCondition(cond.Not, facts)
+	}
+
+	if !e.timeMatches(cond, facts.at) {
+		return false
 	}
 
 	// Simple condition evaluation
@@ -129,7 +175,7 @@ func (e *Engine) evaluateCondition(cond *Condition, features *models.ServiceFeat
 		return true
 	}
 
-	factValue := getFactValue(cond.Fact, features)
+	factValue := facts.value(cond.Fact)
 	if factValue == nil {
 		return false
 	}
@@ -137,6 +183,49 @@ func (e *Engine) evaluateCondition(cond *Condition, features *models.ServiceFeat
 	return compare(factValue, cond.Op, cond.Value)
 }
 
+// timeMatches checks the time conditions of cond against at. A window that
+// cannot be checked, such as an unknown calendar, matches neither way.
+func (e *Engine) timeMatches(cond *Condition, at time.Time) bool {
+	if cond.During != nil {
+		in, err := e.windowContains(cond.During, at)
+		if err != nil {
+			e.logWindowError(cond.During, err)
+		}
+		if err != nil || !in {
+			return false
+		}
+	}
+	if cond.Outside != nil {
+		in, err := e.windowContains(cond.Outside, at)
+		if err != nil {
+			e.logWindowError(cond.Outside, err)
+		}
+		if err != nil || in {
+			return false
+		}
+	}
+	return true
+}
+
+// windowContains reports whether at falls in a cron window or a named calendar
+func (e *Engine) windowContains(w *Window, at time.Time) (bool, error) {
+	if w.Calendar == "" {
+		return w.contains(at)
+	}
+	e.mu.RLock()
+	calendars := e.calendars
+	e.mu.RUnlock()
+	return calendars.Contains(w.Calendar, at)
+}
+
+func (e *Engine) logWindowError(w *Window, err error) {
+	e.logger.Warn("failed to check time window",
+		"cron", w.Cron,
+		"calendar", w.Calendar,
+		"error", err,
+	)
+}
+
 func getFactValue(fact string, features *models.ServiceFeatures) interface{} {
 	v := reflect.ValueOf(features).Elem()
 	field := v.FieldByName(fact)
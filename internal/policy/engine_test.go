@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -248,7 +249,7 @@ func TestCompoundConditions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := engine.evaluateCondition(&tt.cond, tt.features)
+			got := engine.evaluateCondition(&tt.cond, engine.newFacts(context.Background(), tt.features, time.Now(), nil))
 			assert.Equal(t, tt.want, got)
 		})
 	}
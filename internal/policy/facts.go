@@ -0,0 +1,251 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// FactProvider contributes facts beside the service features. Its facts are
+// referenced in policies as "<namespace>.<name>", e.g. deploy.in_progress.
+type FactProvider interface {
+	Namespace() string
+	Fact(ctx context.Context, serviceID, name string) (interface{}, error)
+}
+
+// FactOptions bound how a provider's facts are resolved
+type FactOptions struct {
+	Timeout time.Duration // per fact lookup; a fact that times out is missing
+	TTL     time.Duration // how long a value is reused for the same service, zero disables caching
+}
+
+// DefaultFactOptions returns the default fact resolution settings
+func DefaultFactOptions() FactOptions {
+	return FactOptions{Timeout: 500 * time.Millisecond, TTL: 30 * time.Second}
+}
+
+// registeredProvider is a provider with its options and cached values
+type registeredProvider struct {
+	provider FactProvider
+	options  FactOptions
+
+	mu    sync.Mutex
+	cache map[string]cachedFact
+}
+
+type cachedFact struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// RegisterFactProvider makes a provider's facts available to policies
+func (e *Engine) RegisterFactProvider(provider FactProvider, options FactOptions) error {
+	namespace := provider.Namespace()
+	if namespace == "" || strings.Contains(namespace, ".") {
+		return fmt.Errorf("invalid fact namespace %q", namespace)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultFactOptions().Timeout
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.providers[namespace]; ok {
+		return fmt.Errorf("fact namespace %s already registered", namespace)
+	}
+	e.providers[namespace] = &registeredProvider{
+		provider: provider,
+		options:  options,
+		cache:    make(map[string]cachedFact),
+	}
+	e.logger.Info("fact provider registered", "namespace", namespace)
+	return nil
+}
+
+// resolve looks a fact up, from the cache when it is fresh
+func (r *registeredProvider) resolve(ctx context.Context, serviceID, name string) (interface{}, error) {
+	key := serviceID + "\x00" + name
+	if r.options.TTL > 0 {
+		r.mu.Lock()
+		cached, ok := r.cache[key]
+		r.mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached.value, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.options.Timeout)
+	defer cancel()
+
+	type lookup struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan lookup, 1)
+	go func() {
+		value, err := r.provider.Fact(ctx, serviceID, name)
+		done <- lookup{value, err}
+	}()
+
+	// Providers that ignore ctx cannot hold up the evaluation
+	var l lookup
+	select {
+	case l = <-done:
+	case <-ctx.Done():
+		l.err = ctx.Err()
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	if r.options.TTL > 0 {
+		r.mu.Lock()
+		r.cache[key] = cachedFact{value: l.value, expiresAt: time.Now().Add(r.options.TTL)}
+		r.mu.Unlock()
+	}
+	return l.value, nil
+}
+
+// facts resolves the facts of one evaluation. Each fact is looked up at most
+// once, and every provided fact used is recorded so the evaluation can be
+// replayed with the same values.
+type facts struct {
+	ctx      context.Context
+	engine   *Engine
+	features *models.ServiceFeatures
+	at       time.Time              // time of the decision, which time conditions are checked against
+	recorded map[string]interface{} // when set, the only source of provided facts
+	used     map[string]interface{}
+}
+
+func (e *Engine) newFacts(ctx context.Context, features *models.ServiceFeatures, at time.Time, recorded map[string]interface{}) *facts {
+	return &facts{
+		ctx:      ctx,
+		engine:   e,
+		features: features,
+		at:       at,
+		recorded: recorded,
+		used:     make(map[string]interface{}),
+	}
+}
+
+// value returns a fact, or nil when it is unknown or could not be resolved
+func (f *facts) value(fact string) interface{} {
+	namespace, name, provided := strings.Cut(fact, ".")
+	if !provided {
+		return getFactValue(fact, f.features)
+	}
+
+	if v, ok := f.used[fact]; ok {
+		return v
+	}
+	if f.recorded != nil {
+		v := f.recorded[fact]
+		f.used[fact] = v
+		return v
+	}
+
+	f.engine.mu.RLock()
+	provider, ok := f.engine.providers[namespace]
+	f.engine.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	v, err := provider.resolve(f.ctx, f.features.ServiceID, name)
+	if err != nil {
+		f.engine.logger.Warn("failed to resolve fact",
+			"fact", fact,
+			"service_id", f.features.ServiceID,
+			"error", err,
+		)
+	}
+	// A fact that failed is recorded as missing so a replay sees the same
+	f.used[fact] = v
+	return v
+}
+
+// recordedFacts returns the provided facts used so far, or nil when there were none
+func (f *facts) recordedFacts() map[string]interface{} {
+	if len(f.used) == 0 {
+		return nil
+	}
+	used := make(map[string]interface{}, len(f.used))
+	for k, v := range f.used {
+		used[k] = v
+	}
+	return used
+}
+
+// TimeProvider provides the current time as time.hour_utc, time.minute_utc
+// and time.weekday_utc (0 is Sunday)
+type TimeProvider struct {
+	now func() time.Time
+}
+
+// NewTimeProvider creates a time fact provider
+func NewTimeProvider() *TimeProvider {
+	return &TimeProvider{now: time.Now}
+}
+
+// Namespace returns "time"
+func (p *TimeProvider) Namespace() string {
+	return "time"
+}
+
+// Fact returns a time fact
+func (p *TimeProvider) Fact(ctx context.Context, serviceID, name string) (interface{}, error) {
+	now := p.now().UTC()
+	switch name {
+	case "hour_utc":
+		return now.Hour(), nil
+	case "minute_utc":
+		return now.Minute(), nil
+	case "weekday_utc":
+		return int(now.Weekday()), nil
+	}
+	return nil, fmt.Errorf("unknown fact time.%s", name)
+}
+
+// CalendarProvider provides calendar.is_business_hours and
+// calendar.is_weekend in a configured time zone. Business hours run from
+// start to end hour, Monday to Friday.
+type CalendarProvider struct {
+	location   *time.Location
+	start, end int
+	now        func() time.Time
+}
+
+// NewCalendarProvider creates a calendar fact provider
+func NewCalendarProvider(timezone string, start, end int) (*CalendarProvider, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business time zone: %w", err)
+	}
+	if start < 0 || end > 24 || start >= end {
+		return nil, fmt.Errorf("invalid business hours %d-%d", start, end)
+	}
+	return &CalendarProvider{location: location, start: start, end: end, now: time.Now}, nil
+}
+
+// Namespace returns "calendar"
+func (p *CalendarProvider) Namespace() string {
+	return "calendar"
+}
+
+// Fact returns a calendar fact
+func (p *CalendarProvider) Fact(ctx context.Context, serviceID, name string) (interface{}, error) {
+	now := p.now().In(p.location)
+	weekend := now.Weekday() == time.Saturday || now.Weekday() == time.Sunday
+	switch name {
+	case "is_business_hours":
+		return !weekend && now.Hour() >= p.start && now.Hour() < p.end, nil
+	case "is_weekend":
+		return weekend, nil
+	}
+	return nil, fmt.Errorf("unknown fact calendar.%s", name)
+}
@@ -0,0 +1,128 @@
+package policy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deployProvider reports a deploy in progress on the services it lists
+type deployProvider struct {
+	deploying map[string]bool
+	delay     time.Duration
+	lookups   atomic.Int32
+}
+
+func (p *deployProvider) Namespace() string {
+	return "deploy"
+}
+
+func (p *deployProvider) Fact(ctx context.Context, serviceID, name string) (interface{}, error) {
+	p.lookups.Add(1)
+	time.Sleep(p.delay)
+	return p.deploying[serviceID], nil
+}
+
+func deployPolicy() *Policy {
+	return &Policy{
+		ID:      "deploys",
+		Version: "1.0",
+		Rules: []Rule{{
+			ID: "hold_during_deploy",
+			When: Condition{All: []Condition{
+				{Fact: "cpu", Op: ">=", Value: 80.0},
+				{Fact: "deploy.in_progress", Op: "==", Value: true},
+			}},
+			Action: Action{Type: "open_circuit"},
+		}},
+	}
+}
+
+func TestEvaluateProvidedFacts(t *testing.T) {
+	engine := NewEngine(nil)
+	provider := &deployProvider{deploying: map[string]bool{"checkout": true}}
+	require.NoError(t, engine.RegisterFactProvider(provider, FactOptions{Timeout: time.Second, TTL: time.Minute}))
+	ctx := context.Background()
+
+	result, _ := engine.Evaluate(ctx, deployPolicy(), &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 90})
+	assert.True(t, result.Matched)
+	assert.Equal(t, map[string]interface{}{"deploy.in_progress": true}, result.Facts)
+
+	// Facts are resolved lazily: a rule that fails first never asks
+	result, _ = engine.Evaluate(ctx, deployPolicy(), &models.ServiceFeatures{ServiceID: "payments", CPUCurrent: 10})
+	assert.False(t, result.Matched)
+	assert.Nil(t, result.Facts)
+	assert.Equal(t, int32(1), provider.lookups.Load())
+
+	// Cached values are reused for the same service
+	engine.Evaluate(ctx, deployPolicy(), &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 90})
+	assert.Equal(t, int32(1), provider.lookups.Load())
+}
+
+func TestEvaluateFactTimeout(t *testing.T) {
+	engine := NewEngine(nil)
+	provider := &deployProvider{deploying: map[string]bool{"checkout": true}, delay: 200 * time.Millisecond}
+	require.NoError(t, engine.RegisterFactProvider(provider, FactOptions{Timeout: 20 * time.Millisecond}))
+
+	start := time.Now()
+	result, _ := engine.Evaluate(context.Background(), deployPolicy(), &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 90})
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+	assert.False(t, result.Matched)
+
+	// A fact that timed out is recorded as missing
+	assert.Equal(t, map[string]interface{}{"deploy.in_progress": nil}, result.Facts)
+}
+
+func TestEvaluateRecorded(t *testing.T) {
+	engine := NewEngine(nil)
+	provider := &deployProvider{}
+	require.NoError(t, engine.RegisterFactProvider(provider, DefaultFactOptions()))
+
+	features := &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 90}
+	result, _ := engine.EvaluateRecorded(context.Background(), deployPolicy(), features, time.Now(), map[string]interface{}{"deploy.in_progress": true})
+	assert.True(t, result.Matched)
+	assert.Equal(t, int32(0), provider.lookups.Load())
+}
+
+func TestRegisterFactProvider(t *testing.T) {
+	engine := NewEngine(nil)
+	require.NoError(t, engine.RegisterFactProvider(NewTimeProvider(), FactOptions{}))
+	assert.Error(t, engine.RegisterFactProvider(NewTimeProvider(), FactOptions{}))
+	assert.Error(t, engine.RegisterFactProvider(&namedProvider{"slo.budget"}, FactOptions{}))
+}
+
+type namedProvider struct{ namespace string }
+
+func (p *namedProvider) Namespace() string { return p.namespace }
+
+func (p *namedProvider) Fact(ctx context.Context, serviceID, name string) (interface{}, error) {
+	return nil, nil
+}
+
+func TestCalendarProvider(t *testing.T) {
+	p, err := NewCalendarProvider("UTC", 9, 17)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	p.now = func() time.Time { return time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC) } // Wednesday
+	v, err := p.Fact(ctx, "checkout", "is_business_hours")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	p.now = func() time.Time { return time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC) } // Saturday
+	v, _ = p.Fact(ctx, "checkout", "is_business_hours")
+	assert.Equal(t, false, v)
+	v, _ = p.Fact(ctx, "checkout", "is_weekend")
+	assert.Equal(t, true, v)
+
+	_, err = p.Fact(ctx, "checkout", "is_holiday")
+	assert.Error(t, err)
+
+	_, err = NewCalendarProvider("UTC", 17, 9)
+	assert.Error(t, err)
+}
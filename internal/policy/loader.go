@@ -3,6 +3,8 @@ package policy
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,7 +21,7 @@ func LoadPolicy(path string) (*Policy, error) {
 		return nil, fmt.Errorf("failed to parse policy: %w", err)
 	}
 
-	if err := policy.Validate(); err != nil {
+	if err := validateLoaded(&policy); err != nil {
 		return nil, fmt.Errorf("invalid policy: %w", err)
 	}
 
@@ -33,9 +35,44 @@ func LoadPolicyFromBytes(data []byte) (*Policy, error) {
 		return nil, fmt.Errorf("failed to parse policy: %w", err)
 	}
 
-	if err := policy.Validate(); err != nil {
+	if err := validateLoaded(&policy); err != nil {
 		return nil, fmt.Errorf("invalid policy: %w", err)
 	}
 
 	return &policy, nil
 }
+
+// LoadPolicies loads every .yaml and .yml policy in a directory
+func LoadPolicies(dir string) ([]*Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	policies := make([]*Policy, 0, len(names))
+	for _, name := range names {
+		p, err := LoadPolicy(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// validateLoaded validates a policy as loaded. A policy that extends another
+// is validated in full when the registry resolves it.
+func validateLoaded(p *Policy) error {
+	if p.Extends != "" {
+		return p.validateExtension()
+	}
+	return p.Validate()
+}
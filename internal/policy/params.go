@@ -0,0 +1,282 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Param types
+const (
+	ParamTypeNumber   = "number"
+	ParamTypeInteger  = "integer"
+	ParamTypeString   = "string"
+	ParamTypeBool     = "bool"
+	ParamTypeDuration = "duration"
+)
+
+// ErrInvalidParams is returned for param overrides a policy does not accept
+var ErrInvalidParams = errors.New("invalid policy params")
+
+// paramRef matches references to params such as ${params.cpu_high}
+var paramRef = regexp.MustCompile(`\$\{params\.([A-Za-z0-9_]+)\}`)
+
+// Param declares a policy parameter. A param without a default must be
+// supplied by a binding or the evaluate request.
+type Param struct {
+	Type        string      `yaml:"type" json:"type"`
+	Default     interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	Min         *float64    `yaml:"min,omitempty" json:"min,omitempty"` // for numbers and integers
+	Max         *float64    `yaml:"max,omitempty" json:"max,omitempty"`
+	Enum        []string    `yaml:"enum,omitempty" json:"enum,omitempty"` // allowed strings
+	Description string      `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Coerce converts v to the param's type and checks it against its range
+func (p *Param) Coerce(v interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParamTypeNumber, ParamTypeInteger:
+		n := toFloat64(v)
+		if n == nil {
+			return nil, fmt.Errorf("%v is not a number", v)
+		}
+		if p.Min != nil && *n < *p.Min {
+			return nil, fmt.Errorf("%v is below the minimum of %v", *n, *p.Min)
+		}
+		if p.Max != nil && *n > *p.Max {
+			return nil, fmt.Errorf("%v is above the maximum of %v", *n, *p.Max)
+		}
+		if p.Type == ParamTypeNumber {
+			return *n, nil
+		}
+		if *n != math.Trunc(*n) {
+			return nil, fmt.Errorf("%v is not an integer", *n)
+		}
+		return int(*n), nil
+	case ParamTypeString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", v)
+		}
+		if len(p.Enum) > 0 {
+			for _, allowed := range p.Enum {
+				if s == allowed {
+					return s, nil
+				}
+			}
+			return nil, fmt.Errorf("%q is not one of %v", s, p.Enum)
+		}
+		return s, nil
+	case ParamTypeBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a bool", v)
+		}
+		return b, nil
+	case ParamTypeDuration:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a duration", v)
+		}
+		if _, err := time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("%q is not a duration", s)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown param type %q", p.Type)
+}
+
+// validateParams checks the param declarations and that every param the
+// rules reference is declared
+func (p *Policy) validateParams() error {
+	for name, param := range p.Params {
+		if _, err := (&Param{Type: param.Type}).Coerce(zeroOf(param.Type)); err != nil {
+			return &PolicyValidationError{Field: "params." + name, Message: err.Error()}
+		}
+		if param.Default != nil {
+			if _, err := param.Coerce(param.Default); err != nil {
+				return &PolicyValidationError{Field: "params." + name, Message: "invalid default: " + err.Error()}
+			}
+		}
+	}
+	for _, rule := range p.Rules {
+		for _, name := range referencedParams(rule) {
+			if _, ok := p.Params[name]; !ok {
+				return &PolicyValidationError{Field: "rule.params", Message: fmt.Sprintf("rule %s references undeclared param %s", rule.ID, name)}
+			}
+		}
+	}
+	return nil
+}
+
+// zeroOf returns a value every param of a type accepts, for checking the type
+func zeroOf(paramType string) interface{} {
+	switch paramType {
+	case ParamTypeNumber, ParamTypeInteger:
+		return 0
+	case ParamTypeBool:
+		return false
+	case ParamTypeDuration:
+		return "0s"
+	}
+	return ""
+}
+
+// ResolveParams returns the value of every param, from overrides or the
+// defaults. Overrides of undeclared params, values of the wrong type or out of
+// range, and missing params without a default fail with ErrInvalidParams.
+func (p *Policy) ResolveParams(overrides map[string]interface{}) (map[string]interface{}, error) {
+	for name := range overrides {
+		if _, ok := p.Params[name]; !ok {
+			return nil, fmt.Errorf("%w: %s does not declare param %s", ErrInvalidParams, p.ID, name)
+		}
+	}
+
+	values := make(map[string]interface{}, len(p.Params))
+	for name, param := range p.Params {
+		v, ok := overrides[name]
+		if !ok {
+			v = param.Default
+		}
+		if v == nil {
+			return nil, fmt.Errorf("%w: param %s of %s is required", ErrInvalidParams, name, p.ID)
+		}
+		coerced, err := param.Coerce(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: param %s: %v", ErrInvalidParams, name, err)
+		}
+		values[name] = coerced
+	}
+	return values, nil
+}
+
+// DeclaredParams returns the overrides of the params the policy declares
+func (p *Policy) DeclaredParams(overrides map[string]interface{}) map[string]interface{} {
+	declared := make(map[string]interface{})
+	for name, v := range overrides {
+		if _, ok := p.Params[name]; ok {
+			declared[name] = v
+		}
+	}
+	return declared
+}
+
+// WithParams returns a copy of the policy with every ${params.name} in rule
+// conditions and action params replaced by its value. A reference that is the
+// whole value takes the param's type; one inside a string is formatted into it.
+func (p *Policy) WithParams(values map[string]interface{}) *Policy {
+	if len(p.Params) == 0 {
+		return p
+	}
+	resolved := *p
+	resolved.Rules = make([]Rule, len(p.Rules))
+	for i, rule := range p.Rules {
+		rule.When = substituteCondition(rule.When, values)
+		if rule.Action.Params != nil {
+			rule.Action.Params = substitute(rule.Action.Params, values).(map[string]interface{})
+		}
+		resolved.Rules[i] = rule
+	}
+	return &resolved
+}
+
+func substituteCondition(c Condition, values map[string]interface{}) Condition {
+	c.Value = substitute(c.Value, values)
+	if c.All != nil {
+		all := make([]Condition, len(c.All))
+		for i := range c.All {
+			all[i] = substituteCondition(c.All[i], values)
+		}
+		c.All = all
+	}
+	if c.Any != nil {
+		any := make([]Condition, len(c.Any))
+		for i := range c.Any {
+			any[i] = substituteCondition(c.Any[i], values)
+		}
+		c.Any = any
+	}
+	if c.Not != nil {
+		not := substituteCondition(*c.Not, values)
+		c.Not = &not
+	}
+	return c
+}
+
+func substitute(v interface{}, values map[string]interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		if m := paramRef.FindStringSubmatch(val); m != nil && m[0] == val {
+			if param, ok := values[m[1]]; ok {
+				return param
+			}
+			return val
+		}
+		return paramRef.ReplaceAllStringFunc(val, func(ref string) string {
+			if param, ok := values[paramRef.FindStringSubmatch(ref)[1]]; ok {
+				return fmt.Sprint(param)
+			}
+			return ref
+		})
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = substitute(item, values)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, item := range val {
+			s[i] = substitute(item, values)
+		}
+		return s
+	}
+	return v
+}
+
+// referencedParams returns the params a rule references, sorted
+func referencedParams(rule Rule) []string {
+	seen := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case string:
+			for _, m := range paramRef.FindAllStringSubmatch(val, -1) {
+				seen[m[1]] = true
+			}
+		case map[string]interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	var walkCondition func(c *Condition)
+	walkCondition = func(c *Condition) {
+		walk(c.Value)
+		for i := range c.All {
+			walkCondition(&c.All[i])
+		}
+		for i := range c.Any {
+			walkCondition(&c.Any[i])
+		}
+		if c.Not != nil {
+			walkCondition(c.Not)
+		}
+	}
+	walkCondition(&rule.When)
+	walk(rule.Action.Params)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const tieredPolicy = `
+id: autoscale_tiered
+version: "1.0"
+name: Tiered Auto-Scaling
+type: autoscale
+params:
+  cpu_high:
+    type: number
+    default: 90
+    min: 50
+    max: 100
+  max_instances:
+    type: integer
+    default: 10
+  tier:
+    type: string
+    default: standard
+    enum: [standard, gold]
+rules:
+  - id: scale_up
+    name: Scale Up
+    when:
+      fact: cpu
+      op: ">="
+      value: ${params.cpu_high}
+    action:
+      type: scale_up
+      params:
+        max_instances: ${params.max_instances}
+        reason: "cpu above ${params.cpu_high} on ${params.tier}"
+`
+
+func loadTiered(t *testing.T) *Policy {
+	pol, err := LoadPolicyFromBytes([]byte(tieredPolicy))
+	require.NoError(t, err)
+	return pol
+}
+
+func TestResolveParams(t *testing.T) {
+	pol := loadTiered(t)
+
+	values, err := pol.ResolveParams(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cpu_high": 90.0, "max_instances": 10, "tier": "standard"}, values)
+
+	// JSON numbers arrive as float64
+	values, err = pol.ResolveParams(map[string]interface{}{"cpu_high": 70, "max_instances": 30.0, "tier": "gold"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cpu_high": 70.0, "max_instances": 30, "tier": "gold"}, values)
+
+	for _, overrides := range []map[string]interface{}{
+		{"cpu_high": 40},
+		{"cpu_high": "high"},
+		{"max_instances": 2.5},
+		{"tier": "platinum"},
+		{"memory_high": 80},
+	} {
+		_, err := pol.ResolveParams(overrides)
+		assert.ErrorIs(t, err, ErrInvalidParams, "%v", overrides)
+	}
+}
+
+func TestWithParams(t *testing.T) {
+	pol := loadTiered(t)
+	values, err := pol.ResolveParams(map[string]interface{}{"cpu_high": 70})
+	require.NoError(t, err)
+
+	resolved := pol.WithParams(values)
+	assert.Equal(t, 70.0, resolved.Rules[0].When.Value)
+	assert.Equal(t, 10, resolved.Rules[0].Action.Params["max_instances"])
+	assert.Equal(t, "cpu above 70 on standard", resolved.Rules[0].Action.Params["reason"])
+
+	// The loaded policy keeps its references
+	assert.Equal(t, "${params.cpu_high}", pol.Rules[0].When.Value)
+
+	engine := NewEngine(nil)
+	result, _ := engine.Evaluate(context.Background(), resolved, &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 75})
+	assert.True(t, result.Matched)
+	result, _ = engine.Evaluate(context.Background(), pol.WithParams(map[string]interface{}{"cpu_high": 90.0, "max_instances": 10, "tier": "gold"}),
+		&models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 75})
+	assert.False(t, result.Matched)
+}
+
+func TestValidateParams(t *testing.T) {
+	var pol Policy
+	require.NoError(t, yaml.Unmarshal([]byte(tieredPolicy), &pol))
+	delete(pol.Params, "tier")
+	assert.Error(t, pol.Validate(), "undeclared param")
+
+	pol.Params["tier"] = Param{Type: "color"}
+	assert.Error(t, pol.Validate(), "unknown type")
+
+	pol.Params["tier"] = Param{Type: ParamTypeString, Default: 3}
+	assert.Error(t, pol.Validate(), "invalid default")
+}
+
+func TestExtendParams(t *testing.T) {
+	r := NewRegistry()
+	gold := &Policy{ID: "autoscale_gold", Version: "1.0", Extends: "autoscale_tiered",
+		Params: map[string]Param{"cpu_high": {Default: 75}}}
+	require.NoError(t, r.AddAll([]*Policy{loadTiered(t), gold}))
+
+	resolved, err := r.Get("autoscale_gold")
+	require.NoError(t, err)
+	values, err := resolved.ResolveParams(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 75.0, values["cpu_high"])
+	assert.Equal(t, ParamTypeNumber, resolved.Params["cpu_high"].Type)
+
+	require.NoError(t, r.SetBindings([]Binding{{
+		Selector: Selector{Service: "checkout"},
+		Policies: []string{"autoscale_gold"},
+		Params:   map[string]interface{}{"max_instances": 40},
+	}}))
+	bound, err := r.BoundOfType("checkout", nil, "autoscale")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"max_instances": 40}, bound.Params)
+
+	assert.ErrorIs(t, r.SetBindings([]Binding{{Policies: []string{"autoscale_gold"}, Params: map[string]interface{}{"cpu_high": 120}}}), ErrInvalidParams)
+	assert.ErrorIs(t, r.SetBindings([]Binding{{Policies: []string{"autoscale_gold"}, Params: map[string]interface{}{"memory_high": 80}}}), ErrInvalidParams)
+}
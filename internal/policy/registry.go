@@ -0,0 +1,460 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Extend returns p with what it leaves out taken from base. Rules are merged
+// by ID: a rule of p with the ID of a base rule overrides the fields it sets,
+// with action params merged key by key, and other rules are added.
+func (p *Policy) Extend(base *Policy) *Policy {
+	resolved := *base
+	resolved.ID = p.ID
+	resolved.Version = p.Version
+	resolved.Extends = p.Extends
+	if p.Name != "" {
+		resolved.Name = p.Name
+	}
+	if p.Description != "" {
+		resolved.Description = p.Description
+	}
+	if p.Type != "" {
+		resolved.Type = p.Type
+	}
+	if p.Execution.Mode != "" {
+		resolved.Execution.Mode = p.Execution.Mode
+	}
+	if p.Approval.MaxRisk != 0 {
+		resolved.Approval.MaxRisk = p.Approval.MaxRisk
+	}
+	if p.Approval.MaxCost != 0 {
+		resolved.Approval.MaxCost = p.Approval.MaxCost
+	}
+	if p.Approval.Timeout != "" {
+		resolved.Approval.Timeout = p.Approval.Timeout
+	}
+	resolved.Defaults = mergeMap(base.Defaults, p.Defaults)
+	resolved.Params = mergeMap(base.Params, p.Params)
+	for name, param := range p.Params {
+		resolved.Params[name] = overrideParam(base.Params[name], param)
+	}
+
+	resolved.Rules = make([]Rule, 0, len(base.Rules)+len(p.Rules))
+	index := make(map[string]int, len(base.Rules))
+	for _, rule := range base.Rules {
+		rule.Action.Params = mergeMap(rule.Action.Params, nil)
+		index[rule.ID] = len(resolved.Rules)
+		resolved.Rules = append(resolved.Rules, rule)
+	}
+	for _, rule := range p.Rules {
+		i, ok := index[rule.ID]
+		if !ok {
+			index[rule.ID] = len(resolved.Rules)
+			resolved.Rules = append(resolved.Rules, rule)
+			continue
+		}
+		overrideRule(&resolved.Rules[i], &rule)
+	}
+	return &resolved
+}
+
+// overrideRule sets the fields of rule that override sets
+func overrideRule(rule, override *Rule) {
+	if override.Name != "" {
+		rule.Name = override.Name
+	}
+	if override.Priority != 0 {
+		rule.Priority = override.Priority
+	}
+	if !reflect.DeepEqual(override.When, Condition{}) {
+		rule.When = override.When
+	}
+	if override.Action.Type != "" {
+		rule.Action.Type = override.Action.Type
+	}
+	if override.Action.Target != "" {
+		rule.Action.Target = override.Action.Target
+	}
+	if override.Action.Cost != 0 {
+		rule.Action.Cost = override.Action.Cost
+	}
+	if override.Action.Risk != 0 {
+		rule.Action.Risk = override.Action.Risk
+	}
+	if override.Cooldown != "" {
+		rule.Cooldown = override.Cooldown
+	}
+	rule.Action.Params = mergeMap(rule.Action.Params, override.Action.Params)
+}
+
+// overrideParam returns base with the fields override sets, so an extending
+// policy can change only the default of an inherited param
+func overrideParam(base, override Param) Param {
+	if override.Type != "" {
+		base.Type = override.Type
+	}
+	if override.Default != nil {
+		base.Default = override.Default
+	}
+	if override.Min != nil {
+		base.Min = override.Min
+	}
+	if override.Max != nil {
+		base.Max = override.Max
+	}
+	if override.Enum != nil {
+		base.Enum = override.Enum
+	}
+	if override.Description != "" {
+		base.Description = override.Description
+	}
+	return base
+}
+
+// mergeMap returns a copy of base with the entries of override, or nil when
+// both are empty
+func mergeMap[V any](base, override map[string]V) map[string]V {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]V, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Selector picks services by ID, by a glob on the ID such as "payments-*", or
+// by labels that must all match. An empty selector picks every service.
+type Selector struct {
+	Service string            `yaml:"service,omitempty" json:"service,omitempty"`
+	Labels  map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// matches reports whether the selector picks a service
+func (s Selector) matches(serviceID string, labels map[string]string) bool {
+	if s.Service != "" {
+		if ok, _ := path.Match(s.Service, serviceID); !ok {
+			return false
+		}
+	}
+	for k, v := range s.Labels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// specificity ranks selectors: an exact ID beats a glob, and labels add to
+// either, so the most specific binding of a service wins
+func (s Selector) specificity() int {
+	score := 0
+	switch {
+	case s.Service == "":
+	case strings.ContainsAny(s.Service, "*?["):
+		score = 2
+	default:
+		score = 4
+	}
+	if len(s.Labels) > 0 {
+		score++
+	}
+	return score
+}
+
+// Binding applies policies to the services its selector picks. Params
+// override the params of the bound policies that declare them.
+type Binding struct {
+	Selector Selector               `yaml:"selector" json:"selector"`
+	Policies []string               `yaml:"policies" json:"policies"`
+	Params   map[string]interface{} `yaml:"params,omitempty" json:"params,omitempty"`
+}
+
+// BoundPolicy is a policy bound to a service with the param overrides of its
+// binding
+type BoundPolicy struct {
+	Policy *Policy
+	Params map[string]interface{}
+}
+
+// Validate validates the binding
+func (b *Binding) Validate() error {
+	if len(b.Policies) == 0 {
+		return fmt.Errorf("binding for %q has no policies", b.Selector.Service)
+	}
+	if _, err := path.Match(b.Selector.Service, ""); err != nil {
+		return fmt.Errorf("invalid service glob %q: %w", b.Selector.Service, err)
+	}
+	return nil
+}
+
+// LoadBindings reads bindings from a YAML file with a top-level "bindings" list
+func LoadBindings(path string) ([]Binding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bindings: %w", err)
+	}
+
+	var file struct {
+		Bindings []Binding `yaml:"bindings"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse bindings: %w", err)
+	}
+	for i := range file.Bindings {
+		if err := file.Bindings[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return file.Bindings, nil
+}
+
+// Registry holds the loaded policies and the bindings that select them for
+// services. Policies are kept as loaded and resolved against the policies they
+// extend when fetched, so reloading a base policy updates its children.
+type Registry struct {
+	mu         sync.RWMutex
+	policies   map[string]*Policy
+	candidates map[string]*Policy // by the ID of the policy they would replace
+	bindings   []Binding
+}
+
+// NewRegistry creates an empty policy registry
+func NewRegistry() *Registry {
+	return &Registry{
+		policies:   make(map[string]*Policy),
+		candidates: make(map[string]*Policy),
+	}
+}
+
+// Add adds or replaces a policy. The policy it extends must already be added,
+// and every policy must still resolve with it in place.
+func (r *Registry) Add(p *Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.policies[p.ID]
+	r.policies[p.ID] = p
+	for id := range r.policies {
+		if _, err := r.resolve(id, nil); err != nil {
+			if existed {
+				r.policies[p.ID] = previous
+			} else {
+				delete(r.policies, p.ID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// AddAll adds policies, each after the policy it extends
+func (r *Registry) AddAll(policies []*Policy) error {
+	pending := policies
+	for len(pending) > 0 {
+		var next []*Policy
+		for _, p := range pending {
+			r.mu.RLock()
+			_, baseLoaded := r.policies[p.Extends]
+			r.mu.RUnlock()
+			if p.Extends != "" && !baseLoaded {
+				next = append(next, p)
+				continue
+			}
+			if err := r.Add(p); err != nil {
+				return err
+			}
+		}
+		if len(next) == len(pending) {
+			return fmt.Errorf("policy %s extends %s: %w", next[0].ID, next[0].Extends, models.ErrPolicyNotFound)
+		}
+		pending = next
+	}
+	return nil
+}
+
+// Get returns a policy resolved against the policies it extends
+func (r *Registry) Get(id string) (*Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resolve(id, nil)
+}
+
+func (r *Registry) resolve(id string, seen map[string]bool) (*Policy, error) {
+	p, ok := r.policies[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", models.ErrPolicyNotFound, id)
+	}
+	if p.Extends == "" {
+		return p, nil
+	}
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	if seen[id] {
+		return nil, fmt.Errorf("policy %s extends itself", id)
+	}
+	seen[id] = true
+
+	base, err := r.resolve(p.Extends, seen)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", id, err)
+	}
+	resolved := p.Extend(base)
+	if err := resolved.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", id, err)
+	}
+	return resolved, nil
+}
+
+// SetCandidate sets the candidate version of a policy, evaluated in shadow
+// next to the policy with its ID until it is removed. The policy must be added,
+// and a candidate that extends a policy, its own live version included, is
+// resolved against it.
+func (r *Registry) SetCandidate(p *Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.policies[p.ID]; !ok {
+		return fmt.Errorf("candidate %s %s: %w", p.ID, p.Version, models.ErrPolicyNotFound)
+	}
+	if _, err := r.resolveCandidate(p); err != nil {
+		return fmt.Errorf("candidate %s %s: %w", p.ID, p.Version, err)
+	}
+	r.candidates[p.ID] = p
+	return nil
+}
+
+// RemoveCandidate stops shadowing a policy and reports whether it had a candidate
+func (r *Registry) RemoveCandidate(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.candidates[id]
+	delete(r.candidates, id)
+	return ok
+}
+
+// Candidate returns the candidate version of a policy, resolved against the
+// policy it extends, or nil when the policy has none
+func (r *Registry) Candidate(id string) (*Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.candidates[id]
+	if !ok {
+		return nil, nil
+	}
+	return r.resolveCandidate(p)
+}
+
+func (r *Registry) resolveCandidate(p *Policy) (*Policy, error) {
+	if p.Extends == "" {
+		return p, nil
+	}
+	base, err := r.resolve(p.Extends, nil)
+	if err != nil {
+		return nil, err
+	}
+	resolved := p.Extend(base)
+	if err := resolved.Validate(); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// SetBindings replaces the bindings. Every policy they name must be added.
+func (r *Registry) SetBindings(bindings []Binding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range bindings {
+		b := &bindings[i]
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		declared := make(map[string]bool, len(b.Params))
+		for _, id := range b.Policies {
+			p, err := r.resolve(id, nil)
+			if err != nil {
+				return fmt.Errorf("binding for %q: %w", b.Selector.Service, err)
+			}
+			for name, v := range p.DeclaredParams(b.Params) {
+				param := p.Params[name]
+				if _, err := param.Coerce(v); err != nil {
+					return fmt.Errorf("binding for %q: %w: param %s of %s: %v", b.Selector.Service, ErrInvalidParams, name, id, err)
+				}
+				declared[name] = true
+			}
+		}
+		for name := range b.Params {
+			if !declared[name] {
+				return fmt.Errorf("binding for %q: %w: no bound policy declares param %s", b.Selector.Service, ErrInvalidParams, name)
+			}
+		}
+	}
+	r.bindings = bindings
+	return nil
+}
+
+// Bound returns the policies bound to a service, one per policy type. When
+// bindings disagree on a type, the most specific one wins, and among equally
+// specific ones the first.
+func (r *Registry) Bound(serviceID string, labels map[string]string) ([]BoundPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []Binding
+	for _, b := range r.bindings {
+		if b.Selector.matches(serviceID, labels) {
+			matched = append(matched, b)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Selector.specificity() > matched[j].Selector.specificity()
+	})
+
+	var bound []BoundPolicy
+	types := make(map[string]bool)
+	for _, b := range matched {
+		for _, id := range b.Policies {
+			p, err := r.resolve(id, nil)
+			if err != nil {
+				return nil, err
+			}
+			if types[p.Type] {
+				continue
+			}
+			types[p.Type] = true
+			bound = append(bound, BoundPolicy{Policy: p, Params: p.DeclaredParams(b.Params)})
+		}
+	}
+	return bound, nil
+}
+
+// BoundOfType returns the policy of a type bound to a service, if any
+func (r *Registry) BoundOfType(serviceID string, labels map[string]string, policyType string) (*BoundPolicy, error) {
+	bound, err := r.Bound(serviceID, labels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range bound {
+		if bound[i].Policy.Type == policyType {
+			return &bound[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func basePolicy() *Policy {
+	return &Policy{
+		ID:      "autoscale_base",
+		Version: "1.0",
+		Name:    "Base",
+		Type:    "autoscale",
+		Rules: []Rule{
+			{
+				ID:       "scale_up",
+				Name:     "Scale Up",
+				Priority: 100,
+				When:     Condition{Fact: "cpu", Op: ">=", Value: 80.0},
+				Action:   Action{Type: "scale_up", Params: map[string]interface{}{"instances": 1, "max_instances": 10}},
+			},
+			{
+				ID:     "scale_down",
+				Name:   "Scale Down",
+				When:   Condition{Fact: "cpu", Op: "<", Value: 20.0},
+				Action: Action{Type: "scale_down"},
+			},
+		},
+	}
+}
+
+func TestExtend(t *testing.T) {
+	base := basePolicy()
+	child := &Policy{
+		ID:      "autoscale_checkout",
+		Version: "2.0",
+		Extends: base.ID,
+		Rules: []Rule{
+			{
+				ID:     "scale_up",
+				When:   Condition{Fact: "cpu", Op: ">=", Value: 60.0},
+				Action: Action{Params: map[string]interface{}{"max_instances": 30}},
+			},
+			{
+				ID:     "shed_load",
+				Name:   "Shed Load",
+				When:   Condition{Fact: "error_rate", Op: ">", Value: 0.1},
+				Action: Action{Type: "throttle"},
+			},
+		},
+	}
+
+	resolved := child.Extend(base)
+	require.NoError(t, resolved.Validate())
+	assert.Equal(t, "autoscale_checkout", resolved.ID)
+	assert.Equal(t, "2.0", resolved.Version)
+	assert.Equal(t, "autoscale", resolved.Type)
+	require.Len(t, resolved.Rules, 3)
+
+	scaleUp := resolved.GetRuleByID("scale_up")
+	assert.Equal(t, "Scale Up", scaleUp.Name)
+	assert.Equal(t, 100, scaleUp.Priority)
+	assert.Equal(t, 60.0, scaleUp.When.Value)
+	assert.Equal(t, map[string]interface{}{"instances": 1, "max_instances": 30}, scaleUp.Action.Params)
+	assert.Equal(t, "shed_load", resolved.Rules[2].ID)
+
+	// The base policy is left as it was
+	assert.Equal(t, 10, base.Rules[0].Action.Params["max_instances"])
+	assert.Equal(t, 80.0, base.Rules[0].When.Value)
+}
+
+func TestRegistryExtends(t *testing.T) {
+	r := NewRegistry()
+	child := &Policy{ID: "child", Version: "1.0", Extends: "autoscale_base", Name: "Child"}
+	grandchild := &Policy{ID: "grandchild", Version: "1.0", Extends: "child"}
+
+	// Policies are added after the ones they extend, whatever their order
+	require.NoError(t, r.AddAll([]*Policy{grandchild, child, basePolicy()}))
+	resolved, err := r.Get("grandchild")
+	require.NoError(t, err)
+	assert.Equal(t, "Child", resolved.Name)
+	assert.Len(t, resolved.Rules, 2)
+
+	// Reloading a base policy updates its children
+	updated := basePolicy()
+	updated.Version = "1.1"
+	updated.Rules = updated.Rules[:1]
+	require.NoError(t, r.Add(updated))
+	resolved, err = r.Get("grandchild")
+	require.NoError(t, err)
+	assert.Len(t, resolved.Rules, 1)
+
+	_, err = r.Get("missing")
+	assert.ErrorIs(t, err, models.ErrPolicyNotFound)
+	assert.Error(t, r.Add(&Policy{ID: "orphan", Version: "1.0", Extends: "missing"}))
+	assert.Error(t, r.AddAll([]*Policy{{ID: "orphan", Version: "1.0", Extends: "missing"}}))
+
+	// A rule that only overrides must have a rule to override
+	assert.Error(t, r.Add(&Policy{ID: "partial", Version: "1.0", Extends: "autoscale_base", Rules: []Rule{{ID: "new_rule"}}}))
+	_, err = r.Get("partial")
+	assert.ErrorIs(t, err, models.ErrPolicyNotFound)
+}
+
+func TestRegistryBound(t *testing.T) {
+	r := NewRegistry()
+	ratelimit := &Policy{ID: "ratelimit_default", Version: "1.0", Type: "ratelimit",
+		Rules: []Rule{{ID: "limit", Name: "Limit", Action: Action{Type: "throttle"}}}}
+	checkout := &Policy{ID: "autoscale_checkout", Version: "1.0", Extends: "autoscale_base"}
+	gold := &Policy{ID: "autoscale_gold", Version: "1.0", Extends: "autoscale_base"}
+	require.NoError(t, r.AddAll([]*Policy{basePolicy(), ratelimit, checkout, gold}))
+
+	require.NoError(t, r.SetBindings([]Binding{
+		{Policies: []string{"autoscale_base", "ratelimit_default"}},
+		{Selector: Selector{Labels: map[string]string{"tier": "gold"}}, Policies: []string{"autoscale_gold"}},
+		{Selector: Selector{Service: "checkout"}, Policies: []string{"autoscale_checkout"}},
+	}))
+
+	ids := func(serviceID string, labels map[string]string) []string {
+		bound, err := r.Bound(serviceID, labels)
+		require.NoError(t, err)
+		var ids []string
+		for _, p := range bound {
+			ids = append(ids, p.Policy.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"autoscale_base", "ratelimit_default"}, ids("search", nil))
+	assert.Equal(t, []string{"autoscale_gold", "ratelimit_default"}, ids("search", map[string]string{"tier": "gold"}))
+	assert.Equal(t, []string{"autoscale_checkout", "ratelimit_default"}, ids("checkout", map[string]string{"tier": "gold"}))
+
+	pol, err := r.BoundOfType("checkout", nil, "ratelimit")
+	require.NoError(t, err)
+	assert.Equal(t, "ratelimit_default", pol.Policy.ID)
+
+	assert.Error(t, r.SetBindings([]Binding{{Selector: Selector{Service: "payments-*"}, Policies: []string{"missing"}}}))
+}
+
+func TestSelector(t *testing.T) {
+	glob := Selector{Service: "payments-*"}
+	assert.True(t, glob.matches("payments-eu", nil))
+	assert.False(t, glob.matches("checkout", nil))
+	assert.Greater(t, Selector{Service: "payments-eu"}.specificity(), glob.specificity())
+	assert.Greater(t, glob.specificity(), Selector{Labels: map[string]string{"tier": "gold"}}.specificity())
+}
+
+func TestLoadPolicies(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte(`
+id: autoscale_base
+version: "1.0"
+name: Base
+type: autoscale
+rules:
+  - id: scale_up
+    name: Scale Up
+    when:
+      fact: cpu
+      op: ">="
+      value: 80
+    action:
+      type: scale_up
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checkout.yaml"), []byte(`
+id: autoscale_checkout
+version: "1.0"
+extends: autoscale_base
+rules:
+  - id: scale_up
+    when:
+      fact: cpu
+      op: ">="
+      value: 60
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bindings.txt"), []byte("not a policy"), 0o644))
+
+	policies, err := LoadPolicies(dir)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+
+	r := NewRegistry()
+	require.NoError(t, r.AddAll(policies))
+	resolved, err := r.Get("autoscale_checkout")
+	require.NoError(t, err)
+	assert.Equal(t, 60, resolved.Rules[0].When.Value)
+	assert.Equal(t, "scale_up", resolved.Rules[0].Action.Type)
+
+	path := filepath.Join(dir, "bindings.yaml.txt")
+	require.NoError(t, os.WriteFile(path, []byte(`
+bindings:
+  - selector: {service: "checkout"}
+    policies: [autoscale_checkout]
+  - selector: {labels: {tier: gold}}
+    policies: []
+`), 0o644))
+	_, err = LoadBindings(path)
+	assert.Error(t, err)
+}
+
+func TestRegistryCandidate(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Add(basePolicy()))
+
+	candidate, err := r.Candidate("autoscale_base")
+	require.NoError(t, err)
+	assert.Nil(t, candidate)
+
+	// A candidate extending its own live version changes only what it sets
+	require.NoError(t, r.SetCandidate(&Policy{ID: "autoscale_base", Version: "2.0", Extends: "autoscale_base",
+		Rules: []Rule{{ID: "scale_up", When: Condition{Fact: "cpu", Op: ">=", Value: 70.0}}}}))
+	candidate, err = r.Candidate("autoscale_base")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", candidate.Version)
+	assert.Equal(t, 70.0, candidate.GetRuleByID("scale_up").When.Value)
+	assert.Len(t, candidate.Rules, 2)
+
+	// The live policy is unchanged
+	live, err := r.Get("autoscale_base")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", live.Version)
+
+	assert.ErrorIs(t, r.SetCandidate(&Policy{ID: "missing", Version: "2.0"}), models.ErrPolicyNotFound)
+	assert.True(t, r.RemoveCandidate("autoscale_base"))
+	assert.False(t, r.RemoveCandidate("autoscale_base"))
+}
@@ -1,5 +1,7 @@
 package policy
 
+import "time"
+
 // Policy represents a decision policy
 type Policy struct {
 	ID          string            `yaml:"id" json:"id"`
@@ -7,8 +9,54 @@ type Policy struct {
 	Name        string            `yaml:"name" json:"name"`
 	Description string            `yaml:"description" json:"description"`
 	Type        string            `yaml:"type" json:"type"`
+	Extends     string            `yaml:"extends,omitempty" json:"extends,omitempty"` // ID of the base policy
+	Params      map[string]Param  `yaml:"params,omitempty" json:"params,omitempty"`
 	Rules       []Rule            `yaml:"rules" json:"rules"`
 	Defaults    map[string]string `yaml:"defaults" json:"defaults"`
+	Execution   Execution         `yaml:"execution,omitempty" json:"execution,omitempty"`
+	Approval    Approval          `yaml:"approval,omitempty" json:"approval,omitempty"`
+}
+
+// Execution modes control what happens to the actions of a non-dry-run decision
+const (
+	ExecutionModeManual   = "manual"   // actions are only returned to the caller
+	ExecutionModeAuto     = "auto"     // actions are executed immediately
+	ExecutionModeApproval = "approval" // actions are recorded and wait for approval
+)
+
+// Execution holds policy-level action execution settings
+type Execution struct {
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+}
+
+// Approval holds the thresholds above which actions wait for a human approver.
+// A zero threshold is not enforced.
+type Approval struct {
+	MaxRisk float64 `yaml:"max_risk,omitempty" json:"max_risk,omitempty"`
+	MaxCost float64 `yaml:"max_cost,omitempty" json:"max_cost,omitempty"`
+	Timeout string  `yaml:"timeout,omitempty" json:"timeout,omitempty"` // falls back to the server default when empty
+}
+
+// Requires reports whether an action with the given risk and cost needs approval
+func (a Approval) Requires(risk, cost float64) bool {
+	return (a.MaxRisk > 0 && risk > a.MaxRisk) || (a.MaxCost > 0 && cost > a.MaxCost)
+}
+
+// TimeoutDuration returns the approval timeout, or zero when unset or invalid
+func (a Approval) TimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(a.Timeout)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// ExecutionMode returns the policy's execution mode, defaulting to manual
+func (p *Policy) ExecutionMode() string {
+	if p.Execution.Mode == "" {
+		return ExecutionModeManual
+	}
+	return p.Execution.Mode
 }
 
 // Rule represents a single rule in a policy
@@ -29,6 +77,10 @@ type Condition struct {
 	Fact string      `yaml:"fact,omitempty" json:"fact,omitempty"`
 	Op   string      `yaml:"op,omitempty" json:"op,omitempty"`
 	Value interface{} `yaml:"value,omitempty" json:"value,omitempty"`
+
+	// Time conditions hold when the decision time is in, or outside, a window
+	During  *Window `yaml:"during,omitempty" json:"during,omitempty"`
+	Outside *Window `yaml:"outside,omitempty" json:"outside,omitempty"`
 }
 
 // Action represents the action to take when rule matches
@@ -52,6 +104,22 @@ func (p *Policy) Validate() error {
 		return &PolicyValidationError{Field: "rules", Message: "policy must have at least one rule"}
 	}
 
+	switch p.ExecutionMode() {
+	case ExecutionModeManual, ExecutionModeAuto, ExecutionModeApproval:
+	default:
+		return &PolicyValidationError{Field: "execution.mode", Message: "unknown execution mode: " + p.Execution.Mode}
+	}
+
+	if p.Approval.Timeout != "" {
+		if d, err := time.ParseDuration(p.Approval.Timeout); err != nil || d <= 0 {
+			return &PolicyValidationError{Field: "approval.timeout", Message: "invalid approval timeout: " + p.Approval.Timeout}
+		}
+	}
+
+	if err := p.validateParams(); err != nil {
+		return err
+	}
+
 	ruleIDs := make(map[string]bool)
 	for _, rule := range p.Rules {
 		if rule.ID == "" {
@@ -70,6 +138,30 @@ func (p *Policy) Validate() error {
 	return nil
 }
 
+// validateExtension validates a policy that extends another. Its rules may
+// only override parts of inherited ones, so it is validated in full once
+// resolved against its base.
+func (p *Policy) validateExtension() error {
+	if p.ID == "" {
+		return &PolicyValidationError{Field: "id", Message: "policy ID is required"}
+	}
+	if p.Version == "" {
+		return &PolicyValidationError{Field: "version", Message: "policy version is required"}
+	}
+	if p.Extends == p.ID {
+		return &PolicyValidationError{Field: "extends", Message: "policy cannot extend itself"}
+	}
+	for _, rule := range p.Rules {
+		if rule.ID == "" {
+			return &PolicyValidationError{Field: "rule.id", Message: "rule ID is required"}
+		}
+		if err := validateCondition(&rule.When); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateRule(r *Rule) error {
 	if r.Name == "" {
 		return &PolicyValidationError{Field: "rule.name", Message: "rule name is required"}
@@ -77,6 +169,31 @@ func validateRule(r *Rule) error {
 	if r.Action.Type == "" {
 		return &PolicyValidationError{Field: "rule.action.type", Message: "rule action type is required"}
 	}
+	return validateCondition(&r.When)
+}
+
+func validateCondition(c *Condition) error {
+	for _, w := range []*Window{c.During, c.Outside} {
+		if w == nil {
+			continue
+		}
+		if err := w.Validate(); err != nil {
+			return &PolicyValidationError{Field: "rule.when", Message: err.Error()}
+		}
+	}
+	for i := range c.All {
+		if err := validateCondition(&c.All[i]); err != nil {
+			return err
+		}
+	}
+	for i := range c.Any {
+		if err := validateCondition(&c.Any[i]); err != nil {
+			return err
+		}
+	}
+	if c.Not != nil {
+		return validateCondition(c.Not)
+	}
 	return nil
 }
 
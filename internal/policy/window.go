@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/cron"
+	"gopkg.in/yaml.v3"
+)
+
+// Window is a recurring stretch of time, given by a cron expression or by the
+// name of a calendar. Without a duration a cron window covers every hour the
+// expression fires in; with one it covers the duration after each activation.
+type Window struct {
+	Cron     string `yaml:"cron,omitempty" json:"cron,omitempty"`
+	TZ       string `yaml:"tz,omitempty" json:"tz,omitempty"` // UTC when empty
+	Duration string `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Calendar string `yaml:"calendar,omitempty" json:"calendar,omitempty"`
+}
+
+// Validate validates the window
+func (w *Window) Validate() error {
+	if w.Calendar != "" {
+		if w.Cron != "" || w.TZ != "" || w.Duration != "" {
+			return fmt.Errorf("calendar window %s cannot set cron, tz or duration", w.Calendar)
+		}
+		return nil
+	}
+	if w.Cron == "" {
+		return fmt.Errorf("window needs a cron expression or a calendar")
+	}
+	if _, err := cron.Parse(w.Cron); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(w.TZ); err != nil {
+		return fmt.Errorf("invalid window time zone: %w", err)
+	}
+	if w.Duration != "" {
+		if d, err := time.ParseDuration(w.Duration); err != nil || d <= 0 {
+			return fmt.Errorf("invalid window duration: %s", w.Duration)
+		}
+	}
+	return nil
+}
+
+// contains reports whether at falls in a cron window
+func (w *Window) contains(at time.Time) (bool, error) {
+	schedule, err := cron.Parse(w.Cron)
+	if err != nil {
+		return false, err
+	}
+	location, err := time.LoadLocation(w.TZ)
+	if err != nil {
+		return false, err
+	}
+	at = at.In(location)
+
+	if w.Duration == "" {
+		return schedule.MatchesHour(at), nil
+	}
+	d, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return false, err
+	}
+	// The window is open when the schedule fired within the last d
+	next := schedule.Next(at.Add(-d))
+	return !next.IsZero() && !next.After(at), nil
+}
+
+// Calendar is a named set of windows, such as maintenance or freeze periods.
+// While one of the windows of a freeze calendar is open, no action runs.
+type Calendar struct {
+	Name    string   `yaml:"name" json:"name"`
+	Freeze  bool     `yaml:"freeze,omitempty" json:"freeze,omitempty"`
+	Windows []Window `yaml:"windows" json:"windows"`
+}
+
+// Calendars holds the named calendars policies refer to
+type Calendars struct {
+	byName map[string]*Calendar
+	names  []string
+}
+
+// NewCalendars validates calendars and indexes them by name
+func NewCalendars(calendars []Calendar) (*Calendars, error) {
+	c := &Calendars{byName: make(map[string]*Calendar, len(calendars))}
+	for i := range calendars {
+		cal := &calendars[i]
+		if cal.Name == "" {
+			return nil, fmt.Errorf("calendar %d: name is required", i)
+		}
+		if _, ok := c.byName[cal.Name]; ok {
+			return nil, fmt.Errorf("duplicate calendar %s", cal.Name)
+		}
+		if len(cal.Windows) == 0 {
+			return nil, fmt.Errorf("calendar %s has no windows", cal.Name)
+		}
+		for _, w := range cal.Windows {
+			if w.Calendar != "" {
+				return nil, fmt.Errorf("calendar %s: windows cannot refer to other calendars", cal.Name)
+			}
+			if err := w.Validate(); err != nil {
+				return nil, fmt.Errorf("calendar %s: %w", cal.Name, err)
+			}
+		}
+		c.byName[cal.Name] = cal
+		c.names = append(c.names, cal.Name)
+	}
+	sort.Strings(c.names)
+	return c, nil
+}
+
+// LoadCalendars reads calendars from a YAML file with a top-level "calendars" list
+func LoadCalendars(path string) (*Calendars, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendars: %w", err)
+	}
+
+	var file struct {
+		Calendars []Calendar `yaml:"calendars"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse calendars: %w", err)
+	}
+	return NewCalendars(file.Calendars)
+}
+
+// Contains reports whether at falls in one of the windows of the named calendar
+func (c *Calendars) Contains(name string, at time.Time) (bool, error) {
+	var cal *Calendar
+	if c != nil {
+		cal = c.byName[name]
+	}
+	if cal == nil {
+		return false, fmt.Errorf("unknown calendar %s", name)
+	}
+	for i := range cal.Windows {
+		open, err := cal.Windows[i].contains(at)
+		if err != nil {
+			return false, err
+		}
+		if open {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Frozen returns the name of the freeze calendar at falls in, if any
+func (c *Calendars) Frozen(at time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	for _, name := range c.names {
+		if !c.byName[name].Freeze {
+			continue
+		}
+		if open, _ := c.Contains(name, at); open {
+			return name, true
+		}
+	}
+	return "", false
+}
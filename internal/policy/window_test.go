@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowContains(t *testing.T) {
+	tests := []struct {
+		name   string
+		window Window
+		at     time.Time
+		want   bool
+	}{
+		{"business hours", Window{Cron: "0 9-17 * * MON-FRI"}, time.Date(2026, 3, 11, 17, 30, 0, 0, time.UTC), true},
+		{"after business hours", Window{Cron: "0 9-17 * * MON-FRI"}, time.Date(2026, 3, 11, 18, 0, 0, 0, time.UTC), false},
+		{"weekend", Window{Cron: "0 9-17 * * MON-FRI"}, time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC), false},
+		// 08:30 UTC is 09:30 in Madrid in winter
+		{"time zone", Window{Cron: "0 9-17 * * MON-FRI", TZ: "Europe/Madrid"}, time.Date(2026, 3, 11, 8, 30, 0, 0, time.UTC), true},
+		{"within duration", Window{Cron: "0 22 * * SAT", Duration: "6h"}, time.Date(2026, 3, 15, 3, 59, 0, 0, time.UTC), true},
+		{"after duration", Window{Cron: "0 22 * * SAT", Duration: "6h"}, time.Date(2026, 3, 15, 4, 1, 0, 0, time.UTC), false},
+		{"before activation", Window{Cron: "0 22 * * SAT", Duration: "6h"}, time.Date(2026, 3, 14, 21, 59, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.window.Validate())
+			got, err := tt.window.contains(tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWindowValidate(t *testing.T) {
+	assert.Error(t, (&Window{}).Validate())
+	assert.Error(t, (&Window{Cron: "0 9-17 * *"}).Validate())
+	assert.Error(t, (&Window{Cron: "@daily", TZ: "Mars/Olympus"}).Validate())
+	assert.Error(t, (&Window{Cron: "@daily", Duration: "-1h"}).Validate())
+	assert.Error(t, (&Window{Calendar: "maintenance", TZ: "UTC"}).Validate())
+	assert.NoError(t, (&Window{Calendar: "maintenance"}).Validate())
+}
+
+func scaleDownPolicy() *Policy {
+	return &Policy{
+		ID:      "scale-down",
+		Version: "1.0",
+		Rules: []Rule{{
+			ID:   "low_cpu",
+			Name: "Low CPU",
+			When: Condition{All: []Condition{
+				{Fact: "cpu", Op: "<", Value: 20.0},
+				{Outside: &Window{Cron: "0 9-17 * * MON-FRI"}},
+				{Outside: &Window{Calendar: "maintenance"}},
+			}},
+			Action: Action{Type: "scale_down"},
+		}},
+	}
+}
+
+func TestEvaluateTimeConditions(t *testing.T) {
+	engine := NewEngine(nil)
+	calendars, err := NewCalendars([]Calendar{{
+		Name:    "maintenance",
+		Windows: []Window{{Cron: "0 2 * * SUN", Duration: "2h"}},
+	}})
+	require.NoError(t, err)
+	engine.SetCalendars(calendars)
+
+	pol := scaleDownPolicy()
+	require.NoError(t, pol.Validate())
+	features := &models.ServiceFeatures{ServiceID: "checkout", CPUCurrent: 10}
+	evaluateAt := func(at time.Time) bool {
+		engine.SetClock(func() time.Time { return at })
+		result, _ := engine.Evaluate(context.Background(), pol, features)
+		return result.Matched
+	}
+
+	assert.False(t, evaluateAt(time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)), "business hours")
+	assert.True(t, evaluateAt(time.Date(2026, 3, 11, 20, 0, 0, 0, time.UTC)), "evening")
+	assert.False(t, evaluateAt(time.Date(2026, 3, 15, 3, 0, 0, 0, time.UTC)), "maintenance")
+
+	// Replays are evaluated at the recorded time, whatever the clock says
+	result, _ := engine.EvaluateRecorded(context.Background(), pol, features, time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC), nil)
+	assert.False(t, result.Matched)
+	assert.Equal(t, time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC), result.EvaluatedAt)
+
+	// A window that cannot be checked matches neither way
+	engine.SetCalendars(nil)
+	assert.False(t, evaluateAt(time.Date(2026, 3, 11, 20, 0, 0, 0, time.UTC)))
+}
+
+func TestLoadCalendars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendars.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+calendars:
+  - name: maintenance
+    windows:
+      - cron: "0 2 * * SUN"
+        duration: 2h
+  - name: release-freeze
+    freeze: true
+    windows:
+      - cron: "* * 20-31 12 *"
+        tz: Europe/Madrid
+`), 0o644))
+
+	calendars, err := LoadCalendars(path)
+	require.NoError(t, err)
+
+	in, err := calendars.Contains("maintenance", time.Date(2026, 3, 15, 2, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, in)
+	_, err = calendars.Contains("holidays", time.Now())
+	assert.Error(t, err)
+
+	name, frozen := calendars.Frozen(time.Date(2026, 12, 24, 12, 0, 0, 0, time.UTC))
+	assert.True(t, frozen)
+	assert.Equal(t, "release-freeze", name)
+	_, frozen = calendars.Frozen(time.Date(2026, 3, 15, 2, 30, 0, 0, time.UTC))
+	assert.False(t, frozen, "maintenance does not freeze")
+
+	_, err = NewCalendars([]Calendar{{Name: "nested", Windows: []Window{{Calendar: "maintenance"}}}})
+	assert.Error(t, err)
+}
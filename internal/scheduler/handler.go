@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the scheduler admin endpoints
+type Handler struct {
+	scheduler *Scheduler
+}
+
+// NewHandler creates a new scheduler handler
+func NewHandler(scheduler *Scheduler) *Handler {
+	return &Handler{scheduler: scheduler}
+}
+
+// RegisterRoutes registers the scheduler routes
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/jobs", h.handleListJobs)
+}
+
+func (h *Handler) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	jobs := h.scheduler.Jobs()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// Recurrence computes the nominal run times of a recurring job.
+// *cron.Schedule satisfies it.
+type Recurrence interface {
+	// Next returns the first run strictly after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Every returns a recurrence firing at a fixed interval. Runs fall on
+// multiples of the interval, so every replica schedules the same run times.
+func Every(interval time.Duration) Recurrence {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(e)).Add(time.Duration(e))
+}
+
+func (e every) String() string {
+	return fmt.Sprintf("every %s", time.Duration(e))
+}
+
+// MisfirePolicy decides what happens to runs of a recurring job that were
+// missed, either because the scheduler was late or because the job was still
+// running at its concurrency limit
+type MisfirePolicy string
+
+const (
+	// MisfireSkip drops missed runs and waits for the next one on schedule
+	MisfireSkip MisfirePolicy = "skip"
+	// MisfireRunOnce runs once for all missed runs, then resumes the schedule
+	MisfireRunOnce MisfirePolicy = "run_once"
+	// MisfireCatchUp runs once for every missed run, one after the other
+	MisfireCatchUp MisfirePolicy = "catch_up"
+)
+
+// MisfireThreshold is how late a run may start before it counts as missed
+const MisfireThreshold = 5 * time.Second
+
+// ParseMisfirePolicy parses a misfire policy name, defaulting to run_once
+func ParseMisfirePolicy(s string) (MisfirePolicy, error) {
+	switch MisfirePolicy(s) {
+	case "":
+		return MisfireRunOnce, nil
+	case MisfireSkip, MisfireRunOnce, MisfireCatchUp:
+		return MisfirePolicy(s), nil
+	}
+	return "", fmt.Errorf("unknown misfire policy: %s", s)
+}
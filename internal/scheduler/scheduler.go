@@ -4,17 +4,58 @@ import (
 	"container/heap"
 	"context"
 	"log/slog"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
 )
 
-// Job represents a scheduled job
+// Job represents a scheduled job. A job with a Recurrence is rescheduled after
+// every run; otherwise it runs once at ExecuteAt.
 type Job struct {
 	ID        string
 	ExecuteAt time.Time
 	Payload   interface{}
 	Handler   func(context.Context, interface{}) error
 	Index     int // For heap
+
+	Recurrence    Recurrence
+	Jitter        time.Duration // random delay of up to Jitter added to each run
+	Misfire       MisfirePolicy // defaults to MisfireRunOnce
+	MaxConcurrent int           // runs of this job at once, defaults to 1
+
+	// Guarded by Scheduler.mu
+	nominal      time.Time // ExecuteAt before jitter
+	inFlight     int
+	deferred     bool // a run is waiting for an in-flight one to finish
+	runs         int64
+	skipped      int64
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastError    string
+}
+
+func (j *Job) maxConcurrent() int {
+	if j.MaxConcurrent <= 0 {
+		return 1
+	}
+	return j.MaxConcurrent
+}
+
+func (j *Job) misfire() MisfirePolicy {
+	if j.Misfire == "" {
+		return MisfireRunOnce
+	}
+	return j.Misfire
+}
+
+// setNext sets the nominal run time and the jittered ExecuteAt
+func (j *Job) setNext(nominal time.Time) {
+	j.nominal = nominal
+	j.ExecuteAt = nominal
+	if j.Jitter > 0 {
+		j.ExecuteAt = nominal.Add(time.Duration(rand.Int63n(int64(j.Jitter))))
+	}
 }
 
 // JobQueue implements a priority queue for jobs
@@ -49,13 +90,22 @@ func (jq *JobQueue) Pop() interface{} {
 	return job
 }
 
+// DefaultMaxConcurrent bounds how many jobs run at the same time
+const DefaultMaxConcurrent = 10
+
+// idleWait is how long the scheduler sleeps when no job is queued
+const idleWait = time.Hour
+
 // Scheduler manages scheduled jobs
 type Scheduler struct {
 	mu       sync.Mutex
 	jobs     JobQueue
+	byID     map[string]*Job
 	logger   *slog.Logger
 	running  bool
 	stopChan chan struct{}
+	wake     chan struct{} // signals that the earliest job may have changed
+	slots    chan struct{} // one token per running job
 	wg       sync.WaitGroup
 }
 
@@ -66,8 +116,11 @@ func NewScheduler(logger *slog.Logger) *Scheduler {
 	}
 	return &Scheduler{
 		jobs:     make(JobQueue, 0),
+		byID:     make(map[string]*Job),
 		logger:   logger,
 		stopChan: make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+		slots:    make(chan struct{}, DefaultMaxConcurrent),
 	}
 }
 
@@ -101,12 +154,25 @@ func (s *Scheduler) Stop() {
 	s.logger.Info("scheduler stopped")
 }
 
-// Schedule adds a job to the scheduler
+// Schedule adds a job to the scheduler, replacing any job with the same ID.
+// A recurring job with no ExecuteAt first runs at its next recurrence.
 func (s *Scheduler) Schedule(job *Job) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	
-	heap.Push(&s.jobs, job)
+
+	s.remove(job.ID)
+	if job.ExecuteAt.IsZero() && job.Recurrence != nil {
+		job.setNext(job.Recurrence.Next(time.Now()))
+	} else {
+		job.nominal = job.ExecuteAt
+	}
+	if job.ExecuteAt.IsZero() {
+		s.logger.Warn("job has no run time", "id", job.ID)
+		return
+	}
+
+	s.byID[job.ID] = job
+	s.push(job)
 	s.logger.Info("job scheduled", "id", job.ID, "execute_at", job.ExecuteAt)
 }
 
@@ -115,75 +181,267 @@ func (s *Scheduler) Cancel(jobID string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, job := range s.jobs {
-		if job.ID == jobID {
-			heap.Remove(&s.jobs, i)
-			s.logger.Info("job cancelled", "id", jobID)
-			return true
-		}
+	if !s.remove(jobID) {
+		return false
+	}
+	s.logger.Info("job cancelled", "id", jobID)
+	return true
+}
+
+// remove drops a job from the queue and the registry. Runs already in flight
+// finish, but the job is not rescheduled.
+func (s *Scheduler) remove(jobID string) bool {
+	job, ok := s.byID[jobID]
+	if !ok {
+		return false
+	}
+	delete(s.byID, jobID)
+	if job.Index >= 0 && job.Index < len(s.jobs) && s.jobs[job.Index] == job {
+		heap.Remove(&s.jobs, job.Index)
+	}
+	return true
+}
+
+// push queues a job and wakes the run loop in case it is now the earliest
+func (s *Scheduler) push(job *Job) {
+	heap.Push(&s.jobs, job)
+	select {
+	case s.wake <- struct{}{}:
+	default:
 	}
-	return false
 }
 
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	timer := time.NewTimer(time.Second)
-	defer timer.Stop()
-
 	for {
+		timer := time.NewTimer(s.nextWait())
+
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
 		case <-s.stopChan:
+			timer.Stop()
 			return
+		case <-s.wake:
+			timer.Stop()
 		case <-timer.C:
-			s.processDueJobs(ctx)
-			timer.Reset(time.Second)
+			if !s.processDueJobs(ctx) {
+				return
+			}
 		}
 	}
 }
 
-func (s *Scheduler) processDueJobs(ctx context.Context) {
+// nextWait returns how long to sleep until the earliest job is due
+func (s *Scheduler) nextWait() time.Duration {
 	s.mu.Lock()
-	now := time.Now()
+	defer s.mu.Unlock()
 
-	for s.jobs.Len() > 0 {
-		job := s.jobs[0]
-		if job.ExecuteAt.After(now) {
-			break
-		}
+	if s.jobs.Len() == 0 {
+		return idleWait
+	}
+	wait := time.Until(s.jobs[0].ExecuteAt)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
 
-		heap.Pop(&s.jobs)
+// processDueJobs starts every due job, waiting for a free slot when
+// DefaultMaxConcurrent jobs are already running. It returns false if the
+// scheduler was stopped while waiting.
+func (s *Scheduler) processDueJobs(ctx context.Context) bool {
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		if s.jobs.Len() == 0 || s.jobs[0].ExecuteAt.After(now) {
+			s.mu.Unlock()
+			return true
+		}
+		job := heap.Pop(&s.jobs).(*Job)
+		scheduledFor := job.nominal
+		start := s.admit(job, now)
 		s.mu.Unlock()
 
-		// Execute job in goroutine
-		go s.executeJob(ctx, job)
+		if !start {
+			continue
+		}
 
-		s.mu.Lock()
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		case <-s.stopChan:
+			return false
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { <-s.slots }()
+			s.executeJob(ctx, job, scheduledFor)
+		}()
 	}
-	s.mu.Unlock()
 }
 
-func (s *Scheduler) executeJob(ctx context.Context, job *Job) {
+// admit decides whether a due job runs now, applying its misfire policy,
+// and queues the following run of a recurring job. Called with s.mu held.
+func (s *Scheduler) admit(job *Job, now time.Time) bool {
+	if job.Recurrence == nil {
+		job.inFlight++
+		return true
+	}
+
+	late := now.Sub(job.ExecuteAt) > MisfireThreshold
+	busy := job.inFlight >= job.maxConcurrent()
+	policy := job.misfire()
+
+	if busy && policy != MisfireSkip {
+		// Picked up again once an in-flight run finishes
+		job.deferred = true
+		return false
+	}
+	if busy || (late && policy == MisfireSkip) {
+		job.skipped++
+		s.logger.Warn("job run skipped", "id", job.ID, "scheduled_for", job.nominal, "busy", busy)
+		s.reschedule(job, now)
+		return false
+	}
+
+	job.inFlight++
+	s.reschedule(job, now)
+	return true
+}
+
+// reschedule queues the run after the one just admitted or skipped. Catch-up
+// jobs step through every missed run; others resume from now.
+func (s *Scheduler) reschedule(job *Job, now time.Time) {
+	from := job.nominal
+	if job.misfire() != MisfireCatchUp && now.After(from) {
+		from = now
+	}
+
+	next := job.Recurrence.Next(from)
+	if next.IsZero() {
+		s.logger.Info("recurring job has no further runs", "id", job.ID)
+		return
+	}
+	job.setNext(next)
+	s.push(job)
+}
+
+func (s *Scheduler) executeJob(ctx context.Context, job *Job, scheduledFor time.Time) {
 	s.logger.Info("executing scheduled job", "id", job.ID)
 	
+	ctx = context.WithValue(ctx, scheduledForKey{}, scheduledFor)
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	if err := job.Handler(ctx, job.Payload); err != nil {
+	start := time.Now()
+	err := job.Handler(ctx, job.Payload)
+	if err != nil {
 		s.logger.Error("job execution failed", "id", job.ID, "error", err)
 	} else {
 		s.logger.Info("job completed", "id", job.ID)
 	}
+
+	s.finish(job, start, err)
+}
+
+type scheduledForKey struct{}
+
+// ScheduledFor returns the time the run of the job handling ctx was scheduled
+// for, before jitter, so that every replica running the same occurrence
+// agrees on it. It returns the zero time outside a job.
+func ScheduledFor(ctx context.Context) time.Time {
+	t, _ := ctx.Value(scheduledForKey{}).(time.Time)
+	return t
+}
+
+// finish records the outcome of a run and releases a deferred run
+func (s *Scheduler) finish(job *Job, start time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.inFlight--
+	job.runs++
+	job.lastRun = start
+	job.lastDuration = time.Since(start)
+	job.lastError = ""
+	if err != nil {
+		job.lastError = err.Error()
+	}
+
+	if job.Recurrence == nil {
+		if s.byID[job.ID] == job {
+			delete(s.byID, job.ID)
+		}
+		return
+	}
+	if job.deferred && s.byID[job.ID] == job {
+		job.deferred = false
+		if job.Index < 0 {
+			s.push(job)
+		}
+	}
+}
+
+// JobStatus describes a job for the admin endpoint
+type JobStatus struct {
+	ID           string     `json:"id"`
+	Schedule     string     `json:"schedule"`
+	Misfire      string     `json:"misfire,omitempty"`
+	NextRun      *time.Time `json:"next_run,omitempty"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	Runs         int64      `json:"runs"`
+	Skipped      int64      `json:"skipped"`
+	Running      int        `json:"running"`
+}
+
+// Jobs returns the status of every queued, running or recurring job, sorted by ID
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.byID))
+	for _, job := range s.byID {
+		status := JobStatus{
+			ID:        job.ID,
+			Schedule:  "once",
+			LastError: job.lastError,
+			Runs:      job.runs,
+			Skipped:   job.skipped,
+			Running:   job.inFlight,
+		}
+		if job.Recurrence != nil {
+			status.Schedule = job.Recurrence.String()
+			status.Misfire = string(job.misfire())
+		}
+		if job.Index >= 0 && job.Index < len(s.jobs) && s.jobs[job.Index] == job {
+			next := job.ExecuteAt
+			status.NextRun = &next
+		}
+		if !job.lastRun.IsZero() {
+			last := job.lastRun
+			status.LastRun = &last
+			status.LastDuration = job.lastDuration.String()
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
 }
 
-// GetPendingJobs returns pending jobs (for debugging)
-func (s *Scheduler) GetPendingJobs() []*Job {
+// Job returns a queued, running or recurring job by ID
+func (s *Scheduler) Job(id string) (*Job, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	jobs := make([]*Job, len(s.jobs))
-	copy(jobs, s.jobs)
-	return jobs
+	job, ok := s.byID[id]
+	return job, ok
 }
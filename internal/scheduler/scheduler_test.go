@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRunsDueJobPromptly(t *testing.T) {
+	s := NewScheduler(nil)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	done := make(chan time.Time, 1)
+	executeAt := time.Now().Add(50 * time.Millisecond)
+	s.Schedule(&Job{
+		ID:        "job-1",
+		ExecuteAt: executeAt,
+		Handler: func(ctx context.Context, payload interface{}) error {
+			done <- time.Now()
+			return nil
+		},
+	})
+
+	select {
+	case ranAt := <-done:
+		assert.False(t, ranAt.Before(executeAt))
+		assert.Less(t, ranAt.Sub(executeAt), 500*time.Millisecond)
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not run")
+	}
+}
+
+func TestScheduledFor(t *testing.T) {
+	s := NewScheduler(nil)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	scheduled := make(chan time.Time, 1)
+	executeAt := time.Now().Add(20 * time.Millisecond)
+	s.Schedule(&Job{
+		ID:        "job-1",
+		ExecuteAt: executeAt,
+		Handler: func(ctx context.Context, payload interface{}) error {
+			scheduled <- ScheduledFor(ctx)
+			return nil
+		},
+	})
+
+	select {
+	case at := <-scheduled:
+		assert.True(t, at.Equal(executeAt))
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not run")
+	}
+	assert.True(t, ScheduledFor(context.Background()).IsZero())
+}
+
+func TestSchedulerBoundsConcurrency(t *testing.T) {
+	s := NewScheduler(nil)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	release := make(chan struct{})
+	var running, peak int32
+	var wg sync.WaitGroup
+
+	total := DefaultMaxConcurrent + 5
+	wg.Add(total)
+	for i := 0; i < total; i++ {
+		s.Schedule(&Job{
+			ID:        fmt.Sprintf("job-%d", i),
+			ExecuteAt: time.Now(),
+			Handler: func(ctx context.Context, payload interface{}) error {
+				defer wg.Done()
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt32(&running, -1)
+				return nil
+			},
+		})
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(DefaultMaxConcurrent), atomic.LoadInt32(&running))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(DefaultMaxConcurrent), atomic.LoadInt32(&peak))
+}
+
+func TestSchedulerRunsRecurringJob(t *testing.T) {
+	s := NewScheduler(nil)
+	s.Start(context.Background())
+	defer s.Stop()
+
+	var runs int32
+	s.Schedule(&Job{
+		ID:         "tick",
+		Recurrence: Every(20 * time.Millisecond),
+		Handler: func(ctx context.Context, payload interface{}) error {
+			atomic.AddInt32(&runs, 1)
+			return fmt.Errorf("boom")
+		},
+	})
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, 2*time.Second, 10*time.Millisecond)
+
+	jobs := s.Jobs()
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "every 20ms", jobs[0].Schedule)
+	assert.NotNil(t, jobs[0].NextRun)
+	assert.NotNil(t, jobs[0].LastRun)
+	assert.Equal(t, "boom", jobs[0].LastError)
+
+	assert.True(t, s.Cancel("tick"))
+	assert.Empty(t, s.Jobs())
+}
+
+func TestEveryIsAligned(t *testing.T) {
+	at := time.Date(2026, 3, 11, 10, 0, 30, 0, time.UTC)
+	assert.Equal(t, at.Add(30*time.Second), Every(time.Minute).Next(at))
+	assert.Equal(t, at.Add(90*time.Second), Every(time.Minute).Next(at.Add(30*time.Second)))
+}
+
+func TestAdmitMisfirePolicies(t *testing.T) {
+	now := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+	missed := now.Add(-3 * time.Minute)
+
+	tests := []struct {
+		name        string
+		policy      MisfirePolicy
+		due         time.Time
+		inFlight    int
+		wantRun     bool
+		wantNext    time.Time
+		wantQueued  bool
+		wantSkipped int64
+	}{
+		{name: "on time", policy: MisfireSkip, due: now, wantRun: true, wantQueued: true, wantNext: now.Add(time.Minute)},
+		{name: "skip late run", policy: MisfireSkip, due: missed, wantQueued: true, wantNext: now.Add(time.Minute), wantSkipped: 1},
+		{name: "run once", policy: MisfireRunOnce, due: missed, wantRun: true, wantQueued: true, wantNext: now.Add(time.Minute)},
+		{name: "catch up", policy: MisfireCatchUp, due: missed, wantRun: true, wantQueued: true, wantNext: missed.Add(time.Minute)},
+		{name: "skip while busy", policy: MisfireSkip, due: now, inFlight: 1, wantQueued: true, wantNext: now.Add(time.Minute), wantSkipped: 1},
+		{name: "defer while busy", policy: MisfireRunOnce, due: now, inFlight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(nil)
+			job := &Job{ID: "job", Recurrence: Every(time.Minute), Misfire: tt.policy, inFlight: tt.inFlight, Index: -1}
+			job.setNext(tt.due)
+
+			assert.Equal(t, tt.wantRun, s.admit(job, now))
+			assert.Equal(t, tt.wantQueued, s.jobs.Len() == 1)
+			assert.Equal(t, tt.wantSkipped, job.skipped)
+			if tt.wantQueued {
+				assert.Equal(t, tt.wantNext, job.ExecuteAt)
+			} else {
+				assert.True(t, job.deferred)
+			}
+		})
+	}
+}
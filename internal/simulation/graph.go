@@ -0,0 +1,328 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Dependency is a directed edge in the service graph: Service calls DependsOn,
+// so latency and errors in DependsOn propagate to Service.
+type Dependency struct {
+	Service       string  `yaml:"service" json:"service"`
+	DependsOn     string  `yaml:"depends_on" json:"depends_on"`
+	LatencyWeight float64 `yaml:"latency_weight" json:"latency_weight"` // share of upstream latency increase inherited
+	ErrorWeight   float64 `yaml:"error_weight" json:"error_weight"`     // share of upstream error increase inherited
+	Learned       bool    `yaml:"learned,omitempty" json:"learned,omitempty"`
+}
+
+// DependencyGraph holds the service dependency edges used by multi-service simulations
+type DependencyGraph struct {
+	mu           sync.RWMutex
+	dependencies []Dependency
+	callers      map[string][]Dependency // keyed by DependsOn
+}
+
+const (
+	defaultLatencyWeight = 0.5
+	defaultErrorWeight   = 0.5
+)
+
+// NewDependencyGraph builds a graph from a list of dependencies
+func NewDependencyGraph(deps []Dependency) (*DependencyGraph, error) {
+	g := &DependencyGraph{}
+	if err := g.Replace(deps); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// LoadDependencyGraph loads a dependency graph from a YAML file
+func LoadDependencyGraph(path string) (*DependencyGraph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dependency graph: %w", err)
+	}
+
+	var file struct {
+		Dependencies []Dependency `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse dependency graph: %w", err)
+	}
+
+	return NewDependencyGraph(file.Dependencies)
+}
+
+// Replace swaps the edges of the graph after validating them
+func (g *DependencyGraph) Replace(deps []Dependency) error {
+	normalized := make([]Dependency, 0, len(deps))
+	seen := make(map[string]bool)
+	for _, d := range deps {
+		if d.Service == "" || d.DependsOn == "" {
+			return fmt.Errorf("dependency requires service and depends_on")
+		}
+		if d.Service == d.DependsOn {
+			return fmt.Errorf("service %s cannot depend on itself", d.Service)
+		}
+		key := d.Service + "->" + d.DependsOn
+		if seen[key] {
+			return fmt.Errorf("duplicate dependency %s", key)
+		}
+		seen[key] = true
+
+		if d.LatencyWeight == 0 {
+			d.LatencyWeight = defaultLatencyWeight
+		}
+		if d.ErrorWeight == 0 {
+			d.ErrorWeight = defaultErrorWeight
+		}
+		d.LatencyWeight = math.Max(0, math.Min(1, d.LatencyWeight))
+		d.ErrorWeight = math.Max(0, math.Min(1, d.ErrorWeight))
+		normalized = append(normalized, d)
+	}
+
+	callers := make(map[string][]Dependency)
+	for _, d := range normalized {
+		callers[d.DependsOn] = append(callers[d.DependsOn], d)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.dependencies = normalized
+	g.callers = callers
+	return nil
+}
+
+// Dependencies returns a copy of all edges
+func (g *DependencyGraph) Dependencies() []Dependency {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	deps := make([]Dependency, len(g.dependencies))
+	copy(deps, g.dependencies)
+	return deps
+}
+
+// Callers returns the edges of services that depend on the given service
+func (g *DependencyGraph) Callers(serviceID string) []Dependency {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.callers[serviceID]
+}
+
+// Depths returns the number of hops from origin to every service reachable
+// by walking callers, i.e. every service a failure in origin can reach.
+func (g *DependencyGraph) Depths(origin string) map[string]int {
+	depths := map[string]int{origin: 0}
+	queue := []string{origin}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, d := range g.Callers(current) {
+			if _, visited := depths[d.Service]; visited {
+				continue
+			}
+			depths[d.Service] = depths[current] + 1
+			queue = append(queue, d.Service)
+		}
+	}
+
+	return depths
+}
+
+// LearnDependencies infers dependency edges from co-occurring metrics events.
+// Events are bucketed per minute; when the degradation signal of one service
+// is followed one bucket later by the same signal in another, the latter is
+// assumed to depend on the former. Pairs below minCorrelation are ignored.
+func LearnDependencies(eventsByService map[string][]models.Event, minCorrelation float64) []Dependency {
+	series := make(map[string]map[int64]bucket)
+	for serviceID, events := range eventsByService {
+		series[serviceID] = bucketize(events)
+	}
+
+	services := make([]string, 0, len(series))
+	for id := range series {
+		services = append(services, id)
+	}
+	sort.Strings(services)
+
+	var deps []Dependency
+	for i := 0; i < len(services); i++ {
+		for j := i + 1; j < len(services); j++ {
+			a, b := services[i], services[j]
+
+			aLeads := laggedCorrelation(series[a], series[b], signalDegradation)
+			bLeads := laggedCorrelation(series[b], series[a], signalDegradation)
+
+			upstream, downstream, corr := a, b, aLeads
+			if math.IsNaN(aLeads) || bLeads > aLeads {
+				upstream, downstream, corr = b, a, bLeads
+			}
+			if math.IsNaN(corr) || corr < minCorrelation {
+				continue
+			}
+
+			deps = append(deps, Dependency{
+				Service:       downstream,
+				DependsOn:     upstream,
+				LatencyWeight: clampWeight(laggedCorrelation(series[upstream], series[downstream], signalLatency)),
+				ErrorWeight:   clampWeight(laggedCorrelation(series[upstream], series[downstream], signalErrors)),
+				Learned:       true,
+			})
+		}
+	}
+
+	return deps
+}
+
+type bucket struct {
+	latency   float64
+	errorRate float64
+	count     int
+}
+
+func bucketize(events []models.Event) map[int64]bucket {
+	buckets := make(map[int64]bucket)
+	for _, evt := range events {
+		metrics, err := evt.GetMetricsPayload()
+		if err != nil {
+			continue
+		}
+		key := evt.Timestamp.Truncate(time.Minute).Unix()
+		b := buckets[key]
+		b.latency += metrics.Latency
+		b.errorRate += metrics.ErrorRate
+		b.count++
+		buckets[key] = b
+	}
+	for key, b := range buckets {
+		b.latency /= float64(b.count)
+		b.errorRate /= float64(b.count)
+		buckets[key] = b
+	}
+	return buckets
+}
+
+type signal int
+
+const (
+	signalLatency signal = iota
+	signalErrors
+	signalDegradation
+)
+
+// minAlignedBuckets is the minimum overlap needed before a correlation is trusted
+const minAlignedBuckets = 5
+
+// laggedCorrelation correlates leader[t] with follower[t+1m]
+func laggedCorrelation(leader, follower map[int64]bucket, sig signal) float64 {
+	var xs, ys []bucket
+	for ts, lb := range leader {
+		fb, ok := follower[ts+60]
+		if !ok {
+			continue
+		}
+		xs = append(xs, lb)
+		ys = append(ys, fb)
+	}
+	if len(xs) < minAlignedBuckets {
+		return math.NaN()
+	}
+
+	switch sig {
+	case signalLatency:
+		return pearson(latencies(xs), latencies(ys))
+	case signalErrors:
+		return pearson(errorRates(xs), errorRates(ys))
+	}
+
+	// Degradation combines standardized latency and error rate so that either
+	// kind of incident can reveal an edge.
+	return pearson(
+		sum(standardize(latencies(xs)), standardize(errorRates(xs))),
+		sum(standardize(latencies(ys)), standardize(errorRates(ys))),
+	)
+}
+
+func latencies(buckets []bucket) []float64 {
+	out := make([]float64, len(buckets))
+	for i, b := range buckets {
+		out[i] = b.latency
+	}
+	return out
+}
+
+func errorRates(buckets []bucket) []float64 {
+	out := make([]float64, len(buckets))
+	for i, b := range buckets {
+		out[i] = b.errorRate
+	}
+	return out
+}
+
+func sum(a, b []float64) []float64 {
+	out := make([]float64, len(a))
+	for i := range a {
+		out[i] = a[i] + b[i]
+	}
+	return out
+}
+
+func standardize(values []float64) []float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(values)))
+
+	out := make([]float64, len(values))
+	for i, v := range values {
+		if std > 0 {
+			out[i] = (v - mean) / std
+		}
+	}
+	return out
+}
+
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+func clampWeight(corr float64) float64 {
+	if math.IsNaN(corr) || corr <= 0 {
+		return 0.1
+	}
+	return math.Min(1, corr)
+}
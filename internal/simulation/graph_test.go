@@ -0,0 +1,154 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyGraphValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		deps    []Dependency
+		wantErr bool
+	}{
+		{
+			name: "valid graph",
+			deps: []Dependency{{Service: "checkout", DependsOn: "gateway"}},
+		},
+		{
+			name:    "missing depends_on",
+			deps:    []Dependency{{Service: "checkout"}},
+			wantErr: true,
+		},
+		{
+			name:    "self dependency",
+			deps:    []Dependency{{Service: "checkout", DependsOn: "checkout"}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate edge",
+			deps: []Dependency{
+				{Service: "checkout", DependsOn: "gateway"},
+				{Service: "checkout", DependsOn: "gateway"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDependencyGraph(tt.deps)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDependencyGraphDefaultsAndDepths(t *testing.T) {
+	graph, err := NewDependencyGraph([]Dependency{
+		{Service: "checkout", DependsOn: "gateway"},
+		{Service: "frontend", DependsOn: "checkout", LatencyWeight: 2},
+		{Service: "gateway", DependsOn: "frontend"}, // cycle
+	})
+	require.NoError(t, err)
+
+	deps := graph.Dependencies()
+	assert.Equal(t, defaultLatencyWeight, deps[0].LatencyWeight)
+	assert.Equal(t, defaultErrorWeight, deps[0].ErrorWeight)
+	assert.Equal(t, 1.0, deps[1].LatencyWeight)
+
+	depths := graph.Depths("gateway")
+	assert.Equal(t, map[string]int{"gateway": 0, "checkout": 1, "frontend": 2}, depths)
+}
+
+func TestLearnDependencies(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	gatewayLatency := []float64{100, 120, 400, 380, 110, 100, 600, 550, 130, 100, 90, 450}
+
+	events := map[string][]models.Event{}
+	for i, latency := range gatewayLatency {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		events["gateway"] = append(events["gateway"], metricsEvent(t, "gateway", ts, latency, 0.01))
+
+		// Checkout follows the gateway one minute later
+		checkoutLatency := 50.0
+		if i > 0 {
+			checkoutLatency += gatewayLatency[i-1] / 2
+		}
+		events["checkout"] = append(events["checkout"], metricsEvent(t, "checkout", ts, checkoutLatency, 0.01))
+	}
+
+	deps := LearnDependencies(events, 0.6)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "checkout", deps[0].Service)
+	assert.Equal(t, "gateway", deps[0].DependsOn)
+	assert.True(t, deps[0].Learned)
+	assert.Greater(t, deps[0].LatencyWeight, 0.9)
+}
+
+func TestRunMultiPropagatesFailure(t *testing.T) {
+	graph, err := NewDependencyGraph([]Dependency{
+		{Service: "checkout", DependsOn: "gateway", LatencyWeight: 1, ErrorWeight: 1},
+	})
+	require.NoError(t, err)
+	svc := NewService(nil, graph, nil)
+
+	req := &MultiSimulationRequest{
+		Services: map[string]*models.ServiceFeatures{
+			"gateway":   {CPUCurrent: 85, LatencyP95: 200, ErrorRate: 0.2},
+			"checkout":  {CPUCurrent: 40, LatencyP95: 100, ErrorRate: 0.01},
+			"reporting": {CPUCurrent: 40, LatencyP95: 100, ErrorRate: 0.01},
+		},
+		FailureService: "gateway",
+		Iterations:     200,
+	}
+
+	result, err := svc.RunMulti(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "failure", result.Scenario)
+	assert.Equal(t, "gateway", result.BlastRadius.Origin)
+	assert.Equal(t, 1, result.BlastRadius.MaxDepth)
+	require.Equal(t, 2, result.BlastRadius.Size)
+	assert.Equal(t, "gateway", result.BlastRadius.Impacted[0].ServiceID)
+	assert.Equal(t, "checkout", result.BlastRadius.Impacted[1].ServiceID)
+	assert.Equal(t, -1, result.Services["reporting"].Depth)
+	assert.Zero(t, result.Services["reporting"].ProbabilityImpacted)
+}
+
+func TestMultiSimulationValidation(t *testing.T) {
+	req := &MultiSimulationRequest{
+		Services: map[string]*models.ServiceFeatures{"gateway": {}},
+		Action:   &ProposedAction{Type: models.ActionTypeScaleUp, Target: "gateway"},
+	}
+	assert.Error(t, req.Validate())
+
+	req.Action.Type = models.ActionTypeOpenCircuit
+	require.NoError(t, req.Validate())
+	assert.Equal(t, "normal", req.Scenario)
+	assert.Equal(t, 0.5, req.ImpactThreshold)
+	assert.Equal(t, "gateway", req.origin())
+}
+
+func metricsEvent(t *testing.T, serviceID string, ts time.Time, latency, errorRate float64) models.Event {
+	t.Helper()
+	payload, err := json.Marshal(models.MetricsPayload{Latency: latency, ErrorRate: errorRate})
+	require.NoError(t, err)
+	return models.Event{
+		EventID:   fmt.Sprintf("%s-%d", serviceID, ts.Unix()),
+		ServiceID: serviceID,
+		EventType: models.EventTypeMetrics,
+		Payload:   payload,
+		Timestamp: ts,
+	}
+}
@@ -21,6 +21,9 @@ func NewHandler(service *Service) *Handler {
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/simulations", h.handleListSimulations)
 	mux.HandleFunc("/simulations/run", h.handleRunSimulation)
+	mux.HandleFunc("/simulations/multi", h.handleRunMultiSimulation)
+	mux.HandleFunc("/simulations/dependencies", h.handleDependencies)
+	mux.HandleFunc("/simulations/dependencies/learn", h.handleLearnDependencies)
 	mux.HandleFunc("/simulations/{id}", h.handleGetSimulation)
 }
 
@@ -47,6 +50,90 @@ func (h *Handler) handleRunSimulation(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+func (h *Handler) handleRunMultiSimulation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req MultiSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.RunMulti(r.Context(), &req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "simulation failed: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *Handler) handleDependencies(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var body struct {
+			Dependencies []Dependency `json:"dependencies"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if err := h.service.Graph().Replace(body.Dependencies); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	deps := h.service.Graph().Dependencies()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dependencies": deps,
+		"count":        len(deps),
+	})
+}
+
+func (h *Handler) handleLearnDependencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req LearnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	deps, err := h.service.LearnDependencies(r.Context(), &req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if deps == nil {
+		deps = []Dependency{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dependencies": deps,
+		"count":        len(deps),
+		"applied":      req.Apply,
+	})
+}
+
 func (h *Handler) handleListSimulations(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
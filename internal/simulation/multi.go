@@ -0,0 +1,385 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+)
+
+// openCircuitErrorRate is the share of calls to an open circuit assumed to
+// surface as caller errors once fallbacks have been applied
+const openCircuitErrorRate = 0.1
+
+// MultiSimulationRequest represents a request to project several services together
+type MultiSimulationRequest struct {
+	Services        map[string]*models.ServiceFeatures `json:"services"`
+	Dependencies    []Dependency                       `json:"dependencies,omitempty"` // Optional: overrides the configured graph
+	Scenario        string                             `json:"scenario"`
+	FailureService  string                             `json:"failure_service,omitempty"` // Service the scenario is applied to
+	Action          *ProposedAction                    `json:"action,omitempty"`
+	HorizonMinutes  int                                `json:"horizon_minutes"`
+	Iterations      int                                `json:"iterations"`
+	ImpactThreshold float64                            `json:"impact_threshold,omitempty"` // Min probability to count as impacted
+}
+
+// ProposedAction is an action whose blast radius should be projected
+type ProposedAction struct {
+	Type   models.ActionType `json:"type"`
+	Target string            `json:"target"`
+}
+
+// Validate validates the multi-service simulation request
+func (r *MultiSimulationRequest) Validate() error {
+	if len(r.Services) == 0 {
+		return fmt.Errorf("services are required")
+	}
+	for id, state := range r.Services {
+		if state == nil {
+			return fmt.Errorf("state for service %s is required", id)
+		}
+	}
+	if r.FailureService != "" {
+		if _, ok := r.Services[r.FailureService]; !ok {
+			return fmt.Errorf("failure_service %s has no state", r.FailureService)
+		}
+	}
+	if r.Action != nil {
+		if r.Action.Type != models.ActionTypeOpenCircuit {
+			return fmt.Errorf("unsupported action type for multi-service simulation: %s", r.Action.Type)
+		}
+		if _, ok := r.Services[r.Action.Target]; !ok {
+			return fmt.Errorf("action target %s has no state", r.Action.Target)
+		}
+	}
+	if r.HorizonMinutes < 5 || r.HorizonMinutes > 15 {
+		r.HorizonMinutes = 10
+	}
+	if r.Iterations < 100 {
+		r.Iterations = 1000
+	}
+	if r.Scenario == "" {
+		r.Scenario = "normal"
+		if r.FailureService != "" {
+			r.Scenario = "failure"
+		}
+	}
+	if r.ImpactThreshold <= 0 || r.ImpactThreshold > 1 {
+		r.ImpactThreshold = 0.5
+	}
+	return nil
+}
+
+// origin returns the service the blast radius is measured from
+func (r *MultiSimulationRequest) origin() string {
+	if r.FailureService != "" {
+		return r.FailureService
+	}
+	if r.Action != nil {
+		return r.Action.Target
+	}
+	return ""
+}
+
+// MultiSimulationResult represents the result of a multi-service simulation
+type MultiSimulationResult struct {
+	RunID          string                        `json:"run_id"`
+	Status         string                        `json:"status"`
+	Scenario       string                        `json:"scenario"`
+	FailureService string                        `json:"failure_service,omitempty"`
+	Action         *ProposedAction               `json:"action,omitempty"`
+	HorizonMinutes int                           `json:"horizon_minutes"`
+	Iterations     int                           `json:"iterations"`
+	Services       map[string]*ServiceProjection `json:"services"`
+	BlastRadius    BlastRadius                   `json:"blast_radius"`
+	StartedAt      time.Time                     `json:"started_at"`
+	CompletedAt    time.Time                     `json:"completed_at"`
+}
+
+// ServiceProjection is the projection of a single service within a multi-service run
+type ServiceProjection struct {
+	ServiceID           string               `json:"service_id"`
+	Depth               int                  `json:"depth"` // Hops from the origin, -1 if unreachable
+	ProjectedStates     []ProjectedState     `json:"projected_states"`
+	Aggregates          SimulationAggregates `json:"aggregates"`
+	RiskScore           float64              `json:"risk_score"`
+	ProbabilityDegraded float64              `json:"probability_degraded"` // Degraded for any reason
+	ProbabilityImpacted float64              `json:"probability_impacted"` // Degraded by propagation from dependencies
+}
+
+// BlastRadius summarizes which services a failure or action reaches
+type BlastRadius struct {
+	Origin   string            `json:"origin,omitempty"`
+	Impacted []ImpactedService `json:"impacted"`
+	Size     int               `json:"size"`
+	MaxDepth int               `json:"max_depth"`
+}
+
+// ImpactedService is a service inside the blast radius
+type ImpactedService struct {
+	ServiceID   string  `json:"service_id"`
+	Depth       int     `json:"depth"`
+	Probability float64 `json:"probability"`
+}
+
+// serviceSim holds the per-iteration state of one service
+type serviceSim struct {
+	id       string
+	scenario string
+
+	// Intrinsic state evolves with the service's own dynamics
+	cpu       float64
+	latency   float64
+	errorRate float64
+
+	// Effective state includes what is inherited from dependencies
+	effLatency   float64
+	effErrorRate float64
+
+	baseLatency   float64
+	baseErrorRate float64
+
+	impacted bool
+	degraded bool
+}
+
+// RunMulti projects several services together, propagating latency and
+// errors along the dependency graph
+func (s *Service) RunMulti(ctx context.Context, req *MultiSimulationRequest) (*MultiSimulationResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	graph := s.graph
+	if len(req.Dependencies) > 0 {
+		override, err := NewDependencyGraph(req.Dependencies)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependencies: %w", err)
+		}
+		graph = override
+	}
+
+	start := time.Now()
+	runID := fmt.Sprintf("msim-%d", start.UnixNano())
+	origin := req.origin()
+
+	s.logger.Info("starting multi-service simulation",
+		"run_id", runID,
+		"services", len(req.Services),
+		"scenario", req.Scenario,
+		"origin", origin,
+	)
+
+	ids := make([]string, 0, len(req.Services))
+	for id := range req.Services {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	projections := make(map[string][][]ProjectedState, len(ids))
+	impactedCount := make(map[string]int, len(ids))
+	degradedCount := make(map[string]int, len(ids))
+
+	for i := 0; i < req.Iterations; i++ {
+		if i%100 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		states, sims := s.projectGraph(req, graph, ids)
+		for _, id := range ids {
+			projections[id] = append(projections[id], states[id])
+			if sims[id].impacted {
+				impactedCount[id]++
+			}
+			if sims[id].degraded {
+				degradedCount[id]++
+			}
+		}
+	}
+
+	depths := map[string]int{}
+	if origin != "" {
+		depths = graph.Depths(origin)
+	}
+
+	result := &MultiSimulationResult{
+		RunID:          runID,
+		Status:         "completed",
+		Scenario:       req.Scenario,
+		FailureService: req.FailureService,
+		Action:         req.Action,
+		HorizonMinutes: req.HorizonMinutes,
+		Iterations:     req.Iterations,
+		Services:       make(map[string]*ServiceProjection, len(ids)),
+		BlastRadius:    BlastRadius{Origin: origin, Impacted: []ImpactedService{}},
+		StartedAt:      start,
+	}
+
+	n := float64(req.Iterations)
+	for _, id := range ids {
+		depth, reachable := depths[id]
+		if !reachable {
+			depth = -1
+		}
+
+		agg := s.calculateAggregates(projections[id], req.HorizonMinutes)
+		proj := &ServiceProjection{
+			ServiceID:           id,
+			Depth:               depth,
+			ProjectedStates:     s.aggregateProjections(projections[id], req.HorizonMinutes),
+			Aggregates:          agg,
+			RiskScore:           s.calculateRiskScore(agg),
+			ProbabilityDegraded: float64(degradedCount[id]) / n,
+			ProbabilityImpacted: float64(impactedCount[id]) / n,
+		}
+		result.Services[id] = proj
+
+		inRadius := id == origin || (reachable && proj.ProbabilityImpacted >= req.ImpactThreshold)
+		if origin == "" || !inRadius {
+			continue
+		}
+
+		probability := proj.ProbabilityImpacted
+		if id == origin {
+			probability = math.Max(proj.ProbabilityDegraded, proj.ProbabilityImpacted)
+		}
+		result.BlastRadius.Impacted = append(result.BlastRadius.Impacted, ImpactedService{
+			ServiceID:   id,
+			Depth:       depth,
+			Probability: probability,
+		})
+		if depth > result.BlastRadius.MaxDepth {
+			result.BlastRadius.MaxDepth = depth
+		}
+	}
+
+	sort.Slice(result.BlastRadius.Impacted, func(i, j int) bool {
+		a, b := result.BlastRadius.Impacted[i], result.BlastRadius.Impacted[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		return a.ServiceID < b.ServiceID
+	})
+	result.BlastRadius.Size = len(result.BlastRadius.Impacted)
+	result.CompletedAt = time.Now()
+
+	s.logger.Info("multi-service simulation completed",
+		"run_id", runID,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"blast_radius", result.BlastRadius.Size,
+	)
+
+	return result, nil
+}
+
+// projectGraph runs one Monte Carlo iteration for all services. Propagation
+// uses the upstream state of the previous minute, so each hop adds one minute
+// of delay and cycles in the graph do not need special handling.
+func (s *Service) projectGraph(req *MultiSimulationRequest, graph *DependencyGraph, ids []string) (map[string][]ProjectedState, map[string]*serviceSim) {
+	sims := make(map[string]*serviceSim, len(ids))
+	for _, id := range ids {
+		current := req.Services[id]
+		scenario := "normal"
+		if id == req.FailureService || req.FailureService == "" {
+			scenario = req.Scenario
+		}
+		if req.Action != nil && id == req.Action.Target {
+			// An open circuit sheds the load reaching the target
+			scenario = "recovery"
+		}
+		sims[id] = &serviceSim{
+			id:            id,
+			scenario:      scenario,
+			cpu:           current.CPUCurrent,
+			latency:       current.LatencyP95,
+			errorRate:     current.ErrorRate,
+			effLatency:    current.LatencyP95,
+			effErrorRate:  current.ErrorRate,
+			baseLatency:   current.LatencyP95,
+			baseErrorRate: current.ErrorRate,
+		}
+	}
+
+	states := make(map[string][]ProjectedState, len(ids))
+	for minute := 1; minute <= req.HorizonMinutes; minute++ {
+		// Snapshot the previous minute before anything moves
+		prevLatency := make(map[string]float64, len(ids))
+		prevErrors := make(map[string]float64, len(ids))
+		for _, id := range ids {
+			prevLatency[id] = sims[id].effLatency
+			prevErrors[id] = sims[id].effErrorRate
+		}
+
+		for _, id := range ids {
+			sim := sims[id]
+			s.stepService(sim)
+
+			var inheritedLatency, inheritedErrors float64
+			for _, dep := range graph.dependenciesOf(id) {
+				upstream, ok := sims[dep.DependsOn]
+				if !ok {
+					continue
+				}
+				if req.Action != nil && dep.DependsOn == req.Action.Target {
+					// Calls fail fast instead of waiting on the target
+					inheritedErrors += dep.ErrorWeight * openCircuitErrorRate
+					continue
+				}
+				inheritedLatency += dep.LatencyWeight * math.Max(0, prevLatency[dep.DependsOn]-upstream.baseLatency)
+				inheritedErrors += dep.ErrorWeight * math.Max(0, prevErrors[dep.DependsOn]-upstream.baseErrorRate)
+			}
+
+			sim.effLatency = sim.latency + inheritedLatency
+			sim.effErrorRate = math.Min(1, sim.errorRate+inheritedErrors)
+
+			if inheritedLatency > 0.25*math.Max(sim.baseLatency, 1) || inheritedErrors > 0.02 {
+				sim.impacted = true
+			}
+			if sim.effLatency > 1.5*math.Max(sim.baseLatency, 1) || sim.effErrorRate > sim.baseErrorRate+0.05 {
+				sim.degraded = true
+			}
+
+			states[id] = append(states[id], ProjectedState{
+				Minute:     minute,
+				CPUAvg:     sim.cpu,
+				CPUP50:     sim.cpu * (0.9 + 0.2*rand.Float64()),
+				CPUP95:     sim.cpu * (1.1 + 0.3*rand.Float64()),
+				LatencyAvg: sim.effLatency,
+				ErrorRate:  sim.effErrorRate,
+			})
+		}
+	}
+
+	return states, sims
+}
+
+// stepService advances a service's intrinsic state by one minute
+func (s *Service) stepService(sim *serviceSim) {
+	cpuTrend, errorTrend, noiseFactor := scenarioParams(sim.scenario)
+
+	sim.cpu = sim.cpu * (1 + cpuTrend + noiseFactor*(rand.Float64()-0.5))
+	sim.errorRate = math.Min(1.0, sim.errorRate*(1+errorTrend+noiseFactor*(rand.Float64()-0.5)))
+	sim.latency = sim.latency * (1 + (sim.cpu-50)/200 + noiseFactor*(rand.Float64()-0.5))
+
+	sim.cpu = math.Max(0, math.Min(100, sim.cpu))
+	sim.errorRate = math.Max(0, math.Min(1, sim.errorRate))
+	sim.latency = math.Max(0, sim.latency)
+}
+
+// dependenciesOf returns the edges on which the given service depends
+func (g *DependencyGraph) dependenciesOf(serviceID string) []Dependency {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var deps []Dependency
+	for _, d := range g.dependencies {
+		if d.Service == serviceID {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
@@ -9,22 +9,87 @@ import (
 	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
 )
 
 // Service runs Monte Carlo simulations
 type Service struct {
-	logger *slog.Logger
+	eventStore *postgres.EventStore
+	graph      *DependencyGraph
+	logger     *slog.Logger
 }
 
 // NewService creates a new simulation service
-func NewService(logger *slog.Logger) *Service {
+func NewService(eventStore *postgres.EventStore, graph *DependencyGraph, logger *slog.Logger) *Service {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if graph == nil {
+		graph = &DependencyGraph{}
+	}
 	rand.Seed(time.Now().UnixNano())
 	return &Service{
-		logger: logger,
+		eventStore: eventStore,
+		graph:      graph,
+		logger:     logger,
+	}
+}
+
+// Graph returns the dependency graph used by multi-service simulations
+func (s *Service) Graph() *DependencyGraph {
+	return s.graph
+}
+
+// LearnRequest represents a request to learn dependencies from recent events
+type LearnRequest struct {
+	ServiceIDs     []string `json:"service_ids"`
+	Window         string   `json:"window"`
+	MinCorrelation float64  `json:"min_correlation"`
+	Apply          bool     `json:"apply"` // Replace the configured graph with the learned edges
+}
+
+// LearnDependencies infers dependency edges from the stored events of the given services
+func (s *Service) LearnDependencies(ctx context.Context, req *LearnRequest) ([]Dependency, error) {
+	if s.eventStore == nil {
+		return nil, fmt.Errorf("event store not configured")
+	}
+	if len(req.ServiceIDs) < 2 {
+		return nil, fmt.Errorf("at least two service_ids are required")
+	}
+
+	window := time.Hour
+	if req.Window != "" {
+		d, err := time.ParseDuration(req.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window: %w", err)
+		}
+		window = d
 	}
+	if req.MinCorrelation <= 0 {
+		req.MinCorrelation = 0.6
+	}
+
+	to := time.Now()
+	from := to.Add(-window)
+	eventsByService := make(map[string][]models.Event, len(req.ServiceIDs))
+	for _, serviceID := range req.ServiceIDs {
+		events, err := s.eventStore.GetByService(ctx, serviceID, from, to, 10000)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load events for %s: %w", serviceID, err)
+		}
+		eventsByService[serviceID] = events
+	}
+
+	deps := LearnDependencies(eventsByService, req.MinCorrelation)
+
+	if req.Apply {
+		if err := s.graph.Replace(deps); err != nil {
+			return nil, fmt.Errorf("failed to apply learned dependencies: %w", err)
+		}
+		s.logger.Info("applied learned dependencies", "edges", len(deps))
+	}
+
+	return deps, nil
 }
 
 // SimulationRequest represents a request to run a simulation
@@ -155,22 +220,7 @@ func (s *Service) projectState(current *models.ServiceFeatures, horizon int, sce
 	latency := current.LatencyP95
 	errorRate := current.ErrorRate
 
-	// Scenario parameters
-	cpuTrend := 0.0
-	errorTrend := 0.0
-	noiseFactor := 0.1
-
-	switch scenario {
-	case "high_load":
-		cpuTrend = 0.03
-		noiseFactor = 0.15
-	case "failure":
-		errorTrend = 0.02
-		noiseFactor = 0.2
-	case "recovery":
-		cpuTrend = -0.02
-		noiseFactor = 0.08
-	}
+	cpuTrend, errorTrend, noiseFactor := scenarioParams(scenario)
 
 	for minute := 1; minute <= horizon; minute++ {
 		cpu = cpu * (1 + cpuTrend + noiseFactor*(rand.Float64()-0.5))
@@ -194,6 +244,25 @@ func (s *Service) projectState(current *models.ServiceFeatures, horizon int, sce
 	return states
 }
 
+// scenarioParams returns the per-minute CPU trend, error trend and noise factor for a scenario
+func scenarioParams(scenario string) (cpuTrend, errorTrend, noiseFactor float64) {
+	noiseFactor = 0.1
+
+	switch scenario {
+	case "high_load":
+		cpuTrend = 0.03
+		noiseFactor = 0.15
+	case "failure":
+		errorTrend = 0.02
+		noiseFactor = 0.2
+	case "recovery":
+		cpuTrend = -0.02
+		noiseFactor = 0.08
+	}
+
+	return cpuTrend, errorTrend, noiseFactor
+}
+
 func (s *Service) aggregateProjections(projections [][]ProjectedState, horizon int) []ProjectedState {
 	aggregated := make([]ProjectedState, horizon)
 
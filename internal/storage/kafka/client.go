@@ -35,15 +35,28 @@ func (c *Client) NewWriter(topic string) *kafka.Writer {
 	}
 }
 
+// NewSyncWriter creates a Kafka writer for a topic whose writes return only
+// once every in-sync replica has the messages, for topics that are the
+// system of record
+func (c *Client) NewSyncWriter(topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(c.brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchSize:    100,
+		BatchTimeout: 10 * time.Millisecond,
+	}
+}
+
 // NewReader creates a new Kafka reader for a topic
 func (c *Client) NewReader(topic, groupID string) *kafka.Reader {
 	return kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        c.brokers,
-		Topic:          topic,
-		GroupID:        groupID,
-		MinBytes:       10e3, // 10KB
-		MaxBytes:       10e6, // 10MB
-		CommitInterval: time.Second,
+		Brokers:  c.brokers,
+		Topic:    topic,
+		GroupID:  groupID,
+		MinBytes: 10e3, // 10KB
+		MaxBytes: 10e6, // 10MB
 	})
 }
 
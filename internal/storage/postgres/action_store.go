@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -19,82 +20,73 @@ func NewActionStore(client *Client) *ActionStore {
 	return &ActionStore{client: client}
 }
 
+const actionColumns = `id, action_id, decision_id, action_type, action_payload, target_service,
+			status, dry_run, scheduled_at, executed_at, completed_at, COALESCE(error_message, ''),
+			retry_count, COALESCE(webhook_url, ''), webhook_response, COALESCE(rollback_of, ''),
+			approval_expires_at, COALESCE(reviewed_by, ''), reviewed_at, COALESCE(review_comment, ''),
+			cost, rollout, created_at, updated_at`
+
 // Store persists an action record
-func (s *ActionStore) Store(ctx context.Context, action *models.Action) error {
+func (s *ActionStore) Store(ctx context.Context, action *models.ActionRecord) error {
+	rollout, err := marshalRollout(action.Rollout)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO action_records (
 			action_id, decision_id, action_type, action_payload, target_service,
-			status, dry_run, scheduled_at, webhook_url
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, created_at`
-
-	return s.client.Pool().QueryRow(ctx, query,
-		action.Type, // Using type as ID for simplicity
-		"",          // decision_id would be set properly in real usage
-		action.Type,
-		action.Payload,
-		action.Target,
-		"pending",
-		false,
-		nil,
-		"",
-	).Scan(nil, nil)
+			status, dry_run, scheduled_at, webhook_url, rollback_of, approval_expires_at, cost, rollout
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13)
+		RETURNING id, created_at, updated_at`
+
+	err = s.client.Pool().QueryRow(ctx, query,
+		action.ActionID,
+		action.DecisionID,
+		action.ActionType,
+		action.ActionPayload,
+		action.TargetService,
+		action.Status,
+		action.DryRun,
+		action.ScheduledAt,
+		action.WebhookURL,
+		action.RollbackOf,
+		action.ApprovalExpiresAt,
+		action.Cost,
+		rollout,
+	).Scan(&action.ID, &action.CreatedAt, &action.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to store action: %w", err)
+	}
+
+	return nil
 }
 
 // GetByID retrieves an action by ID
-func (s *ActionStore) GetByID(ctx context.Context, actionID string) (*ActionRecord, error) {
-	query := `
-		SELECT id, action_id, decision_id, action_type, action_payload, target_service,
-			status, dry_run, scheduled_at, executed_at, completed_at, error_message,
-			retry_count, webhook_url, webhook_response, created_at, updated_at
-		FROM action_records WHERE action_id = $1`
-
-	var record ActionRecord
-	err := s.client.Pool().QueryRow(ctx, query, actionID).Scan(
-		&record.ID, &record.ActionID, &record.DecisionID, &record.ActionType,
-		&record.ActionPayload, &record.TargetService, &record.Status, &record.DryRun,
-		&record.ScheduledAt, &record.ExecutedAt, &record.CompletedAt, &record.ErrorMessage,
-		&record.RetryCount, &record.WebhookURL, &record.WebhookResponse,
-		&record.CreatedAt, &record.UpdatedAt,
-	)
+func (s *ActionStore) GetByID(ctx context.Context, actionID string) (*models.ActionRecord, error) {
+	query := `SELECT ` + actionColumns + ` FROM action_records WHERE action_id = $1`
+
+	rows, err := s.client.Pool().Query(ctx, query, actionID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("action not found: %s", actionID)
-		}
 		return nil, err
 	}
+	defer rows.Close()
 
-	return &record, nil
-}
+	actions, err := scanActionRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) == 0 {
+		return nil, models.ErrActionNotFound
+	}
 
-// ActionRecord represents a stored action
-type ActionRecord struct {
-	ID              string      `db:"id"`
-	ActionID        string      `db:"action_id"`
-	DecisionID      string      `db:"decision_id"`
-	ActionType      string      `db:"action_type"`
-	ActionPayload   interface{} `db:"action_payload"`
-	TargetService   string      `db:"target_service"`
-	Status          string      `db:"status"`
-	DryRun          bool        `db:"dry_run"`
-	ScheduledAt     interface{} `db:"scheduled_at"`
-	ExecutedAt      interface{} `db:"executed_at"`
-	CompletedAt     interface{} `db:"completed_at"`
-	ErrorMessage    string      `db:"error_message"`
-	RetryCount      int         `db:"retry_count"`
-	WebhookURL      string      `db:"webhook_url"`
-	WebhookResponse interface{} `db:"webhook_response"`
-	CreatedAt       interface{} `db:"created_at"`
-	UpdatedAt       interface{} `db:"updated_at"`
+	return actions[0], nil
 }
 
 // ListActions retrieves actions with filters
-func (s *ActionStore) ListActions(ctx context.Context, filters ActionFilters) ([]*ActionRecord, error) {
-	query := `
-		SELECT id, action_id, decision_id, action_type, action_payload, target_service,
-			status, dry_run, scheduled_at, executed_at, completed_at, error_message,
-			retry_count, created_at, updated_at
-		FROM action_records WHERE 1=1`
+func (s *ActionStore) ListActions(ctx context.Context, filters models.ActionFilters) ([]*models.ActionRecord, error) {
+	query := `SELECT ` + actionColumns + ` FROM action_records WHERE 1=1`
 	args := []interface{}{}
 	argCount := 0
 
@@ -113,6 +105,16 @@ func (s *ActionStore) ListActions(ctx context.Context, filters ActionFilters) ([
 		query += fmt.Sprintf(" AND status = $%d", argCount)
 		args = append(args, filters.Status)
 	}
+	if filters.RollbackOf != "" {
+		argCount++
+		query += fmt.Sprintf(" AND rollback_of = $%d", argCount)
+		args = append(args, filters.RollbackOf)
+	}
+	if !filters.Since.IsZero() {
+		argCount++
+		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, filters.Since)
+	}
 
 	query += " ORDER BY created_at DESC"
 
@@ -131,32 +133,90 @@ func (s *ActionStore) ListActions(ctx context.Context, filters ActionFilters) ([
 	return scanActionRows(rows)
 }
 
-// ActionFilters for listing actions
-type ActionFilters struct {
-	DecisionID string
-	ServiceID  string
-	Status     string
-	Limit      int
+// UpdateStatus moves an action from one status to another. The update only
+// applies while the action is still in the expected status, so concurrent
+// writers cannot skip a step of the lifecycle.
+func (s *ActionStore) UpdateStatus(ctx context.Context, actionID string, from, to models.ActionStatus, errorMsg string) error {
+	if !models.ValidTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", models.ErrInvalidTransition, from, to)
+	}
+
+	query := `
+		UPDATE action_records 
+		SET status = $1,
+			error_message = NULLIF($2, ''),
+			executed_at = CASE WHEN $1 = 'executing' THEN NOW() ELSE executed_at END,
+			completed_at = CASE WHEN $1 IN ('completed', 'failed', 'cancelled', 'rejected') THEN NOW() ELSE completed_at END,
+			updated_at = NOW()
+		WHERE action_id = $3 AND status = $4`
+	tag, err := s.client.Pool().Exec(ctx, query, to, errorMsg, actionID, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s is no longer %s", models.ErrInvalidTransition, actionID, from)
+	}
+	return nil
 }
 
-// UpdateStatus updates action status
-func (s *ActionStore) UpdateStatus(ctx context.Context, actionID, status string, errorMsg string) error {
+// Review records the outcome of an approval, moving an awaiting_approval
+// action to pending (approved) or rejected along with who reviewed it
+func (s *ActionStore) Review(ctx context.Context, actionID string, to models.ActionStatus, reviewer, comment string) error {
+	if !models.ValidTransition(models.ActionStatusAwaitingApproval, to) {
+		return fmt.Errorf("%w: %s -> %s", models.ErrInvalidTransition, models.ActionStatusAwaitingApproval, to)
+	}
+
 	query := `
 		UPDATE action_records 
-		SET status = $1, error_message = $2, updated_at = NOW()
-		WHERE action_id = $3`
-	_, err := s.client.Pool().Exec(ctx, query, status, errorMsg, actionID)
-	return err
+		SET status = $1,
+			reviewed_by = $2,
+			reviewed_at = NOW(),
+			review_comment = NULLIF($3, ''),
+			completed_at = CASE WHEN $1 = 'rejected' THEN NOW() ELSE completed_at END,
+			updated_at = NOW()
+		WHERE action_id = $4 AND status = 'awaiting_approval'`
+	tag, err := s.client.Pool().Exec(ctx, query, to, reviewer, comment, actionID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s is not awaiting approval", models.ErrInvalidTransition, actionID)
+	}
+	return nil
 }
 
-// MarkExecuted marks an action as executed
-func (s *ActionStore) MarkExecuted(ctx context.Context, actionID string, response interface{}) error {
+// GetExpiredApprovals retrieves actions still awaiting approval past their expiry
+func (s *ActionStore) GetExpiredApprovals(ctx context.Context, limit int) ([]*models.ActionRecord, error) {
+	query := `SELECT ` + actionColumns + `
+		FROM action_records 
+		WHERE status = 'awaiting_approval' AND approval_expires_at <= NOW()
+		ORDER BY approval_expires_at ASC
+		LIMIT $1`
+
+	rows, err := s.client.Pool().Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanActionRows(rows)
+}
+
+// MarkExecuted marks an executing action as completed and stores the webhook response
+func (s *ActionStore) MarkExecuted(ctx context.Context, actionID string, response json.RawMessage) error {
 	query := `
 		UPDATE action_records 
-		SET status = 'completed', executed_at = NOW(), completed_at = NOW(), webhook_response = $1, updated_at = NOW()
-		WHERE action_id = $2`
-	_, err := s.client.Pool().Exec(ctx, query, response, actionID)
-	return err
+		SET status = 'completed', executed_at = COALESCE(executed_at, NOW()), completed_at = NOW(),
+			webhook_response = $1, updated_at = NOW()
+		WHERE action_id = $2 AND status = 'executing'`
+	tag, err := s.client.Pool().Exec(ctx, query, response, actionID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s is not executing", models.ErrInvalidTransition, actionID)
+	}
+	return nil
 }
 
 // IncrementRetry increments retry count
@@ -169,16 +229,70 @@ func (s *ActionStore) IncrementRetry(ctx context.Context, actionID string) error
 	return err
 }
 
-// GetPendingActions retrieves pending or scheduled actions
-func (s *ActionStore) GetPendingActions(ctx context.Context, limit int) ([]*ActionRecord, error) {
+// GetPendingActions claims up to limit scheduled actions that are due and
+// moves them to executing. Rows locked by another replica are skipped, so
+// each action is handed to exactly one caller.
+func (s *ActionStore) GetPendingActions(ctx context.Context, limit int) ([]*models.ActionRecord, error) {
 	query := `
-		SELECT id, action_id, decision_id, action_type, action_payload, target_service,
-			status, dry_run, scheduled_at, retry_count, created_at
-		FROM action_records 
-		WHERE status IN ('pending', 'scheduled')
-			AND (scheduled_at IS NULL OR scheduled_at <= NOW())
-		ORDER BY created_at ASC
-		LIMIT $1`
+		UPDATE action_records 
+		SET status = 'executing', executed_at = NOW(), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM action_records 
+			WHERE status = 'scheduled' AND scheduled_at <= NOW()
+			ORDER BY scheduled_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + actionColumns
+
+	rows, err := s.client.Pool().Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanActionRows(rows)
+}
+
+// UpdateRollout stores the rollout of an executing action. The update only
+// applies while the stored rollout is still in the expected status, so two
+// replicas cannot advance the same rollout.
+func (s *ActionStore) UpdateRollout(ctx context.Context, actionID string, from models.RolloutStatus, rollout *models.Rollout) error {
+	data, err := marshalRollout(rollout)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE action_records 
+		SET rollout = $1, updated_at = NOW()
+		WHERE action_id = $2 AND status = 'executing' AND rollout->>'status' = $3`
+	tag, err := s.client.Pool().Exec(ctx, query, data, actionID, from)
+	if err != nil {
+		return fmt.Errorf("failed to update rollout: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: rollout of %s is no longer %s", models.ErrInvalidTransition, actionID, from)
+	}
+	return nil
+}
+
+// ClaimDueRollouts claims up to limit rollouts whose observation window has
+// passed and moves them to evaluating. Rows locked by another replica are
+// skipped, so each stage is evaluated once.
+func (s *ActionStore) ClaimDueRollouts(ctx context.Context, limit int) ([]*models.ActionRecord, error) {
+	query := `
+		UPDATE action_records 
+		SET rollout = jsonb_set(rollout, '{status}', '"evaluating"'), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM action_records 
+			WHERE status = 'executing' AND rollout->>'status' = 'observing'
+				AND (rollout->>'next_check_at')::timestamptz <= NOW()
+			ORDER BY (rollout->>'next_check_at')::timestamptz ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + actionColumns
 
 	rows, err := s.client.Pool().Query(ctx, query, limit)
 	if err != nil {
@@ -189,6 +303,17 @@ func (s *ActionStore) GetPendingActions(ctx context.Context, limit int) ([]*Acti
 	return scanActionRows(rows)
 }
 
+func marshalRollout(rollout *models.Rollout) ([]byte, error) {
+	if rollout == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(rollout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rollout: %w", err)
+	}
+	return data, nil
+}
+
 // GetActionStats returns action statistics
 func (s *ActionStore) GetActionStats(ctx context.Context, serviceID string, since time.Time) (map[string]int64, error) {
 	query := `
@@ -216,18 +341,26 @@ func (s *ActionStore) GetActionStats(ctx context.Context, serviceID string, sinc
 	return stats, rows.Err()
 }
 
-func scanActionRows(rows pgx.Rows) ([]*ActionRecord, error) {
-	var actions []*ActionRecord
+func scanActionRows(rows pgx.Rows) ([]*models.ActionRecord, error) {
+	var actions []*models.ActionRecord
 	for rows.Next() {
-		var a ActionRecord
+		var a models.ActionRecord
+		var rollout []byte
 		err := rows.Scan(
 			&a.ID, &a.ActionID, &a.DecisionID, &a.ActionType, &a.ActionPayload,
 			&a.TargetService, &a.Status, &a.DryRun, &a.ScheduledAt, &a.ExecutedAt,
-			&a.CompletedAt, &a.ErrorMessage, &a.RetryCount, &a.CreatedAt, &a.UpdatedAt,
+			&a.CompletedAt, &a.ErrorMessage, &a.RetryCount, &a.WebhookURL,
+			&a.WebhookResponse, &a.RollbackOf, &a.ApprovalExpiresAt, &a.ReviewedBy,
+			&a.ReviewedAt, &a.ReviewComment, &a.Cost, &rollout, &a.CreatedAt, &a.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if len(rollout) > 0 {
+			if err := json.Unmarshal(rollout, &a.Rollout); err != nil {
+				return nil, fmt.Errorf("invalid rollout for action %s: %w", a.ActionID, err)
+			}
+		}
 		actions = append(actions, &a)
 	}
 	return actions, rows.Err()
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/config"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -79,11 +81,8 @@ func (c *Client) Transaction(ctx context.Context, fn func(pgx.Tx) error) error {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		}
-	}()
+	// A no-op once the transaction is committed
+	defer tx.Rollback(ctx)
 
 	if err := fn(tx); err != nil {
 		return err
@@ -95,3 +94,17 @@ func (c *Client) Transaction(ctx context.Context, fn func(pgx.Tx) error) error {
 
 	return nil
 }
+
+// IsPermanent reports whether err is a data or integrity error, such as a
+// check constraint violation, that writing the same values again cannot fix
+func IsPermanent(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return false
+	}
+	switch pgErr.Code[:2] {
+	case "22", "23":
+		return true
+	}
+	return false
+}
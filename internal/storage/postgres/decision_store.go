@@ -24,8 +24,9 @@ func (s *DecisionStore) Store(ctx context.Context, decision *models.DecisionReco
 		INSERT INTO decision_records (
 			decision_id, idempotency_key, service_id, policy_id, policy_version,
 			snapshot_id, decision_type, decision_result, actions, 
-			confidence_score, simulation_run_id, dry_run, executed_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			confidence_score, simulation_run_id, dry_run, shadow, shadow_of,
+			experiment_id, experiment_arm, executed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		ON CONFLICT (idempotency_key) DO NOTHING
 		RETURNING id, created_at`
 
@@ -42,6 +43,10 @@ func (s *DecisionStore) Store(ctx context.Context, decision *models.DecisionReco
 		decision.ConfidenceScore,
 		decision.SimulationRunID,
 		decision.DryRun,
+		decision.Shadow,
+		decision.ShadowOf,
+		decision.ExperimentID,
+		decision.ExperimentArm,
 		decision.ExecutedAt,
 	).Scan(&decision.ID, &decision.CreatedAt)
 
@@ -54,18 +59,29 @@ func (s *DecisionStore) Store(ctx context.Context, decision *models.DecisionReco
 
 // GetByID retrieves a decision by its ID
 func (s *DecisionStore) GetByID(ctx context.Context, decisionID string) (*models.DecisionRecord, error) {
+	return s.getBy(ctx, "decision_id", decisionID)
+}
+
+// GetByIdempotencyKey retrieves the decision stored under an idempotency key
+func (s *DecisionStore) GetByIdempotencyKey(ctx context.Context, key string) (*models.DecisionRecord, error) {
+	return s.getBy(ctx, "idempotency_key", key)
+}
+
+func (s *DecisionStore) getBy(ctx context.Context, column, value string) (*models.DecisionRecord, error) {
 	query := `
 		SELECT id, decision_id, idempotency_key, service_id, policy_id, policy_version,
 			snapshot_id, decision_type, decision_result, actions, 
-			confidence_score, simulation_run_id, dry_run, executed_at, created_at
-		FROM decision_records WHERE decision_id = $1`
+			confidence_score, simulation_run_id, dry_run, shadow, shadow_of,
+			experiment_id, experiment_arm, executed_at, created_at
+		FROM decision_records WHERE ` + column + ` = $1`
 
 	var decision models.DecisionRecord
-	err := s.client.Pool().QueryRow(ctx, query, decisionID).Scan(
+	err := s.client.Pool().QueryRow(ctx, query, value).Scan(
 		&decision.ID, &decision.DecisionID, &decision.IdempotencyKey, &decision.ServiceID,
 		&decision.PolicyID, &decision.PolicyVersion, &decision.SnapshotID,
 		&decision.DecisionType, &decision.DecisionResult, &decision.Actions,
 		&decision.ConfidenceScore, &decision.SimulationRunID, &decision.DryRun,
+		&decision.Shadow, &decision.ShadowOf, &decision.ExperimentID, &decision.ExperimentArm,
 		&decision.ExecutedAt, &decision.CreatedAt,
 	)
 	if err != nil {
@@ -83,8 +99,8 @@ func (s *DecisionStore) StoreTrace(ctx context.Context, trace *models.DecisionTr
 	query := `
 		INSERT INTO decision_traces (
 			trace_id, decision_id, policy_id, policy_version, trace_data,
-			rules_evaluated, rules_matched, features_used, execution_time_ms
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			rules_evaluated, rules_matched, features_used, execution_time_ms, guardrails
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, '[]'::jsonb))
 		RETURNING id, created_at`
 
 	err := s.client.Pool().QueryRow(ctx, query,
@@ -97,6 +113,7 @@ func (s *DecisionStore) StoreTrace(ctx context.Context, trace *models.DecisionTr
 		trace.RulesMatched,
 		trace.FeaturesUsed,
 		trace.ExecutionTimeMs,
+		trace.Guardrails,
 	).Scan(&trace.ID, &trace.CreatedAt)
 
 	if err != nil {
@@ -110,14 +127,15 @@ func (s *DecisionStore) StoreTrace(ctx context.Context, trace *models.DecisionTr
 func (s *DecisionStore) GetTraceByDecisionID(ctx context.Context, decisionID string) (*models.DecisionTrace, error) {
 	query := `
 		SELECT id, trace_id, decision_id, policy_id, policy_version, trace_data,
-			rules_evaluated, rules_matched, features_used, execution_time_ms, created_at
+			rules_evaluated, rules_matched, features_used, execution_time_ms, guardrails, created_at
 		FROM decision_traces WHERE decision_id = $1`
 
 	var trace models.DecisionTrace
 	err := s.client.Pool().QueryRow(ctx, query, decisionID).Scan(
 		&trace.ID, &trace.TraceID, &trace.DecisionID, &trace.PolicyID,
 		&trace.PolicyVersion, &trace.TraceData, &trace.RulesEvaluated,
-		&trace.RulesMatched, &trace.FeaturesUsed, &trace.ExecutionTimeMs, &trace.CreatedAt,
+		&trace.RulesMatched, &trace.FeaturesUsed, &trace.ExecutionTimeMs, &trace.Guardrails,
+		&trace.CreatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -134,11 +152,12 @@ func (s *DecisionStore) ListByFilters(ctx context.Context, filters models.Decisi
 	query := `
 		SELECT id, decision_id, idempotency_key, service_id, policy_id, policy_version,
 			snapshot_id, decision_type, decision_result, actions, 
-			confidence_score, simulation_run_id, dry_run, executed_at, created_at
-		FROM decision_records WHERE 1=1`
+			confidence_score, simulation_run_id, dry_run, shadow, shadow_of,
+			experiment_id, experiment_arm, executed_at, created_at
+		FROM decision_records WHERE shadow = $1`
 	
-	var args []interface{}
-	argCount := 0
+	args := []interface{}{filters.Shadow}
+	argCount := 1
 
 	if filters.ServiceID != "" {
 		argCount++
@@ -150,6 +169,16 @@ func (s *DecisionStore) ListByFilters(ctx context.Context, filters models.Decisi
 		query += fmt.Sprintf(" AND policy_id = $%d", argCount)
 		args = append(args, filters.PolicyID)
 	}
+	if filters.PolicyVersion != "" {
+		argCount++
+		query += fmt.Sprintf(" AND policy_version = $%d", argCount)
+		args = append(args, filters.PolicyVersion)
+	}
+	if filters.ExperimentID != "" {
+		argCount++
+		query += fmt.Sprintf(" AND experiment_id = $%d", argCount)
+		args = append(args, filters.ExperimentID)
+	}
 	if !filters.From.IsZero() {
 		argCount++
 		query += fmt.Sprintf(" AND executed_at >= $%d", argCount)
@@ -183,6 +212,64 @@ func (s *DecisionStore) ListByFilters(ctx context.Context, filters models.Decisi
 	return scanDecisionRows(rows)
 }
 
+// ListShadowComparisons retrieves the shadow evaluations of a policy's
+// candidate versions, newest first, each with the live decision it was made
+// next to and the rules the two matched
+func (s *DecisionStore) ListShadowComparisons(ctx context.Context, filters models.DecisionFilters) ([]*models.ShadowComparison, error) {
+	query := `
+		SELECT l.decision_id, s.decision_id, l.service_id, l.policy_id, l.policy_version,
+			s.policy_version, COALESCE(lt.rules_matched->>0, ''), COALESCE(st.rules_matched->>0, ''),
+			l.decision_result, s.decision_result, l.actions, s.actions, s.executed_at
+		FROM decision_records s
+		JOIN decision_records l ON l.decision_id = s.shadow_of
+		LEFT JOIN decision_traces lt ON lt.decision_id = l.decision_id
+		LEFT JOIN decision_traces st ON st.decision_id = s.decision_id
+		WHERE s.shadow AND s.policy_id = $1`
+
+	args := []interface{}{filters.PolicyID}
+	if filters.PolicyVersion != "" {
+		args = append(args, filters.PolicyVersion)
+		query += fmt.Sprintf(" AND s.policy_version = $%d", len(args))
+	}
+	if filters.ServiceID != "" {
+		args = append(args, filters.ServiceID)
+		query += fmt.Sprintf(" AND s.service_id = $%d", len(args))
+	}
+	if !filters.From.IsZero() {
+		args = append(args, filters.From)
+		query += fmt.Sprintf(" AND s.executed_at >= $%d", len(args))
+	}
+	if !filters.To.IsZero() {
+		args = append(args, filters.To)
+		query += fmt.Sprintf(" AND s.executed_at <= $%d", len(args))
+	}
+	query += " ORDER BY s.executed_at DESC"
+	if filters.Limit > 0 {
+		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.client.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var comparisons []*models.ShadowComparison
+	for rows.Next() {
+		var c models.ShadowComparison
+		if err := rows.Scan(
+			&c.DecisionID, &c.ShadowID, &c.ServiceID, &c.PolicyID, &c.PolicyVersion,
+			&c.CandidateVersion, &c.LiveRule, &c.ShadowRule,
+			&c.LiveResult, &c.ShadowResult, &c.LiveActions, &c.ShadowActions, &c.ExecutedAt,
+		); err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, &c)
+	}
+	return comparisons, rows.Err()
+}
+
 func scanDecisionRows(rows pgx.Rows) ([]*models.DecisionRecord, error) {
 	var decisions []*models.DecisionRecord
 	for rows.Next() {
@@ -192,6 +279,7 @@ func scanDecisionRows(rows pgx.Rows) ([]*models.DecisionRecord, error) {
 			&d.PolicyID, &d.PolicyVersion, &d.SnapshotID,
 			&d.DecisionType, &d.DecisionResult, &d.Actions,
 			&d.ConfidenceScore, &d.SimulationRunID, &d.DryRun,
+			&d.Shadow, &d.ShadowOf, &d.ExperimentID, &d.ExperimentArm,
 			&d.ExecutedAt, &d.CreatedAt,
 		)
 		if err != nil {
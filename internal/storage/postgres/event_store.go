@@ -47,14 +47,15 @@ func (s *EventStore) Store(ctx context.Context, event *models.Event) error {
 	return nil
 }
 
-// StoreBatch persists multiple events in a batch
+// StoreBatch persists multiple events in a batch, skipping events whose
+// event ID or idempotency key is already stored
 func (s *EventStore) StoreBatch(ctx context.Context, events []*models.Event) error {
 	return s.client.Transaction(ctx, func(tx pgx.Tx) error {
 		for _, event := range events {
 			query := `
 				INSERT INTO events (event_id, idempotency_key, service_id, event_type, payload, timestamp)
 				VALUES ($1, $2, $3, $4, $5, $6)
-				ON CONFLICT (idempotency_key) DO NOTHING
+				ON CONFLICT DO NOTHING
 				RETURNING id, created_at`
 
 			err := tx.QueryRow(ctx, query,
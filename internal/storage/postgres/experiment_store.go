@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExperimentStore handles policy experiment persistence
+type ExperimentStore struct {
+	client *Client
+}
+
+// NewExperimentStore creates a new experiment store
+func NewExperimentStore(client *Client) *ExperimentStore {
+	return &ExperimentStore{client: client}
+}
+
+const experimentColumns = `id, experiment_id, policy_id, description, arms, status, started_at, stopped_at, created_at`
+
+// Create persists a new experiment
+func (s *ExperimentStore) Create(ctx context.Context, e *models.Experiment) error {
+	arms, err := json.Marshal(e.Arms)
+	if err != nil {
+		return fmt.Errorf("failed to encode arms: %w", err)
+	}
+
+	query := `
+		INSERT INTO experiments (experiment_id, policy_id, description, arms, status, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err = s.client.Pool().QueryRow(ctx, query,
+		e.ExperimentID,
+		e.PolicyID,
+		e.Description,
+		arms,
+		e.Status,
+		e.StartedAt,
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store experiment: %w", err)
+	}
+	return nil
+}
+
+// Stop marks a running experiment as stopped
+func (s *ExperimentStore) Stop(ctx context.Context, e *models.Experiment) error {
+	query := `
+		UPDATE experiments SET status = $2, stopped_at = $3
+		WHERE experiment_id = $1 AND status = 'running'`
+
+	tag, err := s.client.Pool().Exec(ctx, query, e.ExperimentID, e.Status, e.StoppedAt)
+	if err != nil {
+		return fmt.Errorf("failed to stop experiment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// GetByID retrieves an experiment by its ID
+func (s *ExperimentStore) GetByID(ctx context.Context, experimentID string) (*models.Experiment, error) {
+	query := `SELECT ` + experimentColumns + ` FROM experiments WHERE experiment_id = $1`
+
+	rows, err := s.client.Pool().Query(ctx, query, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+	defer rows.Close()
+
+	experiments, err := scanExperimentRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(experiments) == 0 {
+		return nil, models.ErrNotFound
+	}
+	return experiments[0], nil
+}
+
+// List retrieves experiments, newest first, only the running ones when asked
+func (s *ExperimentStore) List(ctx context.Context, runningOnly bool) ([]*models.Experiment, error) {
+	query := `SELECT ` + experimentColumns + ` FROM experiments`
+	if runningOnly {
+		query += ` WHERE status = 'running'`
+	}
+	query += ` ORDER BY started_at DESC`
+
+	rows, err := s.client.Pool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiments: %w", err)
+	}
+	defer rows.Close()
+
+	return scanExperimentRows(rows)
+}
+
+// RecordAssignment stores the arm a service was assigned to, keeping the
+// first assignment of a service
+func (s *ExperimentStore) RecordAssignment(ctx context.Context, a *models.ExperimentAssignment) error {
+	query := `
+		INSERT INTO experiment_assignments (experiment_id, service_id, arm, policy_id, assigned_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (experiment_id, service_id) DO NOTHING`
+
+	_, err := s.client.Pool().Exec(ctx, query, a.ExperimentID, a.ServiceID, a.Arm, a.PolicyID, a.AssignedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record assignment: %w", err)
+	}
+	return nil
+}
+
+// ListAssignments retrieves the services assigned to an experiment's arms
+func (s *ExperimentStore) ListAssignments(ctx context.Context, experimentID string) ([]*models.ExperimentAssignment, error) {
+	query := `
+		SELECT experiment_id, service_id, arm, policy_id, assigned_at
+		FROM experiment_assignments
+		WHERE experiment_id = $1
+		ORDER BY arm, service_id`
+
+	rows, err := s.client.Pool().Query(ctx, query, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*models.ExperimentAssignment
+	for rows.Next() {
+		var a models.ExperimentAssignment
+		if err := rows.Scan(&a.ExperimentID, &a.ServiceID, &a.Arm, &a.PolicyID, &a.AssignedAt); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, &a)
+	}
+	return assignments, rows.Err()
+}
+
+// ArmOutcomes aggregates, per arm, the live decisions an experiment made and
+// the impact scores of the feedback they received
+func (s *ExperimentStore) ArmOutcomes(ctx context.Context, experimentID string) ([]models.ExperimentArmOutcome, error) {
+	query := `
+		SELECT d.experiment_arm,
+			COUNT(DISTINCT d.service_id),
+			COUNT(DISTINCT d.decision_id),
+			COUNT(f.impact_score),
+			COALESCE(AVG(f.impact_score), 0)::float8,
+			COALESCE(VAR_SAMP(f.impact_score), 0)::float8
+		FROM decision_records d
+		LEFT JOIN feedback_records f ON f.decision_id = d.decision_id
+		WHERE d.experiment_id = $1 AND NOT d.shadow
+		GROUP BY d.experiment_arm
+		ORDER BY d.experiment_arm`
+
+	rows, err := s.client.Pool().Query(ctx, query, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []models.ExperimentArmOutcome
+	for rows.Next() {
+		var o models.ExperimentArmOutcome
+		if err := rows.Scan(&o.Arm, &o.Services, &o.Decisions, &o.FeedbackCount, &o.MeanImpact, &o.ImpactVariance); err != nil {
+			return nil, err
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, rows.Err()
+}
+
+func scanExperimentRows(rows pgx.Rows) ([]*models.Experiment, error) {
+	var experiments []*models.Experiment
+	for rows.Next() {
+		var e models.Experiment
+		var arms []byte
+		if err := rows.Scan(
+			&e.ID, &e.ExperimentID, &e.PolicyID, &e.Description, &arms,
+			&e.Status, &e.StartedAt, &e.StoppedAt, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(arms, &e.Arms); err != nil {
+			return nil, fmt.Errorf("invalid arms of experiment %s: %w", e.ExperimentID, err)
+		}
+		experiments = append(experiments, &e)
+	}
+	return experiments, rows.Err()
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// FeedbackStore handles feedback persistence
+type FeedbackStore struct {
+	client *Client
+}
+
+// NewFeedbackStore creates a new feedback store
+func NewFeedbackStore(client *Client) *FeedbackStore {
+	return &FeedbackStore{client: client}
+}
+
+const feedbackColumns = `id, feedback_id, action_id, decision_id, service_id, feedback_type,
+			metrics_before, metrics_after, impact_score, drift_detected, drift_details,
+			rollback_recommended, rollback_executed, observation_window_minutes,
+			recorded_at, created_at`
+
+// Store persists a feedback record
+func (s *FeedbackStore) Store(ctx context.Context, record *models.FeedbackRecord) error {
+	query := `
+		INSERT INTO feedback_records (
+			feedback_id, action_id, decision_id, service_id, feedback_type,
+			metrics_before, metrics_after, impact_score, drift_detected, drift_details,
+			rollback_recommended, rollback_executed, observation_window_minutes, recorded_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at`
+
+	err := s.client.Pool().QueryRow(ctx, query,
+		record.FeedbackID,
+		record.ActionID,
+		record.DecisionID,
+		record.ServiceID,
+		record.FeedbackType,
+		record.MetricsBefore,
+		record.MetricsAfter,
+		record.ImpactScore,
+		record.DriftDetected,
+		record.DriftDetails,
+		record.RollbackRecommended,
+		record.RollbackExecuted,
+		record.ObservationWindowMins,
+		record.RecordedAt,
+	).Scan(&record.ID, &record.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to store feedback: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a feedback record by its ID
+func (s *FeedbackStore) GetByID(ctx context.Context, feedbackID string) (*models.FeedbackRecord, error) {
+	query := `SELECT ` + feedbackColumns + ` FROM feedback_records WHERE feedback_id = $1`
+
+	var f models.FeedbackRecord
+	err := s.client.Pool().QueryRow(ctx, query, feedbackID).Scan(
+		&f.ID, &f.FeedbackID, &f.ActionID, &f.DecisionID, &f.ServiceID, &f.FeedbackType,
+		&f.MetricsBefore, &f.MetricsAfter, &f.ImpactScore, &f.DriftDetected, &f.DriftDetails,
+		&f.RollbackRecommended, &f.RollbackExecuted, &f.ObservationWindowMins,
+		&f.RecordedAt, &f.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	return &f, nil
+}
+
+// ListByFilters retrieves feedback records matching filters
+func (s *FeedbackStore) ListByFilters(ctx context.Context, filters models.FeedbackFilters) ([]*models.FeedbackRecord, error) {
+	query := `SELECT ` + feedbackColumns + ` FROM feedback_records WHERE 1=1`
+
+	var args []interface{}
+	argCount := 0
+
+	if filters.ActionID != "" {
+		argCount++
+		query += fmt.Sprintf(" AND action_id = $%d", argCount)
+		args = append(args, filters.ActionID)
+	}
+	if filters.DecisionID != "" {
+		argCount++
+		query += fmt.Sprintf(" AND decision_id = $%d", argCount)
+		args = append(args, filters.DecisionID)
+	}
+	if filters.ServiceID != "" {
+		argCount++
+		query += fmt.Sprintf(" AND service_id = $%d", argCount)
+		args = append(args, filters.ServiceID)
+	}
+	if filters.DriftOnly {
+		query += " AND drift_detected = TRUE"
+	}
+	if !filters.From.IsZero() {
+		argCount++
+		query += fmt.Sprintf(" AND recorded_at >= $%d", argCount)
+		args = append(args, filters.From)
+	}
+	if !filters.To.IsZero() {
+		argCount++
+		query += fmt.Sprintf(" AND recorded_at <= $%d", argCount)
+		args = append(args, filters.To)
+	}
+
+	query += " ORDER BY recorded_at DESC"
+
+	if filters.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filters.Limit)
+	}
+
+	rows, err := s.client.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFeedbackRows(rows)
+}
+
+// DriftHistory aggregates the feedback of a service into time buckets
+func (s *FeedbackStore) DriftHistory(ctx context.Context, serviceID string, since time.Time, bucket time.Duration) ([]models.DriftBucket, error) {
+	query := `
+		SELECT to_timestamp(floor(extract(epoch FROM recorded_at)::float8 / $3::float8) * $3::float8) AS bucket_start,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE drift_detected),
+			COUNT(*) FILTER (WHERE rollback_recommended),
+			COUNT(*) FILTER (WHERE rollback_executed),
+			COALESCE(AVG(impact_score), 0)::float8,
+			COALESCE(MIN(impact_score), 0)::float8
+		FROM feedback_records
+		WHERE service_id = $1 AND recorded_at >= $2
+		GROUP BY bucket_start
+		ORDER BY bucket_start ASC`
+
+	rows, err := s.client.Pool().Query(ctx, query, serviceID, since, bucket.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drift history: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []models.DriftBucket
+	for rows.Next() {
+		var b models.DriftBucket
+		if err := rows.Scan(
+			&b.BucketStart, &b.FeedbackCount, &b.DriftCount, &b.RollbackRecommended,
+			&b.RollbackExecuted, &b.AvgImpactScore, &b.MinImpactScore,
+		); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+// PolicyImpact aggregates decisions made since a time by policy, with the
+// actions they issued and the feedback those actions received
+func (s *FeedbackStore) PolicyImpact(ctx context.Context, since time.Time) ([]models.PolicyImpact, error) {
+	query := `
+		SELECT d.policy_id,
+			COUNT(DISTINCT d.decision_id),
+			COUNT(DISTINCT a.action_id),
+			COUNT(DISTINCT a.action_id) FILTER (WHERE a.status = 'failed'),
+			COUNT(f.feedback_id),
+			AVG(f.impact_score)::float8,
+			COUNT(f.feedback_id) FILTER (WHERE f.drift_detected),
+			COUNT(f.feedback_id) FILTER (WHERE f.rollback_recommended)
+		FROM decision_records d
+		LEFT JOIN action_records a ON a.decision_id = d.decision_id
+		LEFT JOIN feedback_records f ON f.action_id = a.action_id
+		WHERE d.created_at >= $1 AND NOT d.shadow
+		GROUP BY d.policy_id
+		ORDER BY d.policy_id ASC`
+
+	rows, err := s.client.Pool().Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy impact: %w", err)
+	}
+	defer rows.Close()
+
+	var impacts []models.PolicyImpact
+	for rows.Next() {
+		var p models.PolicyImpact
+		if err := rows.Scan(
+			&p.PolicyID, &p.Decisions, &p.Actions, &p.FailedActions, &p.FeedbackCount,
+			&p.AvgImpactScore, &p.DriftCount, &p.RollbackRecommended,
+		); err != nil {
+			return nil, err
+		}
+		impacts = append(impacts, p)
+	}
+
+	return impacts, rows.Err()
+}
+
+// MarkRollbackExecuted flags a feedback record as rolled back
+func (s *FeedbackStore) MarkRollbackExecuted(ctx context.Context, feedbackID string) error {
+	query := `UPDATE feedback_records SET rollback_executed = TRUE WHERE feedback_id = $1`
+	tag, err := s.client.Pool().Exec(ctx, query, feedbackID)
+	if err != nil {
+		return fmt.Errorf("failed to mark rollback: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func scanFeedbackRows(rows pgx.Rows) ([]*models.FeedbackRecord, error) {
+	var records []*models.FeedbackRecord
+	for rows.Next() {
+		var f models.FeedbackRecord
+		err := rows.Scan(
+			&f.ID, &f.FeedbackID, &f.ActionID, &f.DecisionID, &f.ServiceID, &f.FeedbackType,
+			&f.MetricsBefore, &f.MetricsAfter, &f.ImpactScore, &f.DriftDetected, &f.DriftDetails,
+			&f.RollbackRecommended, &f.RollbackExecuted, &f.ObservationWindowMins,
+			&f.RecordedAt, &f.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, &f)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// GuardrailStore records the usage reserved by actions that passed the
+// guardrails, so that every replica checks against the same usage
+type GuardrailStore struct {
+	client *Client
+}
+
+// NewGuardrailStore creates a new guardrail store
+func NewGuardrailStore(client *Client) *GuardrailStore {
+	return &GuardrailStore{client: client}
+}
+
+// querier runs queries on the pool or in a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Usage sums the instances reserved since instancesSince and the cost
+// reserved since spendSince per service, leaving out the action exclude
+func (s *GuardrailStore) Usage(ctx context.Context, instancesSince, spendSince time.Time, exclude string) (*models.UsageTotals, error) {
+	return usageTotals(ctx, s.client.Pool(), instancesSince, spendSince, exclude)
+}
+
+// ReserveUsage checks and records an action's usage in one transaction,
+// holding a lock every replica takes: check is given the usage of the other
+// actions and u is recorded, replacing any earlier reservation of the same
+// action, only when check returns nil. Its error is returned as is.
+func (s *GuardrailStore) ReserveUsage(ctx context.Context, u *models.GuardrailUsage, instancesSince, spendSince time.Time, check func(*models.UsageTotals) error) error {
+	return s.client.Transaction(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('guardrail_usage'))`); err != nil {
+			return fmt.Errorf("failed to lock guardrail usage: %w", err)
+		}
+
+		totals, err := usageTotals(ctx, tx, instancesSince, spendSince, u.ActionID)
+		if err != nil {
+			return err
+		}
+		if err := check(totals); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO guardrail_usage (action_id, service_id, instances, cost, circuit, reserved_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (action_id) DO UPDATE SET
+				service_id = EXCLUDED.service_id,
+				instances = EXCLUDED.instances,
+				cost = EXCLUDED.cost,
+				circuit = EXCLUDED.circuit,
+				reserved_at = EXCLUDED.reserved_at`
+
+		_, err = tx.Exec(ctx, query, u.ActionID, u.ServiceID, u.Instances, u.Cost, u.Circuit, u.ReservedAt)
+		if err != nil {
+			return fmt.Errorf("failed to reserve guardrail usage: %w", err)
+		}
+
+		// Usage past both windows no longer counts, except circuit changes
+		_, err = tx.Exec(ctx, `DELETE FROM guardrail_usage WHERE reserved_at < LEAST($1::timestamptz, $2::timestamptz) AND circuit = ''`,
+			instancesSince, spendSince)
+		if err != nil {
+			return fmt.Errorf("failed to prune guardrail usage: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReleaseUsage gives back the usage an action reserved
+func (s *GuardrailStore) ReleaseUsage(ctx context.Context, actionID string) error {
+	_, err := s.client.Pool().Exec(ctx, `DELETE FROM guardrail_usage WHERE action_id = $1`, actionID)
+	if err != nil {
+		return fmt.Errorf("failed to release guardrail usage: %w", err)
+	}
+	return nil
+}
+
+func usageTotals(ctx context.Context, q querier, instancesSince, spendSince time.Time, exclude string) (*models.UsageTotals, error) {
+	totals := &models.UsageTotals{
+		Instances:    make(map[string]int),
+		Spend:        make(map[string]float64),
+		OpenCircuits: []string{},
+	}
+
+	query := `
+		SELECT service_id,
+			COALESCE(SUM(instances) FILTER (WHERE reserved_at >= $1), 0),
+			COALESCE(SUM(cost) FILTER (WHERE reserved_at >= $2), 0)
+		FROM guardrail_usage
+		WHERE reserved_at >= LEAST($1::timestamptz, $2::timestamptz) AND action_id <> $3
+			AND (instances > 0 OR cost > 0)
+		GROUP BY service_id`
+
+	rows, err := q.Query(ctx, query, instancesSince, spendSince, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum guardrail usage: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var service string
+		var instances int
+		var spend float64
+		if err := rows.Scan(&service, &instances, &spend); err != nil {
+			return nil, err
+		}
+		if instances > 0 {
+			totals.Instances[service] = instances
+		}
+		if spend > 0 {
+			totals.Spend[service] = spend
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT service_id FROM (
+			SELECT DISTINCT ON (service_id) service_id, circuit
+			FROM guardrail_usage
+			WHERE circuit <> '' AND action_id <> $1
+			ORDER BY service_id, reserved_at DESC
+		) latest
+		WHERE circuit = 'open'
+		ORDER BY service_id`
+
+	rows, err = q.Query(ctx, query, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open circuits: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var service string
+		if err := rows.Scan(&service); err != nil {
+			return nil, err
+		}
+		totals.OpenCircuits = append(totals.OpenCircuits, service)
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// WebhookStore handles webhook delivery and dead-letter persistence
+type WebhookStore struct {
+	client *Client
+}
+
+// NewWebhookStore creates a new webhook store
+func NewWebhookStore(client *Client) *WebhookStore {
+	return &WebhookStore{client: client}
+}
+
+const deadLetterColumns = `id, webhook_id, url, method, headers, payload, attempts, last_error,
+			redelivery_count, redelivered_at, created_at, updated_at`
+
+// RecordAttempt persists a delivery attempt
+func (s *WebhookStore) RecordAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			webhook_id, url, attempt, status_code, latency_ms, response_body, error
+		) VALUES ($1, $2, $3, NULLIF($4, 0), $5, NULLIF($6, ''), NULLIF($7, ''))
+		RETURNING id, created_at`
+
+	err := s.client.Pool().QueryRow(ctx, query,
+		d.WebhookID,
+		d.URL,
+		d.Attempt,
+		d.StatusCode,
+		d.LatencyMs,
+		d.ResponseBody,
+		d.Error,
+	).Scan(&d.ID, &d.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the delivery attempts of a webhook, oldest first
+func (s *WebhookStore) ListDeliveries(ctx context.Context, webhookID string) ([]*models.WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, url, attempt, COALESCE(status_code, 0), latency_ms,
+			COALESCE(response_body, ''), COALESCE(error, ''), created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at, attempt`
+
+	rows, err := s.client.Pool().Query(ctx, query, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.URL, &d.Attempt, &d.StatusCode, &d.LatencyMs,
+			&d.ResponseBody, &d.Error, &d.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// StoreDeadLetter persists a webhook that failed every retry
+func (s *WebhookStore) StoreDeadLetter(ctx context.Context, dl *models.WebhookDeadLetter) error {
+	headers, err := json.Marshal(dl.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal headers: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_dead_letters (
+			webhook_id, url, method, headers, payload, attempts, last_error
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err = s.client.Pool().QueryRow(ctx, query,
+		dl.WebhookID,
+		dl.URL,
+		dl.Method,
+		headers,
+		dl.Payload,
+		dl.Attempts,
+		dl.LastError,
+	).Scan(&dl.ID, &dl.CreatedAt, &dl.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to store dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeadLetter retrieves a dead letter by ID
+func (s *WebhookStore) GetDeadLetter(ctx context.Context, id string) (*models.WebhookDeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM webhook_dead_letters WHERE id = $1`
+
+	rows, err := s.client.Pool().Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadLetters, err := scanDeadLetterRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(deadLetters) == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return deadLetters[0], nil
+}
+
+// ListDeadLetters lists dead letters, newest first
+func (s *WebhookStore) ListDeadLetters(ctx context.Context, filters models.DeadLetterFilters) ([]*models.WebhookDeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM webhook_dead_letters WHERE 1=1`
+	args := []interface{}{}
+	argCount := 0
+
+	if filters.WebhookID != "" {
+		argCount++
+		query += fmt.Sprintf(" AND webhook_id = $%d", argCount)
+		args = append(args, filters.WebhookID)
+	}
+	if filters.Pending {
+		query += " AND redelivered_at IS NULL"
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	if filters.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filters.Limit)
+	}
+
+	rows, err := s.client.Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeadLetterRows(rows)
+}
+
+// RecordRedelivery counts a manual redelivery. A successful one (empty
+// lastError) marks the dead letter as redelivered.
+func (s *WebhookStore) RecordRedelivery(ctx context.Context, id string, attempts int, lastError string) error {
+	query := `
+		UPDATE webhook_dead_letters
+		SET redelivery_count = redelivery_count + 1,
+			attempts = attempts + $2,
+			last_error = CASE WHEN $3 = '' THEN last_error ELSE $3 END,
+			redelivered_at = CASE WHEN $3 = '' THEN NOW() ELSE redelivered_at END
+		WHERE id = $1`
+
+	tag, err := s.client.Pool().Exec(ctx, query, id, attempts, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to record redelivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanDeadLetterRows(rows pgx.Rows) ([]*models.WebhookDeadLetter, error) {
+	var deadLetters []*models.WebhookDeadLetter
+	for rows.Next() {
+		var dl models.WebhookDeadLetter
+		var headers []byte
+		if err := rows.Scan(
+			&dl.ID, &dl.WebhookID, &dl.URL, &dl.Method, &headers, &dl.Payload,
+			&dl.Attempts, &dl.LastError, &dl.RedeliveryCount, &dl.RedeliveredAt,
+			&dl.CreatedAt, &dl.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(headers, &dl.Headers); err != nil {
+			return nil, fmt.Errorf("invalid headers for dead letter %s: %w", dl.ID, err)
+		}
+		deadLetters = append(deadLetters, &dl)
+	}
+
+	return deadLetters, rows.Err()
+}
@@ -9,28 +9,61 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/aegis-decision-engine/ade/internal/circuitbreaker"
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/pkg/webhooksig"
 )
 
 // Client sends webhooks with retries and circuit breaker
 type Client struct {
-	httpClient       *http.Client
-	logger           *slog.Logger
-	maxRetries       int
-	baseBackoff      time.Duration
-	maxBackoff       time.Duration
-	circuitBreaker   *circuitbreaker.CircuitBreaker
+	httpClient    *http.Client
+	logger        *slog.Logger
+	maxRetries    int
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	maxRetryAfter time.Duration
+	retryPolicy   RetryPolicy
+	breakers      *circuitbreaker.Registry
+	breakerScope  BreakerScope
+
+	deliveryLog DeliveryLog
+
+	mu      sync.RWMutex
+	secrets map[string][]string // signing secrets by endpoint host, "" for the default
 }
 
+// DeliveryLog records delivery attempts and webhooks that failed every retry.
+// *postgres.WebhookStore satisfies it.
+type DeliveryLog interface {
+	RecordAttempt(ctx context.Context, d *models.WebhookDelivery) error
+	StoreDeadLetter(ctx context.Context, dl *models.WebhookDeadLetter) error
+}
+
+// BreakerScope decides which deliveries share a circuit breaker
+type BreakerScope string
+
+const (
+	// BreakerScopeHost shares a breaker between all endpoints of a host
+	BreakerScopeHost BreakerScope = "host"
+	// BreakerScopeEndpoint gives every URL, without its query, its own breaker
+	BreakerScopeEndpoint BreakerScope = "endpoint"
+)
+
 // Config holds webhook client configuration
 type Config struct {
 	Timeout              time.Duration
 	MaxRetries           int
 	BaseBackoff          time.Duration
 	MaxBackoff           time.Duration
+	MaxRetryAfter        time.Duration // longer Retry-After values end the retries
+	Retry                RetryPolicy
 	EnableCircuitBreaker bool
+	CircuitBreaker       circuitbreaker.Config
+	BreakerScope         BreakerScope
 }
 
 // DefaultConfig returns default configuration
@@ -40,7 +73,11 @@ func DefaultConfig() Config {
 		MaxRetries:           3,
 		BaseBackoff:          time.Second,
 		MaxBackoff:           30 * time.Second,
+		MaxRetryAfter:        2 * time.Minute,
+		Retry:                DefaultRetryPolicy(),
 		EnableCircuitBreaker: true,
+		CircuitBreaker:       circuitbreaker.DefaultConfig(),
+		BreakerScope:         BreakerScopeHost,
 	}
 }
 
@@ -54,14 +91,31 @@ func NewClient(config Config, logger *slog.Logger) *Client {
 		httpClient: &http.Client{
 			Timeout: config.Timeout,
 		},
-		logger:      logger,
-		maxRetries:  config.MaxRetries,
-		baseBackoff: config.BaseBackoff,
-		maxBackoff:  config.MaxBackoff,
+		logger:        logger,
+		maxRetries:    config.MaxRetries,
+		baseBackoff:   config.BaseBackoff,
+		maxBackoff:    config.MaxBackoff,
+		maxRetryAfter: config.MaxRetryAfter,
+		retryPolicy:   config.Retry,
+		breakerScope:  config.BreakerScope,
+		secrets:       make(map[string][]string),
+	}
+	if client.breakerScope == "" {
+		client.breakerScope = BreakerScopeHost
+	}
+	if client.retryPolicy.isZero() {
+		client.retryPolicy = DefaultRetryPolicy()
+	}
+	if client.maxRetryAfter <= 0 {
+		client.maxRetryAfter = DefaultConfig().MaxRetryAfter
 	}
 
 	if config.EnableCircuitBreaker {
-		client.circuitBreaker = circuitbreaker.New("webhook", circuitbreaker.DefaultConfig())
+		breakerConfig := config.CircuitBreaker
+		if breakerConfig.MaxFailures <= 0 {
+			breakerConfig = circuitbreaker.DefaultConfig()
+		}
+		client.breakers = circuitbreaker.NewRegistry(breakerConfig)
 	}
 
 	return client
@@ -74,6 +128,11 @@ type Request struct {
 	Headers map[string]string      `json:"headers"`
 	Payload map[string]interface{} `json:"payload"`
 	ID      string                 `json:"id"`
+
+	// OnRetry is called before each retry with the failed attempt number
+	OnRetry func(attempt int, err error) `json:"-"`
+
+	redelivery bool // a manual redelivery of a dead letter, not dead-lettered again
 }
 
 // Response represents a webhook response
@@ -92,60 +151,139 @@ func (c *Client) Send(ctx context.Context, req *Request) (*Response, error) {
 		req.Method = http.MethodPost
 	}
 
-	if _, err := url.Parse(req.URL); err != nil {
+	u, err := url.Parse(req.URL)
+	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
+	if c.breakers == nil {
+		return c.sendWithRetries(ctx, req)
+	}
+
+	breaker := c.breakers.Get(c.breakerKey(u))
 	response := &Response{
 		Headers: make(map[string]string),
 	}
-
-	if c.circuitBreaker != nil {
-		err := c.circuitBreaker.Execute(ctx, func() error {
-			resp, err := c.sendWithRetries(ctx, req)
-			if resp != nil {
-				*response = *resp
+	ran := false
+	var sendErr error
+	err = breaker.Execute(ctx, func() error {
+		ran = true
+		resp, err := c.sendWithRetries(ctx, req)
+		if resp != nil {
+			*response = *resp
+		}
+		sendErr = err
+		// A receiver rejecting this webhook is not a failing receiver
+		if err != nil && clientError(response.StatusCode) {
+			return nil
+		}
+		return err
+	})
+	if !ran {
+		if err != nil {
+			// The breaker is open, so keep the webhook for redelivery
+			response.Error = err.Error()
+			if !req.redelivery {
+				c.deadLetter(ctx, req, 0, err)
 			}
-			return err
-		})
+		}
 		return response, err
 	}
+	return response, sendErr
+}
 
-	return c.sendWithRetries(ctx, req)
+// clientError reports whether a status rejects the request itself, as
+// opposed to a receiver that is failing or overloaded
+func clientError(status int) bool {
+	return status >= 400 && status < 500 && status != http.StatusTooManyRequests
+}
+
+// breakerKey names the breaker guarding u
+func (c *Client) breakerKey(u *url.URL) string {
+	if c.breakerScope == BreakerScopeEndpoint {
+		return u.Scheme + "://" + u.Host + u.Path
+	}
+	return u.Host
 }
 
 func (c *Client) sendWithRetries(ctx context.Context, req *Request) (*Response, error) {
 	var lastErr error
-
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		resp, err := c.doRequest(ctx, req)
-
-		if err == nil && resp.StatusCode < 500 {
-			resp.Attempts = attempt + 1
+	var lastResp *Response
+	attempts := c.maxRetries + 1
+	attempt := 1
+
+	for ; ; attempt++ {
+		started := time.Now()
+		resp, err := c.doRequest(ctx, req, attempt)
+		c.recordAttempt(ctx, req, attempt, time.Since(started), resp, err)
+
+		retryable := c.retryPolicy.Retryable(req.Method, resp, err)
+		if err == nil && !retryable && resp.StatusCode < 400 {
+			resp.Attempts = attempt
 			return resp, nil
 		}
 
 		lastErr = err
 		if resp != nil {
+			lastResp = resp
 			lastErr = fmt.Errorf("status %d: %s", resp.StatusCode, string(resp.Body))
 		}
+		if !retryable || attempt >= attempts {
+			break
+		}
+
+		backoff := fullJitter(c.baseBackoff, c.maxBackoff, attempt-1)
+		if wait, ok := retryAfter(resp, time.Now()); ok {
+			if wait > c.maxRetryAfter {
+				lastErr = fmt.Errorf("%w (Retry-After %s exceeds %s)", lastErr, wait, c.maxRetryAfter)
+				break
+			}
+			backoff = wait
+		}
 
-		if attempt < c.maxRetries {
-			backoff := c.calculateBackoff(attempt)
-			c.logger.Warn("webhook failed, retrying",
-				"attempt", attempt+1,
-				"max_retries", c.maxRetries,
-				"backoff", backoff,
-				"error", lastErr,
-			)
-			time.Sleep(backoff)
+		c.logger.Warn("webhook failed, retrying",
+			"attempt", attempt,
+			"max_retries", c.maxRetries,
+			"backoff", backoff,
+			"error", lastErr,
+		)
+		if req.OnRetry != nil {
+			req.OnRetry(attempt, lastErr)
+		}
+
+		if err := sleep(ctx, backoff); err != nil {
+			lastErr = fmt.Errorf("%w (retry cancelled: %v)", lastErr, err)
+			break
 		}
 	}
 
-	return nil, fmt.Errorf("webhook failed after %d attempts: %w", c.maxRetries+1, lastErr)
+	err := fmt.Errorf("webhook failed after %d attempts: %w", attempt, lastErr)
+	if !req.redelivery {
+		c.deadLetter(ctx, req, attempt, err)
+	}
+
+	failed := &Response{Headers: make(map[string]string), Attempts: attempt, Error: err.Error()}
+	if lastResp != nil {
+		failed.StatusCode = lastResp.StatusCode
+		failed.Headers = lastResp.Headers
+		failed.Body = lastResp.Body
+	}
+	return failed, err
 }
 
-func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error) {
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) doRequest(ctx context.Context, req *Request, attempt int) (*Response, error) {
 	start := time.Now()
 
 	body, err := json.Marshal(req.Payload)
@@ -160,12 +298,17 @@ func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error)
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-Webhook-ID", req.ID)
-	httpReq.Header.Set("X-Webhook-Attempt", "1")
+	httpReq.Header.Set("X-Webhook-Attempt", strconv.Itoa(attempt))
 
 	for k, v := range req.Headers {
 		httpReq.Header.Set(k, v)
 	}
 
+	// Signed per attempt so retries carry a fresh timestamp and nonce
+	if secrets := c.signingSecrets(req.URL); len(secrets) > 0 {
+		webhooksig.SignRequest(httpReq, secrets, time.Now(), body)
+	}
+
 	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, err
@@ -176,6 +319,7 @@ func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error)
 
 	resp := &Response{
 		StatusCode: httpResp.StatusCode,
+		Headers:    make(map[string]string),
 		Body:       respBody,
 		Duration:   time.Since(start),
 	}
@@ -189,19 +333,140 @@ func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error)
 	return resp, nil
 }
 
-func (c *Client) calculateBackoff(attempt int) time.Duration {
-	backoff := c.baseBackoff * time.Duration(1<<uint(attempt))
-	if backoff > c.maxBackoff {
-		backoff = c.maxBackoff
+// maxLoggedBody bounds the response body kept in the delivery log
+const maxLoggedBody = 2048
+
+// SetDeliveryLog sets where delivery attempts and dead letters are recorded
+func (c *Client) SetDeliveryLog(log DeliveryLog) {
+	c.deliveryLog = log
+}
+
+// Redeliver sends a dead-lettered webhook again, with the usual retries. A
+// failed redelivery is not dead-lettered a second time.
+func (c *Client) Redeliver(ctx context.Context, dl *models.WebhookDeadLetter) (*Response, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(dl.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid dead letter payload: %w", err)
+	}
+
+	return c.Send(ctx, &Request{
+		ID:         dl.WebhookID,
+		URL:        dl.URL,
+		Method:     dl.Method,
+		Headers:    dl.Headers,
+		Payload:    payload,
+		redelivery: true,
+	})
+}
+
+// recordAttempt writes an attempt to the delivery log, if there is one
+func (c *Client) recordAttempt(ctx context.Context, req *Request, attempt int, latency time.Duration, resp *Response, err error) {
+	if c.deliveryLog == nil {
+		return
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: req.ID,
+		URL:       req.URL,
+		Attempt:   attempt,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if resp != nil {
+		delivery.StatusCode = resp.StatusCode
+		body := resp.Body
+		if len(body) > maxLoggedBody {
+			body = body[:maxLoggedBody]
+		}
+		delivery.ResponseBody = string(body)
+	}
+
+	// Record even when the caller's context has been cancelled mid-delivery
+	if err := c.deliveryLog.RecordAttempt(context.WithoutCancel(ctx), delivery); err != nil {
+		c.logger.Warn("failed to record webhook delivery", "webhook_id", req.ID, "error", err)
+	}
+}
+
+// deadLetter keeps a webhook that failed every retry for manual redelivery
+func (c *Client) deadLetter(ctx context.Context, req *Request, attempts int, lastErr error) {
+	if c.deliveryLog == nil {
+		return
 	}
-	jitter := time.Duration(float64(backoff) * 0.1)
-	return backoff + jitter
+
+	payload, err := json.Marshal(req.Payload)
+	if err != nil {
+		c.logger.Error("failed to dead-letter webhook", "webhook_id", req.ID, "error", err)
+		return
+	}
+
+	dl := &models.WebhookDeadLetter{
+		WebhookID: req.ID,
+		URL:       req.URL,
+		Method:    req.Method,
+		Headers:   req.Headers,
+		Payload:   payload,
+		Attempts:  attempts,
+		LastError: lastErr.Error(),
+	}
+	if err := c.deliveryLog.StoreDeadLetter(context.WithoutCancel(ctx), dl); err != nil {
+		c.logger.Error("failed to dead-letter webhook", "webhook_id", req.ID, "error", err)
+		return
+	}
+
+	c.logger.Warn("webhook dead-lettered",
+		"webhook_id", req.ID,
+		"dead_letter_id", dl.ID,
+		"attempts", attempts,
+		"error", lastErr,
+	)
 }
 
-// GetCircuitBreakerStats returns circuit breaker statistics
+// SetSigningSecrets sets the secrets webhooks to host are signed with. An
+// empty host sets the secrets for hosts without their own. Two secrets may be
+// active while one is being rotated out; no secrets turns signing off.
+func (c *Client) SetSigningSecrets(host string, secrets ...string) error {
+	if len(secrets) > webhooksig.MaxSecrets {
+		return fmt.Errorf("at most %d signing secrets may be active, got %d", webhooksig.MaxSecrets, len(secrets))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(secrets) == 0 {
+		delete(c.secrets, host)
+		return nil
+	}
+	c.secrets[host] = secrets
+	return nil
+}
+
+// signingSecrets returns the secrets for the endpoint at rawURL
+func (c *Client) signingSecrets(rawURL string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if u, err := url.Parse(rawURL); err == nil {
+		if secrets, ok := c.secrets[u.Host]; ok {
+			return secrets
+		}
+	}
+	return c.secrets[""]
+}
+
+// GetCircuitBreakerStats returns the statistics of every breaker
 func (c *Client) GetCircuitBreakerStats() map[string]interface{} {
-	if c.circuitBreaker == nil {
+	if c.breakers == nil {
 		return map[string]interface{}{"enabled": false}
 	}
-	return c.circuitBreaker.Stats()
+
+	breakers := c.breakers.All()
+	stats := make([]map[string]interface{}, 0, len(breakers))
+	for _, cb := range breakers {
+		stats = append(stats, cb.Stats())
+	}
+	return map[string]interface{}{
+		"enabled":  true,
+		"scope":    c.breakerScope,
+		"breakers": stats,
+	}
 }
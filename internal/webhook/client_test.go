@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/pkg/webhooksig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendSignsEveryAttempt(t *testing.T) {
+	verifier := webhooksig.NewVerifier([]string{"old"}, 0)
+
+	var mu sync.Mutex
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := verifier.VerifyRequest(r)
+		assert.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		signatures = append(signatures, r.Header.Get(webhooksig.HeaderSignature))
+		if len(signatures) == 1 {
+			// The retry is signed again, usually within the same second
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		Timeout:     time.Second,
+		MaxRetries:  1,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}, nil)
+	require.NoError(t, client.SetSigningSecrets("", "new", "old"))
+
+	resp, err := client.Send(context.Background(), &Request{
+		ID:      "act-1",
+		URL:     server.URL,
+		Payload: map[string]interface{}{"action_id": "act-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+
+	require.Len(t, signatures, 2)
+	assert.NotEqual(t, signatures[0], signatures[1])
+}
+
+func TestSigningSecretsPerHost(t *testing.T) {
+	client := NewClient(DefaultConfig(), nil)
+	require.NoError(t, client.SetSigningSecrets("", "default"))
+	require.NoError(t, client.SetSigningSecrets("scaler.internal:8080", "scaler"))
+	assert.Error(t, client.SetSigningSecrets("", "a", "b", "c"))
+
+	assert.Equal(t, []string{"scaler"}, client.signingSecrets("http://scaler.internal:8080/hook"))
+	assert.Equal(t, []string{"default"}, client.signingSecrets("http://other/hook"))
+
+	require.NoError(t, client.SetSigningSecrets(""))
+	assert.Empty(t, client.signingSecrets("http://other/hook"))
+}
+
+type fakeDeliveryLog struct {
+	mu          sync.Mutex
+	attempts    []*models.WebhookDelivery
+	deadLetters []*models.WebhookDeadLetter
+}
+
+func (l *fakeDeliveryLog) RecordAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, d)
+	return nil
+}
+
+func (l *fakeDeliveryLog) StoreDeadLetter(ctx context.Context, dl *models.WebhookDeadLetter) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	dl.ID = "dl-1"
+	l.deadLetters = append(l.deadLetters, dl)
+	return nil
+}
+
+func TestSendDeadLettersExhaustedDeliveries(t *testing.T) {
+	var mu sync.Mutex
+	var attemptHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attemptHeaders = append(attemptHeaders, r.Header.Get("X-Webhook-Attempt"))
+		mu.Unlock()
+		http.Error(w, "scaler unavailable", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	log := &fakeDeliveryLog{}
+	client := NewClient(Config{
+		Timeout:     time.Second,
+		MaxRetries:  2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}, nil)
+	client.SetDeliveryLog(log)
+
+	req := &Request{
+		ID:      "act-1",
+		URL:     server.URL,
+		Method:  http.MethodPost,
+		Headers: map[string]string{"X-Service-ID": "checkout"},
+		Payload: map[string]interface{}{"action_id": "act-1"},
+	}
+	resp, err := client.Send(context.Background(), req)
+	require.Error(t, err)
+	assert.Equal(t, 3, resp.Attempts)
+	assert.Equal(t, []string{"1", "2", "3"}, attemptHeaders)
+
+	require.Len(t, log.attempts, 3)
+	for i, a := range log.attempts {
+		assert.Equal(t, i+1, a.Attempt)
+		assert.Equal(t, http.StatusBadGateway, a.StatusCode)
+		assert.Contains(t, a.ResponseBody, "scaler unavailable")
+	}
+
+	require.Len(t, log.deadLetters, 1)
+	dl := log.deadLetters[0]
+	assert.Equal(t, "act-1", dl.WebhookID)
+	assert.Equal(t, 3, dl.Attempts)
+	assert.Equal(t, "checkout", dl.Headers["X-Service-ID"])
+	assert.JSONEq(t, `{"action_id":"act-1"}`, string(dl.Payload))
+
+	// A failed redelivery is not dead-lettered again
+	_, err = client.Redeliver(context.Background(), dl)
+	require.Error(t, err)
+	assert.Len(t, log.deadLetters, 1)
+	assert.Len(t, log.attempts, 6)
+}
+
+func TestSendFailsOnClientError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "unknown service", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	log := &fakeDeliveryLog{}
+	client := NewClient(Config{
+		Timeout:     time.Second,
+		MaxRetries:  2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+	}, nil)
+	client.SetDeliveryLog(log)
+
+	resp, err := client.Send(context.Background(), &Request{
+		ID:      "act-1",
+		URL:     server.URL,
+		Method:  http.MethodPost,
+		Payload: map[string]interface{}{"action_id": "act-1"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Len(t, log.deadLetters, 1)
+	assert.Equal(t, 1, log.deadLetters[0].Attempts)
+}
+
+func TestClientErrorsLeaveBreakerClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid payload", http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.MaxRetries = 0
+	config.CircuitBreaker.MaxFailures = 2
+	log := &fakeDeliveryLog{}
+	client := NewClient(config, nil)
+	client.SetDeliveryLog(log)
+
+	for i := 0; i < 4; i++ {
+		_, err := client.Send(context.Background(), &Request{ID: "act-1", URL: server.URL, Payload: map[string]interface{}{}})
+		require.Error(t, err)
+	}
+	// Every delivery reached the receiver and was dead-lettered
+	assert.Len(t, log.attempts, 4)
+	assert.Len(t, log.deadLetters, 4)
+
+	stats := client.GetCircuitBreakerStats()["breakers"].([]map[string]interface{})
+	require.Len(t, stats, 1)
+	assert.Equal(t, "closed", stats[0]["state"])
+}
+
+func TestRetryClassification(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	assert.True(t, policy.Retryable(http.MethodPost, &Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assert.True(t, policy.Retryable(http.MethodPost, &Response{StatusCode: http.StatusBadGateway}, nil))
+	assert.False(t, policy.Retryable(http.MethodPost, &Response{StatusCode: http.StatusNotImplemented}, nil))
+	assert.False(t, policy.Retryable(http.MethodPost, &Response{StatusCode: http.StatusBadRequest}, nil))
+	assert.True(t, policy.Retryable(http.MethodPost, nil, context.DeadlineExceeded))
+
+	policy.Methods = []string{http.MethodPut}
+	assert.False(t, policy.Retryable(http.MethodPost, &Response{StatusCode: http.StatusBadGateway}, nil))
+}
+
+func TestSendHonorsRetryAfterAndContext(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		Timeout:       time.Second,
+		MaxRetries:    3,
+		BaseBackoff:   time.Millisecond,
+		MaxBackoff:    time.Millisecond,
+		MaxRetryAfter: time.Second,
+	}, nil)
+
+	// Retry-After beyond MaxRetryAfter ends the retries at once
+	resp, err := client.Send(context.Background(), &Request{URL: server.URL, Payload: map[string]interface{}{}})
+	require.Error(t, err)
+	assert.Equal(t, 1, resp.Attempts)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// A cancelled context interrupts the wait for Retry-After
+	client.maxRetryAfter = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err = client.Send(ctx, &Request{URL: server.URL, Payload: map[string]interface{}{}})
+	require.Error(t, err)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCircuitBreakersPerHost(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+
+	config := DefaultConfig()
+	config.MaxRetries = 0
+	config.CircuitBreaker.MaxFailures = 2
+	log := &fakeDeliveryLog{}
+	client := NewClient(config, nil)
+	client.SetDeliveryLog(log)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Send(context.Background(), &Request{ID: "act-1", URL: failing.URL, Payload: map[string]interface{}{}})
+		require.Error(t, err)
+	}
+	// The third delivery never reached the receiver but was still dead-lettered
+	assert.Len(t, log.attempts, 2)
+	assert.Len(t, log.deadLetters, 3)
+
+	// Other hosts are unaffected
+	_, err := client.Send(context.Background(), &Request{URL: healthy.URL, Payload: map[string]interface{}{}})
+	assert.NoError(t, err)
+
+	stats := client.GetCircuitBreakerStats()["breakers"].([]map[string]interface{})
+	require.Len(t, stats, 2)
+
+	states := map[string]string{}
+	for _, s := range stats {
+		states[s["name"].(string)] = s["state"].(string)
+	}
+	assert.Equal(t, "open", states[strings.TrimPrefix(failing.URL, "http://")])
+	assert.Equal(t, "closed", states[strings.TrimPrefix(healthy.URL, "http://")])
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/aegis-decision-engine/ade/internal/models"
+	"github.com/aegis-decision-engine/ade/internal/storage/postgres"
+)
+
+// Handler serves the webhook delivery log and dead-letter queue
+type Handler struct {
+	client *Client
+	store  *postgres.WebhookStore
+}
+
+// NewHandler creates a new webhook handler
+func NewHandler(client *Client, store *postgres.WebhookStore) *Handler {
+	return &Handler{client: client, store: store}
+}
+
+// RegisterRoutes registers the webhook routes
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/webhooks/deliveries", h.handleListDeliveries)
+	mux.HandleFunc("/webhooks/dlq", h.handleListDeadLetters)
+	mux.HandleFunc("/webhooks/dlq/{id}", h.handleGetDeadLetter)
+	mux.HandleFunc("/webhooks/dlq/{id}/redeliver", h.handleRedeliver)
+}
+
+func (h *Handler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook store not available")
+		return
+	}
+
+	webhookID := r.URL.Query().Get("webhook_id")
+	if webhookID == "" {
+		writeError(w, http.StatusBadRequest, "webhook_id is required")
+		return
+	}
+
+	deliveries, err := h.store.ListDeliveries(r.Context(), webhookID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list deliveries: "+err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+func (h *Handler) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook store not available")
+		return
+	}
+
+	q := r.URL.Query()
+	filters := models.DeadLetterFilters{
+		WebhookID: q.Get("webhook_id"),
+		Pending:   q.Get("pending") == "true",
+		Limit:     100,
+	}
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			filters.Limit = n
+		}
+	}
+
+	deadLetters, err := h.store.ListDeadLetters(r.Context(), filters)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list dead letters: "+err.Error())
+		return
+	}
+	if deadLetters == nil {
+		deadLetters = []*models.WebhookDeadLetter{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dead_letters": deadLetters,
+		"count":        len(deadLetters),
+	})
+}
+
+func (h *Handler) handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	dl, err := h.store.GetDeadLetter(r.Context(), id)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "dead letter not found: "+id)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get dead letter: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dl)
+}
+
+func (h *Handler) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "webhook store not available")
+		return
+	}
+
+	id := r.PathValue("id")
+	dl, err := h.store.GetDeadLetter(r.Context(), id)
+	if err != nil {
+		if models.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "dead letter not found: "+id)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get dead letter: "+err.Error())
+		return
+	}
+	if dl.RedeliveredAt != nil {
+		writeError(w, http.StatusConflict, "dead letter already redelivered: "+id)
+		return
+	}
+
+	resp, sendErr := h.client.Redeliver(r.Context(), dl)
+	attempts, lastError := 0, ""
+	if resp != nil {
+		attempts = resp.Attempts
+	}
+	if sendErr != nil {
+		lastError = sendErr.Error()
+	}
+	if err := h.store.RecordRedelivery(r.Context(), id, attempts, lastError); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to record redelivery: "+err.Error())
+		return
+	}
+
+	if sendErr != nil {
+		writeError(w, http.StatusBadGateway, "redelivery failed: "+sendErr.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":          id,
+		"webhook_id":  dl.WebhookID,
+		"status":      "redelivered",
+		"status_code": resp.StatusCode,
+		"attempts":    resp.Attempts,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
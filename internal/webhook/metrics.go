@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"github.com/aegis-decision-engine/ade/internal/circuitbreaker"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	breakerStateDesc = prometheus.NewDesc(
+		"ade_webhook_circuit_breaker_state",
+		"Circuit breaker state per webhook endpoint, 1 for the current state",
+		[]string{"breaker", "state"}, nil,
+	)
+	breakerFailuresDesc = prometheus.NewDesc(
+		"ade_webhook_circuit_breaker_failures",
+		"Consecutive failures counted by a webhook circuit breaker",
+		[]string{"breaker"}, nil,
+	)
+)
+
+var breakerStates = []circuitbreaker.State{
+	circuitbreaker.StateClosed,
+	circuitbreaker.StateOpen,
+	circuitbreaker.StateHalfOpen,
+}
+
+// breakerCollector exports the client's circuit breakers as gauges
+type breakerCollector struct {
+	client *Client
+}
+
+// MetricsCollector returns a Prometheus collector for the state of every
+// circuit breaker the client has created
+func (c *Client) MetricsCollector() prometheus.Collector {
+	return &breakerCollector{client: c}
+}
+
+func (bc *breakerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- breakerStateDesc
+	ch <- breakerFailuresDesc
+}
+
+func (bc *breakerCollector) Collect(ch chan<- prometheus.Metric) {
+	if bc.client.breakers == nil {
+		return
+	}
+
+	for _, cb := range bc.client.breakers.All() {
+		current := cb.State()
+		for _, state := range breakerStates {
+			value := 0.0
+			if state == current {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(breakerStateDesc, prometheus.GaugeValue, value, cb.Name(), state.String())
+		}
+		ch <- prometheus.MustNewConstMetric(breakerFailuresDesc, prometheus.GaugeValue, float64(cb.Failures()), cb.Name())
+	}
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy decides which failed deliveries are worth retrying
+type RetryPolicy struct {
+	StatusCodes   []int    // response codes that are retried
+	NetworkErrors bool     // retry when no response was received
+	Methods       []string // methods that may be retried; others are sent once
+}
+
+// DefaultRetryPolicy retries throttling, timeouts and gateway errors. POST is
+// included because every webhook carries X-Webhook-ID for deduplication.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		StatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		NetworkErrors: true,
+		Methods: []string{
+			http.MethodGet, http.MethodHead, http.MethodOptions,
+			http.MethodPut, http.MethodDelete, http.MethodPost,
+		},
+	}
+}
+
+// Retryable reports whether a delivery that got resp or err may be retried
+func (p RetryPolicy) Retryable(method string, resp *Response, err error) bool {
+	if !p.allowsMethod(method) {
+		return false
+	}
+	if err != nil {
+		return p.NetworkErrors
+	}
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) isZero() bool {
+	return len(p.StatusCodes) == 0 && len(p.Methods) == 0 && !p.NetworkErrors
+}
+
+func (p RetryPolicy) allowsMethod(method string) bool {
+	for _, m := range p.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(resp *Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Headers["Retry-After"]
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// fullJitter returns a random backoff between zero and the exponential
+// backoff for attempt, capped at max
+func fullJitter(base, max time.Duration, attempt int) time.Duration {
+	backoff := max
+	if attempt < 32 {
+		if b := base * time.Duration(1<<uint(attempt)); b > 0 && b < max {
+			backoff = b
+		}
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
@@ -0,0 +1,4 @@
+-- Migration 000002: Rollback
+
+DROP INDEX IF EXISTS idx_actions_rollback_of;
+ALTER TABLE action_records DROP COLUMN IF EXISTS rollback_of;
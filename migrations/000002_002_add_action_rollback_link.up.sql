@@ -0,0 +1,8 @@
+-- Migration 000002: Link rollback actions to the action they undo
+
+ALTER TABLE action_records
+    ADD COLUMN rollback_of VARCHAR(255) REFERENCES action_records(action_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_actions_rollback_of ON action_records(rollback_of) WHERE rollback_of IS NOT NULL;
+
+COMMENT ON COLUMN action_records.rollback_of IS 'action_id of the action this record rolls back';
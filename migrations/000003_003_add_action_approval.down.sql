@@ -0,0 +1,13 @@
+-- Migration 000003: Rollback
+
+DROP INDEX IF EXISTS idx_actions_approval_expires;
+ALTER TABLE action_records
+    DROP COLUMN IF EXISTS review_comment,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS approval_expires_at;
+
+UPDATE action_records SET status = 'cancelled' WHERE status IN ('awaiting_approval', 'rejected');
+ALTER TABLE action_records DROP CONSTRAINT chk_action_status;
+ALTER TABLE action_records ADD CONSTRAINT chk_action_status
+    CHECK (status IN ('pending', 'scheduled', 'executing', 'completed', 'failed', 'cancelled'));
@@ -0,0 +1,16 @@
+-- Migration 000003: Pending-approval state for high-risk actions
+
+ALTER TABLE action_records DROP CONSTRAINT chk_action_status;
+ALTER TABLE action_records ADD CONSTRAINT chk_action_status
+    CHECK (status IN ('awaiting_approval', 'pending', 'scheduled', 'executing', 'completed', 'failed', 'cancelled', 'rejected'));
+
+ALTER TABLE action_records
+    ADD COLUMN approval_expires_at TIMESTAMPTZ,
+    ADD COLUMN reviewed_by VARCHAR(255),
+    ADD COLUMN reviewed_at TIMESTAMPTZ,
+    ADD COLUMN review_comment TEXT;
+
+CREATE INDEX idx_actions_approval_expires ON action_records(approval_expires_at) WHERE status = 'awaiting_approval';
+
+COMMENT ON COLUMN action_records.approval_expires_at IS 'when an awaiting_approval action falls back to the default outcome';
+COMMENT ON COLUMN action_records.reviewed_by IS 'approver or rejecter of the action, system:timeout when it expired';
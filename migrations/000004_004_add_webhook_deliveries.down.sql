@@ -0,0 +1,4 @@
+-- Migration 000004: Rollback
+
+DROP TABLE IF EXISTS webhook_dead_letters;
+DROP TABLE IF EXISTS webhook_deliveries;
@@ -0,0 +1,41 @@
+-- Migration 000004: Webhook delivery log and dead-letter queue
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    latency_ms INTEGER NOT NULL,
+    response_body TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries(created_at);
+
+CREATE TABLE webhook_dead_letters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    redelivery_count INTEGER NOT NULL DEFAULT 0,
+    redelivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_dead_letters_pending ON webhook_dead_letters(created_at) WHERE redelivered_at IS NULL;
+CREATE INDEX idx_webhook_dead_letters_webhook_id ON webhook_dead_letters(webhook_id);
+
+CREATE TRIGGER update_webhook_dead_letters_updated_at BEFORE UPDATE ON webhook_dead_letters
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE webhook_deliveries IS 'one row per webhook delivery attempt';
+COMMENT ON TABLE webhook_dead_letters IS 'webhooks that failed every retry, kept for manual redelivery';
+COMMENT ON COLUMN webhook_dead_letters.redelivered_at IS 'set once a manual redelivery succeeds';
@@ -0,0 +1,4 @@
+-- Migration 000005: Rollback
+
+ALTER TABLE decision_traces DROP COLUMN IF EXISTS guardrails;
+ALTER TABLE action_records DROP COLUMN IF EXISTS cost;
@@ -0,0 +1,10 @@
+-- Migration 000005: Record action cost and the guardrails that blocked decision actions
+
+ALTER TABLE action_records
+    ADD COLUMN cost DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE decision_traces
+    ADD COLUMN guardrails JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN action_records.cost IS 'cost of the action, counted against the daily cost budget';
+COMMENT ON COLUMN decision_traces.guardrails IS 'guardrail violations that kept decision actions from running';
@@ -0,0 +1,4 @@
+-- Migration 000006: Rollback
+
+DROP INDEX IF EXISTS idx_actions_rollout_due;
+ALTER TABLE action_records DROP COLUMN IF EXISTS rollout;
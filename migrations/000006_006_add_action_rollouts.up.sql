@@ -0,0 +1,9 @@
+-- Migration 000006: Track the stages of staged (canary) actions
+
+ALTER TABLE action_records
+    ADD COLUMN rollout JSONB;
+
+CREATE INDEX idx_actions_rollout_due ON action_records(status)
+    WHERE status = 'executing' AND rollout IS NOT NULL;
+
+COMMENT ON COLUMN action_records.rollout IS 'stages of a staged action and the verdict on each';
@@ -0,0 +1,6 @@
+-- Migration 000007: Rollback
+
+DROP INDEX IF EXISTS idx_decisions_shadow_of;
+DROP INDEX IF EXISTS idx_decisions_shadow;
+ALTER TABLE decision_records DROP COLUMN IF EXISTS shadow_of;
+ALTER TABLE decision_records DROP COLUMN IF EXISTS shadow;
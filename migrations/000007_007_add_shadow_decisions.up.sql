@@ -0,0 +1,12 @@
+-- Migration 000007: Store shadow evaluations of candidate policy versions
+
+ALTER TABLE decision_records
+    ADD COLUMN shadow BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN shadow_of VARCHAR(255) REFERENCES decision_records(decision_id) ON DELETE CASCADE;
+
+CREATE INDEX idx_decisions_shadow ON decision_records(policy_id, policy_version, executed_at)
+    WHERE shadow;
+CREATE INDEX idx_decisions_shadow_of ON decision_records(shadow_of);
+
+COMMENT ON COLUMN decision_records.shadow IS 'evaluated with a candidate policy version, never acted on';
+COMMENT ON COLUMN decision_records.shadow_of IS 'live decision the shadow evaluation was made next to';
@@ -0,0 +1,7 @@
+-- Migration 000008: Rollback
+
+DROP INDEX IF EXISTS idx_decisions_experiment;
+ALTER TABLE decision_records DROP COLUMN IF EXISTS experiment_arm;
+ALTER TABLE decision_records DROP COLUMN IF EXISTS experiment_id;
+DROP TABLE IF EXISTS experiment_assignments;
+DROP TABLE IF EXISTS experiments;
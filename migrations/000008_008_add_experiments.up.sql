@@ -0,0 +1,39 @@
+-- Migration 000008: Policy experiments split decisions between policy versions
+
+CREATE TABLE experiments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    experiment_id VARCHAR(255) UNIQUE NOT NULL,
+    policy_id VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    arms JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    stopped_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_experiment_status CHECK (status IN ('running', 'stopped'))
+);
+
+-- One running experiment per policy
+CREATE UNIQUE INDEX idx_experiments_running ON experiments(policy_id) WHERE status = 'running';
+
+CREATE TABLE experiment_assignments (
+    experiment_id VARCHAR(255) NOT NULL REFERENCES experiments(experiment_id) ON DELETE CASCADE,
+    service_id VARCHAR(255) NOT NULL,
+    arm VARCHAR(255) NOT NULL,
+    policy_id VARCHAR(255) NOT NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (experiment_id, service_id)
+);
+
+ALTER TABLE decision_records
+    ADD COLUMN experiment_id VARCHAR(255),
+    ADD COLUMN experiment_arm VARCHAR(255);
+
+CREATE INDEX idx_decisions_experiment ON decision_records(experiment_id, experiment_arm)
+    WHERE experiment_id IS NOT NULL;
+
+COMMENT ON TABLE experiments IS 'policy experiments and the arms their decisions are split between';
+COMMENT ON TABLE experiment_assignments IS 'arm each service was assigned to by its stable hash';
+COMMENT ON COLUMN decision_records.experiment_arm IS 'experiment arm whose policy made the decision';
@@ -0,0 +1,4 @@
+-- Migration 000009: Rollback
+
+DROP INDEX IF EXISTS idx_actions_circuits;
+DROP INDEX IF EXISTS idx_actions_live;
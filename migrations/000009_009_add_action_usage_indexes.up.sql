@@ -0,0 +1,8 @@
+-- Migration 000009: Guardrails count the usage of live actions across replicas
+
+CREATE INDEX idx_actions_live ON action_records((COALESCE(executed_at, updated_at)))
+    WHERE NOT dry_run AND status IN ('executing', 'completed');
+
+CREATE INDEX idx_actions_circuits ON action_records(target_service, (COALESCE(executed_at, updated_at)) DESC)
+    WHERE NOT dry_run AND status IN ('executing', 'completed')
+        AND action_type IN ('open_circuit', 'close_circuit');
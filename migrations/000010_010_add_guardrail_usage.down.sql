@@ -0,0 +1,10 @@
+-- Migration 000010: Rollback
+
+CREATE INDEX IF NOT EXISTS idx_actions_live ON action_records((COALESCE(executed_at, updated_at)))
+    WHERE NOT dry_run AND status IN ('executing', 'completed');
+
+CREATE INDEX IF NOT EXISTS idx_actions_circuits ON action_records(target_service, (COALESCE(executed_at, updated_at)) DESC)
+    WHERE NOT dry_run AND status IN ('executing', 'completed')
+        AND action_type IN ('open_circuit', 'close_circuit');
+
+DROP TABLE IF EXISTS guardrail_usage;
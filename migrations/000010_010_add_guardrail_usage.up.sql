@@ -0,0 +1,22 @@
+-- Migration 000010: Guardrail usage is reserved by the actions that pass the guardrails
+
+CREATE TABLE guardrail_usage (
+    action_id VARCHAR(255) PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL,
+    instances INTEGER NOT NULL DEFAULT 0,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    circuit VARCHAR(10) NOT NULL DEFAULT '',
+    reserved_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_guardrail_circuit CHECK (circuit IN ('', 'open', 'close'))
+);
+
+CREATE INDEX idx_guardrail_usage_reserved ON guardrail_usage(reserved_at);
+CREATE INDEX idx_guardrail_usage_circuits ON guardrail_usage(service_id, reserved_at DESC)
+    WHERE circuit <> '';
+
+-- Usage is no longer derived from action_records
+DROP INDEX IF EXISTS idx_actions_circuits;
+DROP INDEX IF EXISTS idx_actions_live;
+
+COMMENT ON TABLE guardrail_usage IS 'instances, cost and circuit changes reserved by actions that passed the guardrails';
@@ -0,0 +1,192 @@
+// Package webhooksig signs and verifies ADE webhooks.
+//
+// Every webhook attempt carries an X-ADE-Timestamp header with the Unix time
+// it was sent, an X-ADE-Nonce header unique to the attempt and an
+// X-ADE-Signature header holding one "v1=<hex>" entry per active secret, each
+// an HMAC-SHA256 of "<timestamp>.<nonce>.<body>". During a secret rotation ADE
+// signs with both the old and the new secret, so a receiver accepts the
+// webhook as long as it knows either one.
+//
+// The nonce only detects a replayed attempt. Retries and redeliveries of the
+// same webhook are new attempts with the same X-Webhook-ID header, which is
+// what receivers should deduplicate the work on.
+//
+// Receivers verify webhooks with a Verifier:
+//
+//	verifier := webhooksig.NewVerifier([]string{os.Getenv("ADE_WEBHOOK_SECRET")}, 0)
+//	body, err := verifier.VerifyRequest(r)
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusUnauthorized)
+//		return
+//	}
+package webhooksig
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HeaderSignature holds the comma separated signatures of a webhook
+	HeaderSignature = "X-ADE-Signature"
+	// HeaderTimestamp holds the Unix time the webhook was signed at
+	HeaderTimestamp = "X-ADE-Timestamp"
+	// HeaderNonce holds a random value unique to each signed attempt
+	HeaderNonce = "X-ADE-Nonce"
+
+	// DefaultTolerance is how old a webhook may be before it is rejected
+	DefaultTolerance = 5 * time.Minute
+
+	// MaxSecrets is the number of secrets that may be active at once
+	MaxSecrets = 2
+
+	version = "v1"
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook signature missing")
+	ErrInvalidSignature = errors.New("webhook signature invalid")
+	ErrTimestampExpired = errors.New("webhook timestamp outside tolerance")
+	ErrReplayed         = errors.New("webhook already received")
+)
+
+// Sign returns the signature of body sent at timestamp with nonce
+func Sign(secret string, timestamp time.Time, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s.", timestamp.Unix(), nonce)
+	mac.Write(body)
+	return version + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeader returns the X-ADE-Signature value for body, with one
+// signature per secret
+func SignatureHeader(secrets []string, timestamp time.Time, nonce string, body []byte) string {
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, Sign(secret, timestamp, nonce, body))
+	}
+	return strings.Join(signatures, ",")
+}
+
+// NewNonce returns a random nonce
+func NewNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SignRequest sets the timestamp, a new nonce and the signature headers on r
+func SignRequest(r *http.Request, secrets []string, timestamp time.Time, body []byte) {
+	nonce := NewNonce()
+	r.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	r.Header.Set(HeaderNonce, nonce)
+	r.Header.Set(HeaderSignature, SignatureHeader(secrets, timestamp, nonce, body))
+}
+
+// Verifier checks webhook signatures and rejects replays
+type Verifier struct {
+	secrets   []string
+	tolerance time.Duration
+	now       func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewVerifier creates a verifier accepting webhooks signed with any of
+// secrets and sent at most tolerance ago. Zero tolerance means DefaultTolerance.
+func NewVerifier(secrets []string, tolerance time.Duration) *Verifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	return &Verifier{
+		secrets:   secrets,
+		tolerance: tolerance,
+		now:       time.Now,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// Verify checks the signature headers against body. A nonce is accepted
+// once; the same attempt delivered again within the tolerance is a replay.
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get(HeaderTimestamp)
+	nonce := header.Get(HeaderNonce)
+	signatures := header.Get(HeaderSignature)
+	if timestamp == "" || nonce == "" || signatures == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrInvalidSignature)
+	}
+	sentAt := time.Unix(unix, 0)
+	now := v.now()
+	if sentAt.Before(now.Add(-v.tolerance)) || sentAt.After(now.Add(v.tolerance)) {
+		return ErrTimestampExpired
+	}
+
+	matched := false
+	for _, secret := range v.secrets {
+		expected := Sign(secret, sentAt, nonce, body)
+		for _, signature := range strings.Split(signatures, ",") {
+			if hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(expected)) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			break
+		}
+	}
+	if !matched {
+		return ErrInvalidSignature
+	}
+
+	return v.remember(nonce, now)
+}
+
+// VerifyRequest verifies r and returns its body, which is left readable on r
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := v.Verify(r.Header, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// remember records the nonce of a verified attempt, forgetting those older
+// than the tolerance
+func (v *Verifier) remember(nonce string, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for n, at := range v.seen {
+		if now.Sub(at) > 2*v.tolerance {
+			delete(v.seen, n)
+		}
+	}
+	if _, ok := v.seen[nonce]; ok {
+		return ErrReplayed
+	}
+	v.seen[nonce] = now
+	return nil
+}
@@ -0,0 +1,78 @@
+package webhooksig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedHeader(secrets []string, at time.Time, body []byte) http.Header {
+	nonce := NewNonce()
+	h := http.Header{}
+	h.Set(HeaderTimestamp, strconv.FormatInt(at.Unix(), 10))
+	h.Set(HeaderNonce, nonce)
+	h.Set(HeaderSignature, SignatureHeader(secrets, at, nonce, body))
+	return h
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"action_id":"act-1"}`)
+	now := time.Now()
+
+	v := NewVerifier([]string{"secret"}, time.Minute)
+	assert.NoError(t, v.Verify(signedHeader([]string{"secret"}, now, body), body))
+
+	// Tampered body
+	assert.ErrorIs(t, v.Verify(signedHeader([]string{"secret"}, now, body), []byte(`{}`)), ErrInvalidSignature)
+	// Wrong secret
+	assert.ErrorIs(t, v.Verify(signedHeader([]string{"other"}, now, body), body), ErrInvalidSignature)
+	// Too old
+	assert.ErrorIs(t, v.Verify(signedHeader([]string{"secret"}, now.Add(-2*time.Minute), body), body), ErrTimestampExpired)
+	// Unsigned
+	assert.ErrorIs(t, v.Verify(http.Header{}, body), ErrMissingSignature)
+	// Nonce swapped for another
+	header := signedHeader([]string{"secret"}, now, body)
+	header.Set(HeaderNonce, NewNonce())
+	assert.ErrorIs(t, v.Verify(header, body), ErrInvalidSignature)
+}
+
+func TestVerifyRejectsReplay(t *testing.T) {
+	body := []byte(`{}`)
+	header := signedHeader([]string{"secret"}, time.Now(), body)
+
+	v := NewVerifier([]string{"secret"}, 0)
+	require.NoError(t, v.Verify(header, body))
+	assert.ErrorIs(t, v.Verify(header, body), ErrReplayed)
+
+	// A retry of the same body signed within the same second is a new attempt
+	assert.NoError(t, v.Verify(signedHeader([]string{"secret"}, time.Now(), body), body))
+}
+
+func TestVerifyDuringRotation(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Now()
+
+	// The sender signs with the new and the old secret
+	header := SignatureHeader([]string{"new", "old"}, now, NewNonce(), body)
+	assert.Len(t, strings.Split(header, ","), 2)
+
+	// Receivers that have not switched yet still accept it, as do those that have
+	assert.NoError(t, NewVerifier([]string{"old"}, 0).Verify(signedHeader([]string{"new", "old"}, now, body), body))
+	assert.NoError(t, NewVerifier([]string{"new"}, 0).Verify(signedHeader([]string{"new", "old"}, now, body), body))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := `{"action_id":"act-1"}`
+	r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	SignRequest(r, []string{"secret"}, time.Now(), []byte(body))
+
+	got, err := NewVerifier([]string{"secret"}, 0).VerifyRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(got))
+}